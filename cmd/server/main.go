@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/preetsinghmakkar/OpenCall/configs"
+	"github.com/preetsinghmakkar/OpenCall/internal/constants"
 	"github.com/preetsinghmakkar/OpenCall/internal/database"
 	"github.com/preetsinghmakkar/OpenCall/internal/handlers"
 	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
@@ -42,19 +44,59 @@ func main() {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// slot holds live in Postgres unless Redis is configured
+	var slotHolds repositories.SlotHoldStore = repositories.NewPostgresSlotHoldStore(client.DB)
+	if config.Booking.SlotHoldStore == constants.SlotHoldStoreRedis {
+		redisClient, err := database.NewRedisClient(database.RedisConfig{
+			Address:           config.Redis.Address,
+			Password:          config.Redis.Password,
+			ConnectionTimeout: 5 * time.Second,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize redis")
+		}
+		defer redisClient.Close()
+
+		slotHolds = repositories.NewRedisSlotHoldStore(redisClient)
+	}
+
 	// repositories
 	userRepo := repositories.NewUserRepository(client.DB)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(client.DB)
 	mentorRepo := repositories.NewMentorRepository(client.DB)
 	mentorServiceRepo := repositories.NewMentorServiceRepository(client.DB)
 	mentorAvailabilityRepo := repositories.NewMentorAvailabilityRepository(client.DB)
-	bookingRepo := repositories.NewBookingRepository(client.DB)
+	bookingRepo := repositories.NewBookingRepository(client.DB, slotHolds)
+	bookingSeriesRepo := repositories.NewBookingSeriesRepository(client.DB)
 	paymentRepo := repositories.NewPaymentRepository(client.DB)
+	refundRepo := repositories.NewRefundRepository(client.DB)
+	ledgerRepo := repositories.NewLedgerRepository(client.DB)
+	promoCodeRepo := repositories.NewPromoCodeRepository(client.DB)
+	packageRepo := repositories.NewPackageRepository(client.DB)
+	invoiceRepo := repositories.NewInvoiceRepository(client.DB)
+	webhookEventRepo := repositories.NewWebhookEventRepository(client.DB)
 	videoSessionRepo := repositories.NewVideoSessionRepository(client.DB)
-	razorpayClient := services.NewRazorpayClient(
-		config.Razorpay.KeyID,
-		config.Razorpay.KeySecret,
+	reviewRepo := repositories.NewReviewRepository(client.DB)
+	calendarRepo := repositories.NewCalendarRepository(client.DB)
+	externalCalendarRepo := repositories.NewExternalCalendarRepository(client.DB)
+	waitlistRepo := repositories.NewWaitlistRepository(client.DB)
+
+	// payment gateway
+	var (
+		paymentGateway services.PaymentGateway
+		fakeGateway    *services.FakeGateway
 	)
+	switch config.Payment.Gateway {
+	case constants.GatewayFake:
+		fakeGateway = services.NewFakeGateway(config.Payment.FakeGatewaySecret)
+		paymentGateway = fakeGateway
+	default:
+		paymentGateway = services.NewRazorpayClient(
+			config.Razorpay.KeyID,
+			config.Razorpay.KeySecret,
+			config.Razorpay.WebhookSecret,
+		)
+	}
 
 	// services
 	userService := services.NewUserService(userRepo)
@@ -67,30 +109,136 @@ func main() {
 	mentorOfferingService := services.NewMentorOfferingService(
 		mentorServiceRepo,
 		mentorRepo,
+		paymentGateway,
 	)
-	mentorAvailabilityService := services.NewMentorAvailabilityService(
-		mentorAvailabilityRepo,
+	availabilityService := services.NewAvailabilityService(
 		mentorRepo,
+		mentorServiceRepo,
+		mentorAvailabilityRepo,
+		bookingRepo,
+		externalCalendarRepo,
+		slotHolds,
 	)
-	availabilityService := services.NewAvailabilityService(
+	waitlistService := services.NewWaitlistService(
+		waitlistRepo,
 		mentorRepo,
 		mentorServiceRepo,
 		mentorAvailabilityRepo,
 		bookingRepo,
+		slotHolds,
+		availabilityService,
+		config.Booking.SlotHold,
+		config.Booking.WaitlistOffer,
+		config.Workers.WaitlistInterval,
+	)
+	mentorAvailabilityService := services.NewMentorAvailabilityService(
+		mentorAvailabilityRepo,
+		mentorRepo,
+		bookingRepo,
+		waitlistService,
+	)
+	ledgerService := services.NewLedgerService(
+		ledgerRepo,
+		mentorRepo,
+		config.Payment.CommissionPercent,
+	)
+	invoiceService := services.NewInvoiceService(
+		client.DB,
+		invoiceRepo,
+		paymentRepo,
+		mentorRepo,
+		config.Invoice.TaxLabel,
+		config.Invoice.TaxRatePercent,
 	)
+	paymentService := services.NewPaymentService(
+		client.DB,
+		paymentRepo,
+		bookingRepo,
+		refundRepo,
+		packageRepo,
+		bookingSeriesRepo,
+		mentorRepo,
+		mentorServiceRepo,
+		ledgerService,
+		invoiceService,
+		paymentGateway,
+	)
+	promoService := services.NewPromoService(promoCodeRepo, mentorRepo)
 	bookingService := services.NewBookingService(
 		bookingRepo,
 		mentorRepo,
 		mentorServiceRepo,
 		mentorAvailabilityRepo,
+		packageRepo,
+		bookingSeriesRepo,
+		slotHolds,
+		paymentService,
+		promoService,
+		waitlistService,
+		config.Booking.SlotHold,
 	)
-	paymentService := services.NewPaymentService(
-		client.DB,
+	calendarService := services.NewCalendarService(calendarRepo)
+	packageService := services.NewPackageService(
+		packageRepo,
+		mentorServiceRepo,
+		mentorRepo,
+		paymentService,
+	)
+
+	// background workers, stopped when the server shuts down
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	bookingReaper := services.NewBookingReaper(
+		bookingRepo,
+		bookingService,
+		waitlistService,
+		config.Booking.PendingHold,
+		config.Booking.RequestTTL,
+		config.Workers.ReaperInterval,
+	)
+	go bookingReaper.Run(workersCtx)
+	go waitlistService.Run(workersCtx)
+
+	webhookInbox := services.NewWebhookInbox(
+		webhookEventRepo,
+		paymentService,
+		config.Payment.WebhookMaxAttempts,
+		config.Workers.WebhookInterval,
+	)
+	go webhookInbox.Run(workersCtx)
+
+	paymentReconciler := services.NewPaymentReconciler(
 		paymentRepo,
+		paymentService,
+		paymentGateway,
+		config.Payment.ReconcileAfter,
+		config.Workers.ReconcileInterval,
+	)
+	go paymentReconciler.Run(workersCtx)
+
+	externalCalendarService := services.NewExternalCalendarService(
+		externalCalendarRepo,
+		mentorRepo,
+		services.NewExternalCalendarHTTPClient(),
+		config.Workers.CalendarSyncInterval,
+	)
+	go externalCalendarService.Run(workersCtx)
+
+	sessionOutcomeProcessor := services.NewSessionOutcomeProcessor(
 		bookingRepo,
-		razorpayClient,
-		config.Razorpay.KeySecret,
+		videoSessionRepo,
+		mentorServiceRepo,
+		packageRepo,
+		promoService,
+		paymentService,
+		config.Booking.NoShowGrace,
+		config.Booking.SessionMinimum,
+		config.Workers.SettleInterval,
 	)
+	go sessionOutcomeProcessor.Run(workersCtx)
+
+	reviewService := services.NewReviewService(reviewRepo, bookingRepo, mentorRepo)
 
 	// WebSocket hub
 	wsHub := websocket.NewHub()
@@ -99,6 +247,7 @@ func main() {
 	videoSessionService := services.NewVideoSessionService(
 		videoSessionRepo,
 		bookingRepo,
+		mentorServiceRepo,
 		mentorRepo,
 		userRepo,
 		wsHub,
@@ -114,7 +263,17 @@ func main() {
 		availabilityService,
 	)
 	bookingHandler := handlers.NewBookingHandler(bookingService, mentorRepo)
-	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService, webhookInbox)
+	earningsHandler := handlers.NewEarningsHandler(ledgerService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoService)
+	packageHandler := handlers.NewPackageHandler(packageService)
+	receiptHandler := handlers.NewReceiptHandler(invoiceService)
+	webhookHandler := handlers.NewWebhookHandler(webhookInbox)
+	reconciliationHandler := handlers.NewReconciliationHandler(paymentReconciler)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	externalCalendarHandler := handlers.NewExternalCalendarHandler(externalCalendarService)
+	waitlistHandler := handlers.NewWaitlistHandler(waitlistService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
 	webSocketHandler := handlers.NewWebSocketHandler(videoSessionService, wsHub, config.JWT.Secret)
 
 	// routes
@@ -126,8 +285,13 @@ func main() {
 		mentorServiceHandler,
 		mentorAvailabilityHandler,
 		paymentHandler,
+		webhookHandler,
+		packageHandler,
+		calendarHandler,
+		reviewHandler,
 		webSocketHandler,
 		bookingRepo,
+		mentorServiceRepo,
 		userRepo,
 		mentorRepo,
 		config.JWT.Secret,
@@ -141,10 +305,27 @@ func main() {
 		mentorAvailabilityHandler,
 		bookingHandler,
 		paymentHandler,
+		earningsHandler,
+		promoCodeHandler,
+		packageHandler,
+		receiptHandler,
+		webhookHandler,
+		reconciliationHandler,
+		calendarHandler,
+		externalCalendarHandler,
+		waitlistHandler,
+		reviewHandler,
 		webSocketHandler,
 		config.JWT.Secret,
 	)
 
+	if fakeGateway != nil {
+		routes.RegisterFakeGatewayEndpoints(
+			router,
+			handlers.NewFakeGatewayHandler(fakeGateway),
+		)
+	}
+
 	server := serve.NewServer(log.Logger, router, config)
 	server.Serve()
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -16,7 +17,12 @@ type Config struct {
 	Server   serverConfig
 	Database databaseConfig
 	JWT      jwtConfig
+	Payment  paymentConfig
 	Razorpay RazorpayConfig
+	Booking  bookingConfig
+	Invoice  invoiceConfig
+	Redis    redisConfig
+	Workers  workerConfig
 }
 
 type serverConfig struct {
@@ -36,6 +42,61 @@ type jwtConfig struct {
 	Secret string
 }
 
+type bookingConfig struct {
+	// How long an unpaid pending booking may hold its slot
+	PendingHold time.Duration
+	// How long a learner can hold a slot while checking out
+	SlotHold time.Duration
+	// Where slot holds live: postgres | redis
+	SlotHoldStore string
+	// How long a waitlisted learner has to accept an opened slot
+	WaitlistOffer time.Duration
+	// How long a mentor has to answer a booking request
+	RequestTTL time.Duration
+	// How late after the start a party may join without being a no-show
+	NoShowGrace time.Duration
+	// How long both parties must be in a session for it to count as held
+	SessionMinimum time.Duration
+}
+
+// workerConfig is how often each background worker wakes up
+type workerConfig struct {
+	// Expires unpaid bookings and unanswered requests
+	ReaperInterval time.Duration
+	// Offers opened slots to waitlisted learners
+	WaitlistInterval time.Duration
+	// Retries webhook events that failed to apply
+	WebhookInterval time.Duration
+	// Checks stale payments with the gateway
+	ReconcileInterval time.Duration
+	// Refreshes mentors' imported calendars
+	CalendarSyncInterval time.Duration
+	// Settles sessions that have ended
+	SettleInterval time.Duration
+}
+
+type redisConfig struct {
+	Address  string
+	Password string
+}
+
+type invoiceConfig struct {
+	// Prices are tax-inclusive; receipts show the tax they contain
+	TaxLabel       string
+	TaxRatePercent int
+}
+
+type paymentConfig struct {
+	Gateway           string // razorpay | fake
+	FakeGatewaySecret string
+	// Share of every booking kept by the platform, in whole percent
+	CommissionPercent int
+	// Tries before a webhook event is parked as failed for manual replay
+	WebhookMaxAttempts int
+	// Age at which a still-unpaid payment is checked with the gateway
+	ReconcileAfter time.Duration
+}
+
 type RazorpayConfig struct {
 	KeyID         string
 	KeySecret     string
@@ -69,11 +130,111 @@ func NewConfig() *Config {
 		JWT: jwtConfig{
 			Secret: GetEnvOrPanic(constants.EnvKeys.JWTSecret),
 		},
-		Razorpay: RazorpayConfig{
+		Payment: paymentConfig{
+			Gateway:            GetEnvOrDefault(constants.EnvKeys.PaymentGateway, constants.GatewayRazorpay),
+			CommissionPercent:  GetEnvIntOrDefault(constants.EnvKeys.PlatformCommission, 10),
+			WebhookMaxAttempts: GetEnvIntOrDefault(constants.EnvKeys.WebhookMaxAttempts, 8),
+			ReconcileAfter:     time.Duration(GetEnvIntOrDefault(constants.EnvKeys.ReconcileAfterMinutes, 5)) * time.Minute,
+		},
+		Invoice: invoiceConfig{
+			TaxLabel:       GetEnvOrDefault(constants.EnvKeys.TaxLabel, "GST"),
+			TaxRatePercent: GetEnvIntOrDefault(constants.EnvKeys.TaxRatePercent, 18),
+		},
+		Booking: bookingConfig{
+			PendingHold:    time.Duration(GetEnvIntOrDefault(constants.EnvKeys.BookingHoldMinutes, 15)) * time.Minute,
+			SlotHold:       time.Duration(GetEnvIntOrDefault(constants.EnvKeys.SlotHoldMinutes, 5)) * time.Minute,
+			SlotHoldStore:  GetEnvOrDefault(constants.EnvKeys.SlotHoldStore, constants.SlotHoldStorePostgres),
+			WaitlistOffer:  time.Duration(GetEnvIntOrDefault(constants.EnvKeys.WaitlistOfferMinutes, 30)) * time.Minute,
+			RequestTTL:     time.Duration(GetEnvIntOrDefault(constants.EnvKeys.RequestTTLHours, 24)) * time.Hour,
+			NoShowGrace:    time.Duration(GetEnvIntOrDefault(constants.EnvKeys.NoShowGraceMinutes, 15)) * time.Minute,
+			SessionMinimum: time.Duration(GetEnvIntOrDefault(constants.EnvKeys.SessionMinMinutes, 10)) * time.Minute,
+		},
+		Workers: workerConfig{
+			ReaperInterval:       time.Duration(GetEnvIntOrDefault(constants.EnvKeys.ReaperIntervalSeconds, 60)) * time.Second,
+			WaitlistInterval:     time.Duration(GetEnvIntOrDefault(constants.EnvKeys.WaitlistIntervalSeconds, 60)) * time.Second,
+			WebhookInterval:      time.Duration(GetEnvIntOrDefault(constants.EnvKeys.WebhookIntervalSeconds, 60)) * time.Second,
+			ReconcileInterval:    time.Duration(GetEnvIntOrDefault(constants.EnvKeys.ReconcileIntervalSeconds, 60)) * time.Second,
+			CalendarSyncInterval: time.Duration(GetEnvIntOrDefault(constants.EnvKeys.CalendarSyncIntervalSeconds, 60)) * time.Second,
+			SettleInterval:       time.Duration(GetEnvIntOrDefault(constants.EnvKeys.SettleIntervalSeconds, 60)) * time.Second,
+		},
+	}
+
+	if c.Payment.CommissionPercent < 0 || c.Payment.CommissionPercent > 100 {
+		panic("PLATFORM_COMMISSION_PERCENT must be between 0 and 100")
+	}
+
+	if c.Payment.WebhookMaxAttempts < 1 {
+		panic("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+
+	if c.Payment.ReconcileAfter <= 0 {
+		panic("PAYMENT_RECONCILE_AFTER_MINUTES must be at least 1")
+	}
+
+	if c.Invoice.TaxRatePercent < 0 || c.Invoice.TaxRatePercent > 100 {
+		panic("TAX_RATE_PERCENT must be between 0 and 100")
+	}
+
+	if c.Booking.PendingHold <= 0 {
+		panic("BOOKING_HOLD_MINUTES must be at least 1")
+	}
+
+	if c.Booking.SlotHold <= 0 {
+		panic("SLOT_HOLD_MINUTES must be at least 1")
+	}
+
+	if c.Booking.WaitlistOffer <= 0 {
+		panic("WAITLIST_OFFER_MINUTES must be at least 1")
+	}
+
+	if c.Booking.RequestTTL <= 0 {
+		panic("BOOKING_REQUEST_TTL_HOURS must be at least 1")
+	}
+
+	if c.Booking.NoShowGrace < 0 {
+		panic("NO_SHOW_GRACE_MINUTES must not be negative")
+	}
+
+	if c.Booking.SessionMinimum <= 0 {
+		panic("SESSION_MIN_MINUTES must be at least 1")
+	}
+
+	// Tickers panic on a zero interval
+	for key, interval := range map[string]time.Duration{
+		constants.EnvKeys.ReaperIntervalSeconds:       c.Workers.ReaperInterval,
+		constants.EnvKeys.WaitlistIntervalSeconds:     c.Workers.WaitlistInterval,
+		constants.EnvKeys.WebhookIntervalSeconds:      c.Workers.WebhookInterval,
+		constants.EnvKeys.ReconcileIntervalSeconds:    c.Workers.ReconcileInterval,
+		constants.EnvKeys.CalendarSyncIntervalSeconds: c.Workers.CalendarSyncInterval,
+		constants.EnvKeys.SettleIntervalSeconds:       c.Workers.SettleInterval,
+	} {
+		if interval <= 0 {
+			panic(key + " must be at least 1")
+		}
+	}
+
+	switch c.Booking.SlotHoldStore {
+	case constants.SlotHoldStorePostgres:
+	case constants.SlotHoldStoreRedis:
+		c.Redis = redisConfig{
+			Address:  GetEnvOrDefault(constants.EnvKeys.RedisAddress, "localhost:6379"),
+			Password: os.Getenv(constants.EnvKeys.RedisPassword),
+		}
+	default:
+		panic(fmt.Sprintf("unknown slot hold store %q", c.Booking.SlotHoldStore))
+	}
+
+	switch c.Payment.Gateway {
+	case constants.GatewayRazorpay:
+		c.Razorpay = RazorpayConfig{
 			KeyID:         GetEnvOrPanic(constants.EnvKeys.RazorpayKeyID),
 			KeySecret:     GetEnvOrPanic(constants.EnvKeys.RazorpayKeySecret),
 			WebhookSecret: GetEnvOrPanic(constants.EnvKeys.RazorpayWebhookSecret),
-		},
+		}
+	case constants.GatewayFake:
+		c.Payment.FakeGatewaySecret = GetEnvOrPanic(constants.EnvKeys.FakeGatewaySecret)
+	default:
+		panic(fmt.Sprintf("unknown payment gateway %q", c.Payment.Gateway))
 	}
 
 	return c
@@ -88,6 +249,29 @@ func GetEnvOrPanic(key string) string {
 	return value
 }
 
+func GetEnvOrDefault(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func GetEnvIntOrDefault(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Sprintf("%s must be a number", key))
+	}
+
+	return n
+}
+
 func (conf *Config) CorsNew() gin.HandlerFunc {
 	allowedOrigin := GetEnvOrPanic(constants.EnvKeys.CorsAllowedOrigins)
 
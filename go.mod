@@ -11,9 +11,14 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
 	github.com/razorpay/razorpay-go v1.4.0
+	github.com/redis/go-redis/v9 v9.7.3
 )
 
-require github.com/mattn/go-colorable v0.1.13 // indirect
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+)
 
 require (
 	github.com/bytedance/gopkg v0.1.3 // indirect
@@ -9,20 +9,52 @@ const (
 	RoleAdmin = "admin"
 )
 
+const (
+	GatewayRazorpay = "razorpay"
+	GatewayFake     = "fake"
+)
+
+const (
+	SlotHoldStorePostgres = "postgres"
+	SlotHoldStoreRedis    = "redis"
+)
+
 type envKeys struct {
-	Env                   string
-	ServerAddress         string
-	CorsAllowedOrigins    string
-	DBDriver              string
-	DBHost                string
-	DBPort                string
-	DBUser                string
-	DBPassword            string
-	DBName                string
-	JWTSecret             string
-	RazorpayKeyID         string
-	RazorpayKeySecret     string
-	RazorpayWebhookSecret string
+	Env                         string
+	ServerAddress               string
+	CorsAllowedOrigins          string
+	DBDriver                    string
+	DBHost                      string
+	DBPort                      string
+	DBUser                      string
+	DBPassword                  string
+	DBName                      string
+	JWTSecret                   string
+	RazorpayKeyID               string
+	RazorpayKeySecret           string
+	RazorpayWebhookSecret       string
+	PaymentGateway              string
+	FakeGatewaySecret           string
+	BookingHoldMinutes          string
+	ReaperIntervalSeconds       string
+	PlatformCommission          string
+	TaxLabel                    string
+	TaxRatePercent              string
+	WebhookMaxAttempts          string
+	ReconcileAfterMinutes       string
+	SlotHoldMinutes             string
+	SlotHoldStore               string
+	WaitlistOfferMinutes        string
+	RequestTTLHours             string
+	NoShowGraceMinutes          string
+	SessionMinMinutes           string
+	WaitlistIntervalSeconds     string
+	WebhookIntervalSeconds      string
+	ReconcileIntervalSeconds    string
+	CalendarSyncIntervalSeconds string
+	SettleIntervalSeconds       string
+	RedisAddress                string
+	RedisPassword               string
 }
 
 type header struct {
@@ -33,19 +65,41 @@ type header struct {
 }
 
 var EnvKeys = envKeys{
-	Env:                   "ENV",
-	ServerAddress:         "SERVER_ADDRESS",
-	CorsAllowedOrigins:    "CORS_ALLOWED_ORIGINS",
-	DBDriver:              "DB_DRIVER",
-	DBHost:                "DB_HOST",
-	DBPort:                "DB_PORT",
-	DBUser:                "DB_USER",
-	DBPassword:            "DB_PASSWORD",
-	DBName:                "DB_NAME",
-	JWTSecret:             "JWT_SECRET",
-	RazorpayKeyID:         "RAZORPAY_KEY_ID",
-	RazorpayKeySecret:     "RAZORPAY_KEY_SECRET",
-	RazorpayWebhookSecret: "RAZORPAY_WEBHOOK_SECRET",
+	Env:                         "ENV",
+	ServerAddress:               "SERVER_ADDRESS",
+	CorsAllowedOrigins:          "CORS_ALLOWED_ORIGINS",
+	DBDriver:                    "DB_DRIVER",
+	DBHost:                      "DB_HOST",
+	DBPort:                      "DB_PORT",
+	DBUser:                      "DB_USER",
+	DBPassword:                  "DB_PASSWORD",
+	DBName:                      "DB_NAME",
+	JWTSecret:                   "JWT_SECRET",
+	RazorpayKeyID:               "RAZORPAY_KEY_ID",
+	RazorpayKeySecret:           "RAZORPAY_KEY_SECRET",
+	RazorpayWebhookSecret:       "RAZORPAY_WEBHOOK_SECRET",
+	PaymentGateway:              "PAYMENT_GATEWAY",
+	FakeGatewaySecret:           "FAKE_GATEWAY_SECRET",
+	BookingHoldMinutes:          "BOOKING_HOLD_MINUTES",
+	ReaperIntervalSeconds:       "REAPER_INTERVAL_SECONDS",
+	PlatformCommission:          "PLATFORM_COMMISSION_PERCENT",
+	TaxLabel:                    "TAX_LABEL",
+	TaxRatePercent:              "TAX_RATE_PERCENT",
+	WebhookMaxAttempts:          "WEBHOOK_MAX_ATTEMPTS",
+	ReconcileAfterMinutes:       "PAYMENT_RECONCILE_AFTER_MINUTES",
+	SlotHoldMinutes:             "SLOT_HOLD_MINUTES",
+	SlotHoldStore:               "SLOT_HOLD_STORE",
+	WaitlistOfferMinutes:        "WAITLIST_OFFER_MINUTES",
+	RequestTTLHours:             "BOOKING_REQUEST_TTL_HOURS",
+	NoShowGraceMinutes:          "NO_SHOW_GRACE_MINUTES",
+	SessionMinMinutes:           "SESSION_MIN_MINUTES",
+	WaitlistIntervalSeconds:     "WAITLIST_INTERVAL_SECONDS",
+	WebhookIntervalSeconds:      "WEBHOOK_RETRY_INTERVAL_SECONDS",
+	ReconcileIntervalSeconds:    "PAYMENT_RECONCILE_INTERVAL_SECONDS",
+	CalendarSyncIntervalSeconds: "CALENDAR_SYNC_INTERVAL_SECONDS",
+	SettleIntervalSeconds:       "SESSION_SETTLE_INTERVAL_SECONDS",
+	RedisAddress:                "REDIS_ADDRESS",
+	RedisPassword:               "REDIS_PASSWORD",
 }
 
 var Headers = header{
@@ -1,11 +1,27 @@
 package dtos
 
+import "time"
+
 type AvailableSlot struct {
-	Start string `json:"start"`
-	End   string `json:"end"`
+	Start    string    `json:"start"` // HH:MM in the response timezone
+	End      string    `json:"end"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+
+	// Group services only: seats still free in this session
+	SeatsLeft int `json:"seats_left,omitempty"`
 }
 
 type AvailabilityResponse struct {
-	Date  string          `json:"date"`
-	Slots []AvailableSlot `json:"slots"`
+	Date     string          `json:"date"`
+	Timezone string          `json:"timezone"`
+	Slots    []AvailableSlot `json:"slots"`
+}
+
+type AvailabilityCalendarResponse struct {
+	From           string                 `json:"from"`
+	To             string                 `json:"to"`
+	Timezone       string                 `json:"timezone"`
+	FirstAvailable *AvailableSlot         `json:"first_available"` // null when the range is fully booked
+	Days           []AvailabilityResponse `json:"days"`
 }
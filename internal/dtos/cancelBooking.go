@@ -0,0 +1,40 @@
+package dtos
+
+import "github.com/google/uuid"
+
+// --------------------
+// CANCEL BOOKING
+// --------------------
+type CancelBookingRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type CancelBookingResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Status         string    `json:"status"`
+	CancelledBy    string    `json:"cancelled_by"`
+	RefundType     string    `json:"refund_type"` // full | partial | none
+	RefundPercent  int       `json:"refund_percent"`
+	RefundCents    int       `json:"refund_cents"`
+	RefundStatus   string    `json:"refund_status,omitempty"`   // pending | processed | failed
+	CreditReturned bool      `json:"credit_returned,omitempty"` // package credit given back
+	Currency       string    `json:"currency"`
+}
+
+// CancelSeriesResponse lists the occurrences a series cancellation
+// cancelled; occurrences already past or cancelled are left alone
+type CancelSeriesResponse struct {
+	ID          uuid.UUID               `json:"id"`
+	Cancelled   []CancelBookingResponse `json:"cancelled"`
+	RefundCents int                     `json:"refund_cents"`
+	Currency    string                  `json:"currency"`
+}
+
+// --------------------
+// RESCHEDULE BOOKING
+// --------------------
+type RescheduleBookingRequest struct {
+	BookingDate string `json:"booking_date" binding:"required"` // YYYY-MM-DD
+	StartTime   string `json:"start_time" binding:"required"`   // HH:MM
+	Timezone    string `json:"timezone"`                        // IANA zone of date and time, defaults to the mentor's
+}
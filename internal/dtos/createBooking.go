@@ -1,6 +1,10 @@
 package dtos
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // --------------------
 // CREATE BOOKING
@@ -11,6 +15,23 @@ type CreateBookingRequest struct {
 	ServiceID   uuid.UUID `json:"service_id" binding:"required"`
 	BookingDate string    `json:"booking_date" binding:"required"` // YYYY-MM-DD
 	StartTime   string    `json:"start_time" binding:"required"`   // HH:MM
+	Timezone    string    `json:"timezone"`                        // IANA zone of date and time, defaults to the mentor's
+	PromoCode   string    `json:"promo_code"`
+
+	// Pay with a credit from this package purchase instead of a new payment
+	PackagePurchaseID *uuid.UUID `json:"package_purchase_id"`
+
+	// Token of a slot hold taken for this slot, consumed by the booking
+	HoldToken string `json:"hold_token"`
+
+	// Book the same time every week; the date above is the first occurrence
+	Recurrence *BookingRecurrence `json:"recurrence"`
+}
+
+type BookingRecurrence struct {
+	Frequency string `json:"frequency" binding:"required,oneof=weekly"`
+	Interval  int    `json:"interval" binding:"omitempty,min=1,max=4"` // weeks between occurrences, defaults to 1
+	Count     int    `json:"count" binding:"required,min=2,max=26"`
 }
 
 // --------------------
@@ -18,11 +39,39 @@ type CreateBookingRequest struct {
 // --------------------
 
 type BookingResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Status    string    `json:"status"`
-	Date      string    `json:"date"`
-	StartTime string    `json:"start_time"`
-	EndTime   string    `json:"end_time"`
-	Price     int       `json:"price_cents"`
-	Currency  string    `json:"currency"`
+	ID             uuid.UUID `json:"id"`
+	Status         string    `json:"status"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	Timezone       string    `json:"timezone"`
+	Date           string    `json:"date"`
+	StartTime      string    `json:"start_time"`
+	EndTime        string    `json:"end_time"`
+	Price          int       `json:"price_cents"`
+	DiscountCents  int       `json:"discount_cents"`
+	PromoCode      string    `json:"promo_code,omitempty"`
+	Currency       string    `json:"currency"`
+	FormattedPrice string    `json:"formatted_price"`
+}
+
+// --------------------
+// RECURRING SERIES
+// --------------------
+
+type BookingSeriesResponse struct {
+	ID             uuid.UUID         `json:"id"`
+	Frequency      string            `json:"frequency"`
+	Interval       int               `json:"interval"`
+	Occurrences    int               `json:"occurrences"`
+	Price          int               `json:"price_cents"` // for all occurrences
+	Currency       string            `json:"currency"`
+	FormattedPrice string            `json:"formatted_price"`
+	Bookings       []BookingResponse `json:"bookings"`
+}
+
+// SeriesOccurrenceFailure is an occurrence that kept a series from being booked
+type SeriesOccurrenceFailure struct {
+	Date      string `json:"date"`
+	StartTime string `json:"start_time"`
+	Reason    string `json:"reason"`
 }
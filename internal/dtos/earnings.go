@@ -0,0 +1,25 @@
+package dtos
+
+type EarningsBalance struct {
+	Currency         string `json:"currency"`
+	BalanceCents     int64  `json:"balance_cents"`
+	FormattedBalance string `json:"formatted_balance"`
+}
+
+type EarningsStatement struct {
+	PeriodStart     string `json:"period_start"` // YYYY-MM-DD
+	Currency        string `json:"currency"`
+	Bookings        int    `json:"bookings"`
+	GrossCents      int64  `json:"gross_cents"`
+	CommissionCents int64  `json:"commission_cents"`
+	RefundedCents   int64  `json:"refunded_cents"`
+	NetCents        int64  `json:"net_cents"`
+}
+
+type EarningsResponse struct {
+	Period     string              `json:"period"` // day | week | month
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Balances   []EarningsBalance   `json:"balances"`
+	Statements []EarningsStatement `json:"statements"`
+}
@@ -0,0 +1,24 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalCalendarRequest subscribes to a calendar by URL (https:// or
+// webcal://), e.g. the secret iCal address of a Google Calendar
+type ExternalCalendarRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	URL  string `json:"url" binding:"required"`
+}
+
+type ExternalCalendarResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Source       string     `json:"source"` // url | upload
+	URL          string     `json:"url,omitempty"`
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	LastError    *string    `json:"last_error"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -1,13 +1,21 @@
 package dtos
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type CreateMentorAvailabilityRequest struct {
-	DayOfWeek int    `json:"day_of_week" binding:"required,min=0,max=6"`
-	StartTime string `json:"start_time" binding:"required"` // "10:00"
-	EndTime   string `json:"end_time" binding:"required"`   // "18:00"
+	DayOfWeek int    `json:"day_of_week" binding:"min=0,max=6"` // 0 = Sunday
+	StartTime string `json:"start_time" binding:"required"`     // "10:00"
+	EndTime   string `json:"end_time" binding:"required"`       // "18:00", "00:00" for midnight
+}
+
+// ReplaceMentorAvailabilityRequest replaces the mentor's whole week.
+// An empty list clears it.
+type ReplaceMentorAvailabilityRequest struct {
+	Rules []CreateMentorAvailabilityRequest `json:"rules" binding:"dive"`
 }
 
 type MentorAvailabilityResponse struct {
@@ -16,3 +24,39 @@ type MentorAvailabilityResponse struct {
 	StartTime string    `json:"start_time"`
 	EndTime   string    `json:"end_time"`
 }
+
+// MentorAvailabilityChangeResponse is the mentor's week after a change
+// plus the upcoming bookings that now fall outside their availability
+type MentorAvailabilityChangeResponse struct {
+	Rules            []MentorAvailabilityResponse `json:"rules"`
+	AffectedBookings []AffectedBooking            `json:"affected_bookings"`
+}
+
+type AffectedBooking struct {
+	ID       uuid.UUID `json:"id"`
+	Status   string    `json:"status"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// MentorAvailabilityOverrideRequest blocks or adds time on specific dates.
+// Leave the times out to block whole days (e.g. a vacation from
+// start_date to end_date).
+type MentorAvailabilityOverrideRequest struct {
+	Kind      string `json:"kind" binding:"required,oneof=block extra"`
+	StartDate string `json:"start_date" binding:"required"` // YYYY-MM-DD, mentor's timezone
+	EndDate   string `json:"end_date"`                      // inclusive, defaults to start_date
+	StartTime string `json:"start_time"`                    // HH:MM
+	EndTime   string `json:"end_time"`                      // HH:MM, at or before start_time runs past midnight
+	Reason    string `json:"reason" binding:"max=200"`
+}
+
+type MentorAvailabilityOverrideResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Kind      string    `json:"kind"`
+	StartDate string    `json:"start_date"`
+	EndDate   string    `json:"end_date"`
+	StartTime string    `json:"start_time,omitempty"`
+	EndTime   string    `json:"end_time,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
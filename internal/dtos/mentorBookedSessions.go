@@ -1,14 +1,22 @@
 package dtos
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type MentorBookedSessionResponse struct {
-	ID           uuid.UUID `json:"id"`
-	UserUsername string    `json:"user_username"`
-	ServiceTitle string    `json:"service_title"`
-	BookingDate  string    `json:"booking_date"`
-	StartTime    string    `json:"start_time"`
-	EndTime      string    `json:"end_time"`
-	PriceCents   int       `json:"price_cents"`
-	Currency     string    `json:"currency"`
+	ID             uuid.UUID `json:"id"`
+	UserUsername   string    `json:"user_username"`
+	ServiceTitle   string    `json:"service_title"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	Timezone       string    `json:"timezone"` // zone of booking_date, start_time and end_time
+	BookingDate    string    `json:"booking_date"`
+	StartTime      string    `json:"start_time"`
+	EndTime        string    `json:"end_time"`
+	PriceCents     int       `json:"price_cents"`
+	Currency       string    `json:"currency"`
+	FormattedPrice string    `json:"formatted_price"`
 }
@@ -41,3 +41,12 @@ type MentorInfo struct {
 	Timezone string    `json:"timezone"`
 	IsActive bool      `json:"is_active"`
 }
+
+// MentorBookingSettings controls when learners can book a mentor.
+// A zero max_advance_days or daily_session_cap means no limit.
+type MentorBookingSettings struct {
+	BufferMinutes    int `json:"buffer_minutes" binding:"min=0,max=240"`
+	MinNoticeMinutes int `json:"min_notice_minutes" binding:"min=0,max=43200"`
+	MaxAdvanceDays   int `json:"max_advance_days" binding:"min=0,max=730"`
+	DailySessionCap  int `json:"daily_session_cap" binding:"min=0,max=48"`
+}
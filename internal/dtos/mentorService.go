@@ -6,16 +6,27 @@ type CreateMentorServiceRequest struct {
 	Title           string `json:"title" binding:"required,min=3"`
 	Description     string `json:"description"`
 	DurationMinutes int    `json:"duration_minutes" binding:"required,oneof=30 60"`
-	PriceCents      int    `json:"price_cents" binding:"required,min=0"`
+	PriceCents      int    `json:"price_cents" binding:"min=0"` // 0 for a free service
 	Currency        string `json:"currency" binding:"required,len=3"`
+
+	// Defaults to one_on_one; group services need a capacity
+	Type     string `json:"type" binding:"omitempty,oneof=one_on_one group"`
+	Capacity int    `json:"capacity" binding:"omitempty,min=2,max=50"`
+
+	// Bookings are only requests until the mentor approves them
+	RequiresApproval bool `json:"requires_approval"`
 }
 
 type MentorServiceResponse struct {
-	ID              uuid.UUID `json:"id"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	DurationMinutes int       `json:"duration_minutes"`
-	PriceCents      int       `json:"price_cents"`
-	Currency        string    `json:"currency"`
-	IsActive        bool      `json:"is_active"`
+	ID               uuid.UUID `json:"id"`
+	Title            string    `json:"title"`
+	Description      string    `json:"description"`
+	DurationMinutes  int       `json:"duration_minutes"`
+	PriceCents       int       `json:"price_cents"`
+	Currency         string    `json:"currency"`
+	FormattedPrice   string    `json:"formatted_price"`
+	Type             string    `json:"type"`
+	Capacity         int       `json:"capacity"`
+	RequiresApproval bool      `json:"requires_approval"`
+	IsActive         bool      `json:"is_active"`
 }
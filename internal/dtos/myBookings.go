@@ -1,15 +1,23 @@
 package dtos
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type MyBookingResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Mentor    string    `json:"mentor"` // username
-	Service   string    `json:"service"`
-	Date      string    `json:"date"`
-	StartTime string    `json:"start_time"`
-	EndTime   string    `json:"end_time"`
-	Status    string    `json:"status"`
-	Price     int       `json:"price_cents"`
-	Currency  string    `json:"currency"`
+	ID             uuid.UUID `json:"id"`
+	Mentor         string    `json:"mentor"` // username
+	Service        string    `json:"service"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	Timezone       string    `json:"timezone"` // zone of date, start_time and end_time
+	Date           string    `json:"date"`
+	StartTime      string    `json:"start_time"`
+	EndTime        string    `json:"end_time"`
+	Status         string    `json:"status"`
+	Price          int       `json:"price_cents"`
+	Currency       string    `json:"currency"`
+	FormattedPrice string    `json:"formatted_price"`
 }
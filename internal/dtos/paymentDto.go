@@ -2,9 +2,12 @@ package dtos
 
 import "github.com/google/uuid"
 
-// Step 1: create Razorpay order
+// Step 1: create Razorpay order, for a booking, a package purchase or a
+// recurring booking series
 type CreatePaymentRequest struct {
-	BookingID uuid.UUID `json:"booking_id" binding:"required"`
+	BookingID         uuid.UUID `json:"booking_id"`
+	PackagePurchaseID uuid.UUID `json:"package_purchase_id"`
+	SeriesID          uuid.UUID `json:"series_id"`
 }
 
 type CreatePaymentResponse struct {
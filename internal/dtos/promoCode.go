@@ -0,0 +1,35 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreatePromoCodeRequest struct {
+	Code           string     `json:"code" binding:"required,min=3,max=32,alphanum"`
+	DiscountType   string     `json:"discount_type" binding:"required,oneof=percent fixed"`
+	PercentOff     int        `json:"percent_off" binding:"omitempty,min=1,max=100"`
+	AmountOffCents int64      `json:"amount_off_cents" binding:"omitempty,min=1"`
+	Currency       string     `json:"currency" binding:"omitempty,len=3"`
+	MaxRedemptions *int       `json:"max_redemptions" binding:"omitempty,min=1"`
+	MaxPerUser     *int       `json:"max_per_user" binding:"omitempty,min=1"`
+	StartsAt       *time.Time `json:"starts_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+type PromoCodeResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	Code            string     `json:"code"`
+	MentorID        *uuid.UUID `json:"mentor_id"` // null for platform-wide codes
+	DiscountType    string     `json:"discount_type"`
+	PercentOff      int        `json:"percent_off,omitempty"`
+	AmountOffCents  int64      `json:"amount_off_cents,omitempty"`
+	Currency        *string    `json:"currency,omitempty"`
+	MaxRedemptions  *int       `json:"max_redemptions"`
+	MaxPerUser      *int       `json:"max_per_user"`
+	RedemptionCount int        `json:"redemption_count"`
+	StartsAt        *time.Time `json:"starts_at"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	IsActive        bool       `json:"is_active"`
+}
@@ -5,11 +5,22 @@ type RazorpayWebhookEvent struct {
 	Payload struct {
 		Payment struct {
 			Entity struct {
-				ID      string `json:"id"`
-				OrderID string `json:"order_id"`
-				Status  string `json:"status"`
-				Amount  int64  `json:"amount"`
+				ID       string `json:"id"`
+				OrderID  string `json:"order_id"`
+				Status   string `json:"status"`
+				Amount   int64  `json:"amount"`
+				Currency string `json:"currency"`
 			} `json:"entity"`
 		} `json:"payment"`
+		Refund struct {
+			Entity struct {
+				ID        string `json:"id"`
+				PaymentID string `json:"payment_id"`
+				Status    string `json:"status"`
+				Amount    int64  `json:"amount"`
+				Currency  string `json:"currency"`
+				Receipt   string `json:"receipt"`
+			} `json:"entity"`
+		} `json:"refund"`
 	} `json:"payload"`
 }
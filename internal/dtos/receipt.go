@@ -0,0 +1,37 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReceiptLine struct {
+	Label           string `json:"label"`
+	AmountCents     int64  `json:"amount_cents"`
+	FormattedAmount string `json:"formatted_amount"`
+}
+
+type ReceiptResponse struct {
+	InvoiceNumber string    `json:"invoice_number"`
+	IssuedAt      time.Time `json:"issued_at"`
+	PaymentID     uuid.UUID `json:"payment_id"`
+
+	BookingID         *uuid.UUID `json:"booking_id,omitempty"`
+	PackagePurchaseID *uuid.UUID `json:"package_purchase_id,omitempty"`
+
+	Seller         string `json:"seller"`
+	LearnerName    string `json:"learner_name"`
+	LearnerEmail   string `json:"learner_email"`
+	MentorName     string `json:"mentor_name"`
+	MentorUsername string `json:"mentor_username"`
+
+	Description string `json:"description"`
+	Currency    string `json:"currency"`
+
+	// Discount, subtotal, tax and total, in that order
+	Lines []ReceiptLine `json:"lines"`
+
+	TotalCents     int64  `json:"total_cents"`
+	FormattedTotal string `json:"formatted_total"`
+}
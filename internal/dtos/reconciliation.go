@@ -0,0 +1,26 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconciliationReport summarises one reconciliation run. Every payment
+// whose local state disagreed with the gateway is listed in Mismatches
+// together with what was done about it.
+type ReconciliationReport struct {
+	StartedAt  time.Time                `json:"started_at"`
+	Checked    int                      `json:"checked"`
+	Mismatches []ReconciliationMismatch `json:"mismatches"`
+}
+
+type ReconciliationMismatch struct {
+	PaymentID      uuid.UUID `json:"payment_id"`
+	GatewayOrderID string    `json:"gateway_order_id"`
+	LocalStatus    string    `json:"local_status"`
+	GatewayStatus  string    `json:"gateway_status,omitempty"`
+	// marked_paid | refunded | marked_failed | needs_review | error
+	Resolution string `json:"resolution"`
+	Detail     string `json:"detail,omitempty"`
+}
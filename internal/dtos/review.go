@@ -0,0 +1,27 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+type ReviewResponse struct {
+	ID        uuid.UUID `json:"id"`
+	BookingID uuid.UUID `json:"booking_id"`
+	Reviewer  string    `json:"reviewer,omitempty"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MentorReviewsResponse struct {
+	AverageRating float64          `json:"average_rating"`
+	ReviewCount   int              `json:"review_count"`
+	Reviews       []ReviewResponse `json:"reviews"`
+}
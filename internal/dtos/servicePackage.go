@@ -0,0 +1,39 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateServicePackageRequest struct {
+	ServiceID    uuid.UUID `json:"service_id" binding:"required"`
+	Title        string    `json:"title" binding:"required,min=3"`
+	SessionCount int       `json:"session_count" binding:"required,min=2,max=50"`
+	PriceCents   int       `json:"price_cents" binding:"required,min=1"`
+	ValidityDays *int      `json:"validity_days" binding:"omitempty,min=1"`
+}
+
+type ServicePackageResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ServiceID      uuid.UUID `json:"service_id"`
+	Title          string    `json:"title"`
+	SessionCount   int       `json:"session_count"`
+	PriceCents     int       `json:"price_cents"`
+	Currency       string    `json:"currency"`
+	FormattedPrice string    `json:"formatted_price"`
+	ValidityDays   *int      `json:"validity_days"`
+}
+
+type PackagePurchaseResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	PackageID        uuid.UUID  `json:"package_id"`
+	ServiceID        uuid.UUID  `json:"service_id"`
+	Status           string     `json:"status"`
+	CreditsTotal     int        `json:"credits_total"`
+	CreditsRemaining int        `json:"credits_remaining"`
+	PriceCents       int        `json:"price_cents"`
+	Currency         string     `json:"currency"`
+	FormattedPrice   string     `json:"formatted_price"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,22 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateSlotHoldRequest struct {
+	ServiceID   uuid.UUID `json:"service_id" binding:"required"`
+	BookingDate string    `json:"booking_date" binding:"required"` // YYYY-MM-DD
+	StartTime   string    `json:"start_time" binding:"required"`   // HH:MM
+	Timezone    string    `json:"timezone"`                        // IANA zone of date and time, defaults to the mentor's
+}
+
+type SlotHoldResponse struct {
+	HoldToken string    `json:"hold_token"`
+	ServiceID uuid.UUID `json:"service_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
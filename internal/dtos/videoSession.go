@@ -75,4 +75,8 @@ type SessionInfoResponse struct {
 	EndTimeInTZ      string `json:"end_time_in_tz"`
 	OtherPartyJoined bool   `json:"other_party_joined"`
 	OtherPartyName   string `json:"other_party_name"`
+
+	// Group sessions: everyone with a seat joins the same room
+	IsGroup      bool `json:"is_group"`
+	Participants int  `json:"participants,omitempty"`
 }
@@ -0,0 +1,42 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JoinWaitlistRequest struct {
+	// Leave out to wait for any of the mentor's one-on-one services
+	ServiceID *uuid.UUID `json:"service_id"`
+
+	PreferredDays []int64 `json:"preferred_days" binding:"omitempty,max=7,dive,min=0,max=6"` // 0 = Sunday
+	PreferredFrom string  `json:"preferred_from"`                                            // HH:MM
+	PreferredTo   string  `json:"preferred_to"`                                              // HH:MM
+	Timezone      string  `json:"timezone"`                                                  // IANA zone of the preferences, defaults to the mentor's
+}
+
+type WaitlistOfferResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ServiceID uuid.UUID `json:"service_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Date      string    `json:"date"`       // YYYY-MM-DD in the entry's timezone
+	StartTime string    `json:"start_time"` // HH:MM in the entry's timezone
+	EndTime   string    `json:"end_time"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type WaitlistEntryResponse struct {
+	ID            uuid.UUID               `json:"id"`
+	MentorID      uuid.UUID               `json:"mentor_id"`
+	ServiceID     *uuid.UUID              `json:"service_id,omitempty"`
+	PreferredDays []int64                 `json:"preferred_days"`
+	PreferredFrom string                  `json:"preferred_from,omitempty"`
+	PreferredTo   string                  `json:"preferred_to,omitempty"`
+	Timezone      string                  `json:"timezone"`
+	Status        string                  `json:"status"`
+	Position      int                     `json:"position,omitempty"` // place in the mentor's line while waiting
+	Offers        []WaitlistOfferResponse `json:"offers"`
+	CreatedAt     time.Time               `json:"created_at"`
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -51,6 +52,11 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 		return
 	}
 
+	if req.Recurrence != nil {
+		h.createSeries(c, userID, &req)
+		return
+	}
+
 	resp, err := h.bookingService.CreateBooking(userID, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -62,6 +68,151 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	c.JSON(http.StatusCreated, resp)
 }
 
+// createSeries books a recurring series. When some dates can't be booked
+// the response lists them and nothing is booked.
+func (h *BookingHandler) createSeries(
+	c *gin.Context,
+	userID uuid.UUID,
+	req *dtos.CreateBookingRequest,
+) {
+
+	resp, err := h.bookingService.CreateSeries(userID, req)
+
+	var unavailable *services.SeriesUnavailableError
+	if errors.As(err, &unavailable) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    err.Error(),
+			"failures": unavailable.Failures,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *BookingHandler) GetSeries(c *gin.Context) {
+
+	userIDStr, _ := c.Get("user_id")
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	seriesID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid series id"})
+		return
+	}
+
+	resp, err := h.bookingService.GetSeries(userID, seriesID, c.Query("tz"))
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "booking series not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CancelSeries cancels every upcoming occurrence of a series. Single
+// occurrences are cancelled through CancelBooking.
+func (h *BookingHandler) CancelSeries(c *gin.Context) {
+
+	userIDStr, _ := c.Get("user_id")
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	seriesID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid series id"})
+		return
+	}
+
+	var req dtos.CancelBookingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	resp, err := h.bookingService.CancelSeries(userID, seriesID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// HoldSlot reserves a slot of the mentor for the caller for a few minutes
+func (h *BookingHandler) HoldSlot(c *gin.Context) {
+	var req dtos.CreateSlotHoldRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.bookingService.HoldSlot(userID, c.Param("username"), &req)
+	if errors.Is(err, repositories.ErrSlotHeld) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *BookingHandler) ReleaseHold(c *gin.Context) {
+
+	userIDStr, _ := c.Get("user_id")
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	err = h.bookingService.ReleaseHold(userID, c.Param("username"), c.Param("token"))
+	switch {
+	case errors.Is(err, repositories.ErrHoldNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil && err.Error() == "unauthorized":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *BookingHandler) GetMyBookings(c *gin.Context) {
 
 	userIDStr, _ := c.Get("user_id")
@@ -71,7 +222,11 @@ func (h *BookingHandler) GetMyBookings(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.bookingService.GetMyBookings(userID)
+	resp, err := h.bookingService.GetMyBookings(userID, c.Query("tz"))
+	if errors.Is(err, services.ErrInvalidTimezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch bookings"})
 		return
@@ -97,7 +252,11 @@ func (h *BookingHandler) GetMentorBookedSessions(c *gin.Context) {
 	}
 
 	// Get booked sessions for this mentor
-	sessions, err := h.bookingService.GetMentorBookedSessions(mentor.ID)
+	sessions, err := h.bookingService.GetMentorBookedSessions(mentor, c.Query("tz"))
+	if errors.Is(err, services.ErrInvalidTimezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booked sessions"})
 		return
@@ -105,3 +264,66 @@ func (h *BookingHandler) GetMentorBookedSessions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, sessions)
 }
+
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+
+	userIDStr, _ := c.Get("user_id")
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	// The reason is optional, so an empty body is fine
+	var req dtos.CancelBookingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	resp, err := h.bookingService.CancelBooking(userID, bookingID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *BookingHandler) RescheduleBooking(c *gin.Context) {
+	var req dtos.RescheduleBookingRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	resp, err := h.bookingService.RescheduleBooking(userID, bookingID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+// GetMentorRequests lists the booking requests waiting for the calling
+// mentor's approval
+func (h *BookingHandler) GetMentorRequests(c *gin.Context) {
+	mentor, ok := h.callingMentor(c)
+	if !ok {
+		return
+	}
+
+	requests, err := h.bookingService.GetMentorRequests(mentor, c.Query("tz"))
+	if errors.Is(err, services.ErrInvalidTimezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booking requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// ApproveBooking accepts a booking request; the learner can then pay for it
+func (h *BookingHandler) ApproveBooking(c *gin.Context) {
+	mentor, ok := h.callingMentor(c)
+	if !ok {
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	resp, err := h.bookingService.ApproveBooking(mentor, bookingID)
+	if err != nil {
+		respondRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *BookingHandler) DeclineBooking(c *gin.Context) {
+	mentor, ok := h.callingMentor(c)
+	if !ok {
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	// The reason is optional, so an empty body is fine
+	var req dtos.CancelBookingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	resp, err := h.bookingService.DeclineBooking(mentor, bookingID, &req)
+	if err != nil {
+		respondRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// callingMentor loads the caller's mentor profile, answering 404 when
+// they have none
+func (h *BookingHandler) callingMentor(c *gin.Context) (*models.MentorProfile, bool) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return nil, false
+	}
+
+	mentor, err := h.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mentor profile not found"})
+		return nil, false
+	}
+
+	return mentor, true
+}
+
+func respondRequestError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "unauthorized":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "booking not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "booking is not awaiting approval":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+type EarningsHandler struct {
+	ledgerService *services.LedgerService
+}
+
+func NewEarningsHandler(ledgerService *services.LedgerService) *EarningsHandler {
+	return &EarningsHandler{ledgerService: ledgerService}
+}
+
+// GetEarnings handles GET /api/mentor/earnings?period=month&from=2025-01-01&to=2025-06-30
+func (h *EarningsHandler) GetEarnings(c *gin.Context) {
+	resp, ok := h.earnings(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ExportEarnings handles GET /api/mentor/earnings/export?period=month&from=2025-01-01&to=2025-06-30
+func (h *EarningsHandler) ExportEarnings(c *gin.Context) {
+	resp, ok := h.earnings(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="earnings_%s_%s.csv"`, resp.From, resp.To),
+	)
+
+	if err := h.ledgerService.WriteStatementsCSV(c.Writer, resp.Statements); err != nil {
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+// earnings parses the shared query parameters and loads the statements.
+// It writes the error response itself and reports whether to continue.
+func (h *EarningsHandler) earnings(c *gin.Context) (*dtos.EarningsResponse, bool) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return nil, false
+	}
+
+	from, to, err := parseEarningsRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	period := c.DefaultQuery("period", "month")
+
+	resp, err := h.ledgerService.GetEarnings(c.Request.Context(), userID, period, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return resp, true
+}
+
+// parseEarningsRange parses inclusive YYYY-MM-DD dates into a half-open
+// [from, to) range. Defaults to the last 12 months up to today.
+func parseEarningsRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	to := today
+	if toStr != "" {
+		t, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to date, expected YYYY-MM-DD")
+		}
+		to = t
+	}
+
+	from := to.AddDate(-1, 0, 1)
+	if fromStr != "" {
+		f, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+		from = f
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+// FakeGatewayHandler drives the in-process fake gateway. It is only
+// registered when PAYMENT_GATEWAY=fake and stands in for the hosted
+// checkout page during offline end-to-end runs.
+type FakeGatewayHandler struct {
+	gateway *services.FakeGateway
+}
+
+func NewFakeGatewayHandler(gateway *services.FakeGateway) *FakeGatewayHandler {
+	return &FakeGatewayHandler{gateway: gateway}
+}
+
+// Capture pays an order and returns what the checkout would hand back:
+// the verify payload for /api/payments/verify and the signed webhook
+// for /api/webhooks/fake.
+func (h *FakeGatewayHandler) Capture(c *gin.Context) {
+	paymentID, signature, webhook, err := h.gateway.CapturePayment(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"razorpay_payment_id": paymentID,
+		"razorpay_signature":  signature,
+		"webhook": gin.H{
+			"payload":   string(webhook.Payload),
+			"signature": webhook.Header.Get(services.FakeSignatureHeader),
+		},
+	})
+}
+
+// Fail declines an order and returns the signed payment.failed webhook
+func (h *FakeGatewayHandler) Fail(c *gin.Context) {
+	webhook, err := h.gateway.FailPayment(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook": gin.H{
+			"payload":   string(webhook.Payload),
+			"signature": webhook.Header.Get(services.FakeSignatureHeader),
+		},
+	})
+}
@@ -41,20 +41,114 @@ func (h *MentorAvailabilityHandler) Create(c *gin.Context) {
 
 	resp, err := h.mentorAvailabilityService.CreateRule(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot create availability"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, resp)
 }
 
+// ReplaceWeek replaces all of the mentor's weekly rules
+func (h *MentorAvailabilityHandler) ReplaceWeek(c *gin.Context) {
+	var req dtos.ReplaceMentorAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.mentorAvailabilityService.ReplaceWeek(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MentorAvailabilityHandler) Update(c *gin.Context) {
+	var req dtos.CreateMentorAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid availability id"})
+		return
+	}
+
+	resp, err := h.mentorAvailabilityService.UpdateRule(userID, id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Delete removes a weekly rule. Unlike overrides it answers 200 with the
+// bookings left outside the mentor's availability.
+func (h *MentorAvailabilityHandler) Delete(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid availability id"})
+		return
+	}
+
+	resp, err := h.mentorAvailabilityService.DeleteRule(userID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *MentorAvailabilityHandler) GetByUsername(c *gin.Context) {
 	username := c.Param("username")
 
 	// Check if query parameters are present for available slots
 	date := c.Query("date")
+	from := c.Query("from")
 	serviceIDStr := c.Query("service_id")
 
+	if from != "" && serviceIDStr != "" {
+		// Return a calendar of available slots from `from` to `to`
+		serviceID, err := uuid.Parse(serviceIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service_id"})
+			return
+		}
+
+		resp, err := h.availabilityService.GetCalendar(username, serviceID, from, c.Query("to"), c.Query("tz"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
 	if date != "" && serviceIDStr != "" {
 		// Return available slots for a specific date and service
 		serviceID, err := uuid.Parse(serviceIDStr)
@@ -63,7 +157,7 @@ func (h *MentorAvailabilityHandler) GetByUsername(c *gin.Context) {
 			return
 		}
 
-		resp, err := h.availabilityService.GetAvailableSlots(username, serviceID, date)
+		resp, err := h.availabilityService.GetAvailableSlots(username, serviceID, date, c.Query("tz"))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -94,7 +188,7 @@ func (h *MentorAvailabilityHandler) Get(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.availabilityService.GetAvailableSlots(username, serviceID, date)
+	resp, err := h.availabilityService.GetAvailableSlots(username, serviceID, date, c.Query("tz"))
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
@@ -102,3 +196,91 @@ func (h *MentorAvailabilityHandler) Get(c *gin.Context) {
 
 	c.JSON(200, resp)
 }
+
+func (h *MentorAvailabilityHandler) CreateOverride(c *gin.Context) {
+	var req dtos.MentorAvailabilityOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.mentorAvailabilityService.CreateOverride(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListOverrides lists the mentor's overrides, optionally ?from=&to= (YYYY-MM-DD)
+func (h *MentorAvailabilityHandler) ListOverrides(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.mentorAvailabilityService.ListOverrides(userID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MentorAvailabilityHandler) UpdateOverride(c *gin.Context) {
+	var req dtos.MentorAvailabilityOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid override id"})
+		return
+	}
+
+	resp, err := h.mentorAvailabilityService.UpdateOverride(userID, id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MentorAvailabilityHandler) DeleteOverride(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid override id"})
+		return
+	}
+
+	if err := h.mentorAvailabilityService.DeleteOverride(userID, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
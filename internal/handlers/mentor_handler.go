@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -48,6 +49,12 @@ func (h *MentorHandler) CreateProfile(c *gin.Context) {
 	}
 
 	profile, err := h.mentorProfileService.CreateProfile(userID, &req)
+	if errors.Is(err, services.ErrInvalidTimezone) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "mentor profile already exists or other error",
@@ -79,3 +86,52 @@ func (h *MentorHandler) GetProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+func (h *MentorHandler) GetBookingSettings(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	resp, err := h.mentorProfileService.GetBookingSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "mentor not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MentorHandler) UpdateBookingSettings(c *gin.Context) {
+	var req dtos.MentorBookingSettings
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	resp, err := h.mentorProfileService.UpdateBookingSettings(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
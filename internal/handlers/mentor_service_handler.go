@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
 	"github.com/preetsinghmakkar/OpenCall/internal/services"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
 )
 
 type MentorServiceHandler struct {
@@ -37,19 +38,23 @@ func (h *MentorServiceHandler) Create(c *gin.Context) {
 	service, err := h.service.CreateService(userID, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "mentor profile not found",
+			"error": err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusCreated, dtos.MentorServiceResponse{
-		ID:              service.ID,
-		Title:           service.Title,
-		Description:     service.Description,
-		DurationMinutes: service.DurationMinutes,
-		PriceCents:      service.PriceCents,
-		Currency:        service.Currency,
-		IsActive:        service.IsActive,
+		ID:               service.ID,
+		Title:            service.Title,
+		Description:      service.Description,
+		DurationMinutes:  service.DurationMinutes,
+		PriceCents:       service.PriceCents,
+		Currency:         service.Currency,
+		FormattedPrice:   utils.FormatMoney(int64(service.PriceCents), service.Currency),
+		Type:             service.Type,
+		Capacity:         service.Capacity,
+		RequiresApproval: service.RequiresApproval,
+		IsActive:         service.IsActive,
 	})
 }
 
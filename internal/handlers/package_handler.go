@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+type PackageHandler struct {
+	packageService *services.PackageService
+}
+
+func NewPackageHandler(packageService *services.PackageService) *PackageHandler {
+	return &PackageHandler{packageService: packageService}
+}
+
+func (h *PackageHandler) Create(c *gin.Context) {
+	var req dtos.CreateServicePackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.packageService.CreatePackage(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *PackageHandler) GetByUsername(c *gin.Context) {
+	resp, err := h.packageService.GetPackagesByUsername(c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mentor packages not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Purchase starts buying a package; the client then pays for it through
+// POST /api/payments with the returned purchase ID
+func (h *PackageHandler) Purchase(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	packageID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid package id"})
+		return
+	}
+
+	resp, err := h.packageService.Purchase(userID, packageID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *PackageHandler) GetMyPurchases(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.packageService.GetMyPurchases(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch packages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -1,22 +1,25 @@
 package handlers
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
 	"github.com/preetsinghmakkar/OpenCall/internal/services"
 )
 
 type PaymentHandler struct {
 	service *services.PaymentService
+	inbox   *services.WebhookInbox
 }
 
-func NewPaymentHandler(s *services.PaymentService) *PaymentHandler {
-	return &PaymentHandler{service: s}
+func NewPaymentHandler(
+	s *services.PaymentService,
+	inbox *services.WebhookInbox,
+) *PaymentHandler {
+	return &PaymentHandler{service: s, inbox: inbox}
 }
 
 func (h *PaymentHandler) CreatePayment(c *gin.Context) {
@@ -28,7 +31,29 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 
 	userID, _ := uuid.Parse(c.GetString("user_id"))
 
-	payment, err := h.service.CreatePayment(c.Request.Context(), req.BookingID, userID)
+	var (
+		payment *models.Payment
+		err     error
+	)
+
+	targets := 0
+	for _, id := range []uuid.UUID{req.BookingID, req.PackagePurchaseID, req.SeriesID} {
+		if id != uuid.Nil {
+			targets++
+		}
+	}
+
+	switch {
+	case targets != 1:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of booking_id, package_purchase_id or series_id is required"})
+		return
+	case req.BookingID != uuid.Nil:
+		payment, err = h.service.CreatePayment(c.Request.Context(), req.BookingID, userID)
+	case req.PackagePurchaseID != uuid.Nil:
+		payment, err = h.service.CreatePackagePayment(c.Request.Context(), req.PackagePurchaseID, userID)
+	default:
+		payment, err = h.service.CreateSeriesPayment(c.Request.Context(), req.SeriesID, userID)
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -36,9 +61,12 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"payment_id":        payment.ID,
+		"gateway":           payment.Gateway,
+		"order_id":          payment.GatewayOrderID,
 		"razorpay_order_id": payment.GatewayOrderID,
 		"amount":            payment.Amount,
 		"currency":          payment.Currency,
+		"discount_cents":    payment.DiscountCents,
 	})
 }
 
@@ -62,38 +90,8 @@ func (h *PaymentHandler) VerifyPayment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "payment verified"})
 }
 
+// RazorpayWebhook keeps the original /webhooks/razorpay endpoint. It
+// stores the event in the webhook inbox like /webhooks/:gateway does.
 func (h *PaymentHandler) RazorpayWebhook(c *gin.Context) {
-	signature := c.GetHeader("X-Razorpay-Signature")
-
-	body, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		c.Status(http.StatusBadRequest)
-		return
-	}
-
-	if err := h.service.VerifyWebhookSignature(body, signature); err != nil {
-		c.Status(http.StatusUnauthorized)
-		return
-	}
-
-	var event dtos.RazorpayWebhookEvent
-	if err := json.Unmarshal(body, &event); err != nil {
-		c.Status(http.StatusBadRequest)
-		return
-	}
-
-	switch event.Event {
-	case "payment.captured":
-		if err := h.service.HandlePaymentCaptured(event); err != nil {
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-	case "payment.failed":
-		if err := h.service.HandlePaymentFailed(event); err != nil {
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-	}
-
-	c.Status(http.StatusOK)
+	receiveWebhook(c, h.inbox, "razorpay")
 }
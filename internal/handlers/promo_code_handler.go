@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+type PromoCodeHandler struct {
+	promoService *services.PromoService
+}
+
+func NewPromoCodeHandler(promoService *services.PromoService) *PromoCodeHandler {
+	return &PromoCodeHandler{promoService: promoService}
+}
+
+// CreateMentorCode creates a code scoped to the calling mentor's services
+func (h *PromoCodeHandler) CreateMentorCode(c *gin.Context) {
+	var req dtos.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.promoService.CreateMentorCode(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *PromoCodeHandler) ListMentorCodes(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.promoService.ListMentorCodes(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreatePlatformCode creates a platform-wide code (admin only)
+func (h *PromoCodeHandler) CreatePlatformCode(c *gin.Context) {
+	var req dtos.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	resp, err := h.promoService.CreatePlatformCode(adminID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *PromoCodeHandler) ListPlatformCodes(c *gin.Context) {
+	resp, err := h.promoService.ListPlatformCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch promo codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
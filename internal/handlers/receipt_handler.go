@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+type ReceiptHandler struct {
+	invoiceService *services.InvoiceService
+}
+
+func NewReceiptHandler(invoiceService *services.InvoiceService) *ReceiptHandler {
+	return &ReceiptHandler{invoiceService: invoiceService}
+}
+
+// GetReceipt returns the receipt of a payment as JSON, or as a PDF when
+// called with ?format=pdf or Accept: application/pdf
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user"})
+		return
+	}
+
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment id"})
+		return
+	}
+
+	invoice, err := h.invoiceService.GetReceipt(c.Request.Context(), userID, paymentID)
+	if err != nil {
+		if err.Error() == "unauthorized" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "pdf" || strings.Contains(c.GetHeader("Accept"), "application/pdf") {
+		c.Header(
+			"Content-Disposition",
+			fmt.Sprintf(`inline; filename="%s.pdf"`, invoice.Number),
+		)
+		c.Data(http.StatusOK, "application/pdf", h.invoiceService.RenderPDF(invoice))
+		return
+	}
+
+	c.JSON(http.StatusOK, h.invoiceService.ToResponse(invoice))
+}
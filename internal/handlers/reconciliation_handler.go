@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+type ReconciliationHandler struct {
+	reconciler *services.PaymentReconciler
+}
+
+func NewReconciliationHandler(reconciler *services.PaymentReconciler) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciler: reconciler}
+}
+
+// Reconcile runs a reconciliation pass now and returns its mismatch
+// report (admin only)
+func (h *ReconciliationHandler) Reconcile(c *gin.Context) {
+	report, err := h.reconciler.ReconcileOnce(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
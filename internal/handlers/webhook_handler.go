@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/services"
+)
+
+type WebhookHandler struct {
+	inbox *services.WebhookInbox
+}
+
+func NewWebhookHandler(inbox *services.WebhookInbox) *WebhookHandler {
+	return &WebhookHandler{inbox: inbox}
+}
+
+// Receive accepts events for the configured gateway at /webhooks/:gateway.
+// Events are only verified and stored here; they are applied in the
+// background, so a 200 means "stored", not "processed".
+func (h *WebhookHandler) Receive(c *gin.Context) {
+	receiveWebhook(c, h.inbox, c.Param("gateway"))
+}
+
+func receiveWebhook(c *gin.Context, inbox *services.WebhookInbox, gateway string) {
+	if gateway != inbox.GatewayName() {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	err = inbox.Receive(c.Request.Context(), body, c.Request.Header)
+	if errors.Is(err, services.ErrInvalidWebhook) {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		// Not stored: let the gateway deliver it again
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// List shows recent inbox events (admin only), e.g. ?status=failed
+func (h *WebhookHandler) List(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return
+	}
+
+	resp, err := h.inbox.List(c.Request.Context(), c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Replay queues a failed event for processing again (admin only)
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event id"})
+		return
+	}
+
+	resp, err := h.inbox.Replay(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ws "github.com/preetsinghmakkar/OpenCall/internal/websocket"
+)
+
+// Group sessions put the mentor and every paid attendee in one room.
+// Offers, answers and ICE candidates name their recipient ("to") and are
+// relayed with their sender ("from"), so each pair negotiates its own peer
+// connection. The mentor moderates: they pick the topology, mute and
+// remove attendees.
+
+// sendRoomState tells a client who joined a group session who is there,
+// and everyone else that they arrived
+func (h *WebSocketHandler) sendRoomState(session *ws.Session, client *ws.Client) {
+	state := ws.RoomStatePayload{
+		Role:         client.Role,
+		Topology:     session.GetTopology(),
+		Capacity:     session.Capacity,
+		Participants: []ws.ParticipantPayload{},
+	}
+
+	for _, c := range session.Participants() {
+		if c != client {
+			state.Participants = append(state.Participants, participantOf(c))
+		}
+	}
+
+	select {
+	case client.Send <- map[string]interface{}{
+		"type":    "room_state",
+		"payload": state,
+	}:
+	default:
+		fmt.Println("[WebSocket Group] WARNING: Failed to send room_state to", client.UserID)
+	}
+
+	session.BroadcastExcept(client, map[string]interface{}{
+		"type":    "participant_joined",
+		"payload": participantOf(client),
+	})
+}
+
+// handleGroupMessage handles the messages that work differently in group
+// sessions. It returns false for the ones handled like one-on-one calls.
+func (h *WebSocketHandler) handleGroupMessage(
+	client *ws.Client,
+	session *ws.Session,
+	msg ws.WebSocketMessage,
+) bool {
+
+	switch msg.Type {
+	case "offer", "answer", "ice_candidate":
+		h.relayGroupSignal(client, session, msg)
+
+	case "set_topology", "mute_participant", "remove_participant":
+		if client.Role != "mentor" {
+			fmt.Printf("[WebSocket Group] WARNING: %s from attendee %s ignored\n", msg.Type, client.UserID)
+			return true
+		}
+		h.handleModeration(session, msg)
+
+	case "raise_hand":
+		session.SendToRole("mentor", map[string]interface{}{
+			"type":    "hand_raised",
+			"payload": participantOf(client),
+		})
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// relayGroupSignal forwards an offer, answer or ICE candidate to the
+// participant named in its "to", if the topology lets the two connect
+func (h *WebSocketHandler) relayGroupSignal(
+	client *ws.Client,
+	session *ws.Session,
+	msg ws.WebSocketMessage,
+) {
+	var target ws.SignalTarget
+	if err := json.Unmarshal(msg.Payload, &target); err != nil {
+		fmt.Printf("[WebSocket Group] ERROR: Failed to parse %s: %v\n", msg.Type, err)
+		return
+	}
+
+	to := session.Participant(target.To)
+	if !session.CanSignal(client, to) {
+		fmt.Printf("[WebSocket Group] WARNING: %s from %s to %s not allowed\n", msg.Type, client.UserID, target.To)
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		fmt.Printf("[WebSocket Group] ERROR: Failed to parse %s: %v\n", msg.Type, err)
+		return
+	}
+
+	delete(payload, "to")
+	payload["from"] = client.UserID
+	payload["from_username"] = client.Username
+
+	select {
+	case to.Send <- map[string]interface{}{
+		"type":    msg.Type,
+		"payload": payload,
+	}:
+	default:
+	}
+}
+
+// handleModeration applies a mentor's moderation message
+func (h *WebSocketHandler) handleModeration(session *ws.Session, msg ws.WebSocketMessage) {
+	switch msg.Type {
+	case "set_topology":
+		var p ws.TopologyPayload
+		if err := json.Unmarshal(msg.Payload, &p); err != nil ||
+			(p.Topology != ws.TopologyFanOut && p.Topology != ws.TopologyMesh) {
+			fmt.Println("[WebSocket Group] ERROR: Invalid topology")
+			return
+		}
+
+		session.SetTopology(p.Topology)
+		session.Broadcast(map[string]interface{}{
+			"type":    "topology_changed",
+			"payload": p,
+		})
+
+	case "mute_participant":
+		// Media flows peer to peer, so the attendee's client mutes itself
+		var p ws.ModerationPayload
+		if err := json.Unmarshal(msg.Payload, &p); err != nil {
+			fmt.Println("[WebSocket Group] ERROR: Invalid mute_participant:", err)
+			return
+		}
+
+		attendee := session.Participant(p.UserID)
+		if attendee == nil || attendee.Role == "mentor" {
+			return
+		}
+
+		session.Broadcast(map[string]interface{}{
+			"type":    "participant_muted",
+			"payload": participantOf(attendee),
+		})
+
+	case "remove_participant":
+		var p ws.ModerationPayload
+		if err := json.Unmarshal(msg.Payload, &p); err != nil {
+			fmt.Println("[WebSocket Group] ERROR: Invalid remove_participant:", err)
+			return
+		}
+
+		attendee := session.RemoveAttendee(p.UserID)
+		if attendee == nil {
+			return
+		}
+
+		notice := map[string]interface{}{
+			"type":    "participant_removed",
+			"payload": participantOf(attendee),
+		}
+
+		select {
+		case attendee.Send <- notice:
+		default:
+		}
+		attendee.Close()
+
+		session.Broadcast(notice)
+	}
+}
+
+func participantOf(c *ws.Client) ws.ParticipantPayload {
+	return ws.ParticipantPayload{
+		UserID:   c.UserID,
+		Username: c.Username,
+		Role:     c.Role,
+	}
+}
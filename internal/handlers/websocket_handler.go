@@ -82,8 +82,26 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		ConnectionState: ws.NewConnectionState(),
 	}
 
-	// Add client to hub and create session if needed
-	session := h.hub.AddClient(auth.BookingID, client)
+	// Add client to hub and create session if needed. All seats of a
+	// group session share one room.
+	var session *ws.Session
+	if auth.Group {
+		client.RoomID = auth.RoomID
+		session, err = h.hub.AddGroupClient(auth.RoomID, auth.Capacity, client)
+		if err != nil {
+			fmt.Println("[WebSocket Handler] ERROR: Not admitted to group session:", err)
+			conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()),
+				time.Now().Add(time.Second),
+			)
+			conn.Close()
+			return
+		}
+	} else {
+		client.RoomID = auth.BookingID
+		session = h.hub.AddClient(auth.BookingID, client)
+	}
 
 	fmt.Println("[WebSocket Handler] Client added to session")
 
@@ -92,8 +110,13 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	h.videoSessionService.HandleClientJoined(ctx, client, auth.Username)
 	cancel()
 
-	// Send session_ready when both parties are present
-	h.sendSessionReady(session, client, auth)
+	// Send session_ready when both parties are present; group sessions
+	// get the room's state instead
+	if session.Group {
+		h.sendRoomState(session, client)
+	} else {
+		h.sendSessionReady(session, client, auth)
+	}
 
 	// Start reading and writing goroutines
 	go h.readPump(client, session)
@@ -178,10 +201,10 @@ func (h *WebSocketHandler) readPump(client *ws.Client, session *ws.Session) {
 		fmt.Printf("[WebSocket ReadPump] Cleaning up client %s (role=%s)\n", client.UserID, client.Role)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		h.videoSessionService.HandleClientLeft(ctx, client.BookingID, client.Role)
+		h.videoSessionService.HandleClientLeft(ctx, client)
 		cancel()
 
-		h.hub.RemoveClient(client.BookingID, client.Role)
+		h.hub.RemoveClient(client)
 		client.Conn.Close()
 	}()
 
@@ -212,6 +235,11 @@ func (h *WebSocketHandler) readPump(client *ws.Client, session *ws.Session) {
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 
+		if session.Group && h.handleGroupMessage(client, session, msg) {
+			cancel()
+			continue
+		}
+
 		switch msg.Type {
 		case "offer":
 			h.handleOfferMessage(client, session, msg.Payload, ctx)
@@ -380,6 +408,25 @@ func (h *WebSocketHandler) writePump(client *ws.Client) {
 
 		case <-client.Done:
 			fmt.Printf("[WebSocket WritePump] Client done for %s\n", client.UserID)
+			flushPending(client)
+			client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		}
+	}
+}
+
+// flushPending writes the messages queued for a closing client, such as
+// the notice telling them why they were disconnected
+func flushPending(client *ws.Client) {
+	client.Conn.SetWriteDeadline(time.Now().Add(time.Second))
+
+	for {
+		select {
+		case message := <-client.Send:
+			if err := client.Conn.WriteJSON(message); err != nil {
+				return
+			}
+		default:
 			return
 		}
 	}
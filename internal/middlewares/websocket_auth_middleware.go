@@ -21,6 +21,11 @@ type WebSocketAuthContext struct {
 	Role      string // "mentor" or "user"
 	MentorID  uuid.UUID
 	Booking   *models.Booking
+
+	// Group sessions share one room between all their seats
+	Group    bool
+	RoomID   uuid.UUID // BookingID for one-on-one sessions
+	Capacity int
 }
 
 // WebSocketAuthMiddleware authenticates WebSocket connections
@@ -29,6 +34,7 @@ type WebSocketAuthContext struct {
 func WebSocketAuthMiddleware(
 	jwtSecret string,
 	bookingRepo *repositories.BookingRepository,
+	serviceRepo *repositories.MentorServiceRepository,
 	// Used to load usernames and mentor profiles
 	userRepo *repositories.UserRepository,
 	mentorRepo *repositories.MentorRepository,
@@ -116,6 +122,28 @@ func WebSocketAuthMiddleware(
 
 		fmt.Println("[WebSocket Auth] Role derived from booking:", role)
 
+		service, err := serviceRepo.FindByIDIncludingInactive(ctx, booking.ServiceID)
+		if err != nil {
+			fmt.Println("[WebSocket Auth] ERROR: Failed to load service:", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "booking not found",
+			})
+			return
+		}
+
+		// Only paid seats are admitted to a group session's room
+		roomID := bookingID
+		if service.IsGroup() {
+			if booking.Status != models.BookingStatusConfirmed {
+				fmt.Printf("[WebSocket Auth] ERROR: Booking %s is %s\n", bookingID, booking.Status)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "booking must be confirmed",
+				})
+				return
+			}
+			roomID = booking.GroupRoomID()
+		}
+
 		// Load username from database (NEVER trust client)
 		user, err := userRepo.FindByID(userID)
 		if err != nil {
@@ -144,6 +172,9 @@ func WebSocketAuthMiddleware(
 			Role:      role,
 			MentorID:  booking.MentorID,
 			Booking:   booking,
+			Group:     service.IsGroup(),
+			RoomID:    roomID,
+			Capacity:  service.Capacity,
 		}
 
 		// Store in context for handler to access
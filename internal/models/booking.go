@@ -9,10 +9,22 @@ import (
 type BookingStatus string
 
 const (
-	BookingStatusPending   BookingStatus = "pending"
-	BookingStatusConfirmed BookingStatus = "confirmed"
-	BookingStatusCancelled BookingStatus = "cancelled"
-	BookingStatusCompleted BookingStatus = "completed"
+	// Requested bookings of services that require approval hold their
+	// slot until the mentor approves (-> pending) or declines them
+	BookingStatusRequested BookingStatus = "requested"
+	BookingStatusDeclined  BookingStatus = "declined"
+
+	BookingStatusPending       BookingStatus = "pending"
+	BookingStatusConfirmed     BookingStatus = "confirmed"
+	BookingStatusCancelled     BookingStatus = "cancelled"
+	BookingStatusCompleted     BookingStatus = "completed"
+	BookingStatusPaymentFailed BookingStatus = "payment_failed"
+
+	// Set after the session from its video attendance; see
+	// SessionOutcomeProcessor
+	BookingStatusNoShowMentor BookingStatus = "no_show_mentor"
+	BookingStatusNoShowUser   BookingStatus = "no_show_user"
+	BookingStatusIncomplete   BookingStatus = "incomplete"
 )
 
 type Booking struct {
@@ -22,16 +34,34 @@ type Booking struct {
 	UserID    uuid.UUID `db:"user_id"`
 	ServiceID uuid.UUID `db:"service_id"`
 
-	// Date & time are intentionally split
-	BookingDate time.Time `db:"booking_date"` // DATE only
-	StartTime   time.Time `db:"start_time"`   // TIME only
-	EndTime     time.Time `db:"end_time"`     // TIME only
+	// Absolute instants; render them in the viewer's timezone
+	StartsAt time.Time `db:"starts_at"`
+	EndsAt   time.Time `db:"ends_at"`
 
 	Status BookingStatus `db:"status"`
 
-	PriceCents int    `db:"price_cents"`
+	PriceCents int    `db:"price_cents"` // what the learner pays, after any discount
 	Currency   string `db:"currency"`
 
+	PromoCodeID   *uuid.UUID `db:"promo_code_id"`
+	DiscountCents int        `db:"discount_cents"`
+
+	// Set when the session was paid with a package credit (PriceCents is 0)
+	PackagePurchaseID *uuid.UUID `db:"package_purchase_id"`
+
+	// Set when the booking is one occurrence of a recurring series
+	SeriesID *uuid.UUID `db:"series_id"`
+
+	CancelledAt        *time.Time `db:"cancelled_at"`
+	CancelledBy        *string    `db:"cancelled_by"` // user | mentor | system
+	CancellationReason *string    `db:"cancellation_reason"`
+
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
+
+// GroupRoomID identifies the video room shared by every seat of a group
+// session: the same service starting at the same instant
+func (b *Booking) GroupRoomID() uuid.UUID {
+	return uuid.NewSHA1(b.ServiceID, []byte(b.StartsAt.UTC().Format(time.RFC3339)))
+}
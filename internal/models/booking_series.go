@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const BookingFrequencyWeekly = "weekly"
+
+// BookingSeries groups the occurrences of a recurring booking. Each
+// occurrence is an ordinary booking carrying the series ID; the series
+// is paid with one payment and its bookings are cancelled one by one.
+type BookingSeries struct {
+	ID        uuid.UUID `db:"id"`
+	MentorID  uuid.UUID `db:"mentor_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	ServiceID uuid.UUID `db:"service_id"`
+
+	Frequency     string `db:"frequency"`      // weekly
+	IntervalWeeks int    `db:"interval_weeks"` // 1 = every week, 2 = every other week
+	Occurrences   int    `db:"occurrences"`
+
+	PriceCents int    `db:"price_cents"` // for all occurrences
+	Currency   string `db:"currency"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed is a user's secret calendar subscription. Only the hash of
+// the token is stored; the URL itself is shown once when it is created.
+type CalendarFeed struct {
+	UserID    uuid.UUID `db:"user_id"`
+	TokenHash string    `db:"token_hash"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// BookingCalendarEntry is a booking with what its calendar event shows
+type BookingCalendarEntry struct {
+	BookingID uuid.UUID
+	Status    BookingStatus
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ServiceTitle string
+
+	LearnerUserID uuid.UUID
+	LearnerName   string
+	LearnerEmail  string
+
+	MentorUserID uuid.UUID
+	MentorName   string
+	MentorEmail  string
+}
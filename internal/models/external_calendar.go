@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalCalendar is another calendar of a mentor whose events block
+// their OpenCall availability. It is either subscribed to by URL or an
+// uploaded .ics file kept in ICSData.
+type ExternalCalendar struct {
+	ID       uuid.UUID `db:"id"`
+	MentorID uuid.UUID `db:"mentor_id"`
+	Name     string    `db:"name"`
+
+	SourceURL *string `db:"source_url"`
+	ICSData   *string `db:"ics_data"`
+
+	LastSyncedAt *time.Time `db:"last_synced_at"`
+	LastError    *string    `db:"last_error"`
+	NextSyncAt   time.Time  `db:"next_sync_at"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// ExternalBusyBlock is one busy span read from an external calendar
+type ExternalBusyBlock struct {
+	CalendarID uuid.UUID `db:"calendar_id"`
+	MentorID   uuid.UUID `db:"mentor_id"`
+	StartsAt   time.Time `db:"starts_at"`
+	EndsAt     time.Time `db:"ends_at"`
+}
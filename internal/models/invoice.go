@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invoice is the receipt issued when a payment is captured. Names and
+// amounts are copied at issue time so a receipt never changes afterwards.
+type Invoice struct {
+	ID     uuid.UUID `db:"id"`
+	Number string    `db:"number"` // sequential, e.g. INV-2025-000042
+
+	PaymentID         uuid.UUID  `db:"payment_id"`
+	BookingID         *uuid.UUID `db:"booking_id"`
+	PackagePurchaseID *uuid.UUID `db:"package_purchase_id"`
+
+	UserID       uuid.UUID `db:"user_id"`
+	LearnerName  string    `db:"learner_name"`
+	LearnerEmail string    `db:"learner_email"`
+
+	MentorID       uuid.UUID `db:"mentor_id"`
+	MentorName     string    `db:"mentor_name"`
+	MentorUsername string    `db:"mentor_username"`
+
+	Description string `db:"description"`
+
+	// Prices are tax-inclusive: Subtotal + Tax = Total
+	Currency      string `db:"currency"`
+	DiscountCents int64  `db:"discount_cents"`
+	SubtotalCents int64  `db:"subtotal_cents"`
+	TaxLabel      string `db:"tax_label"`
+	TaxRateBps    int    `db:"tax_rate_bps"`
+	TaxCents      int64  `db:"tax_cents"`
+	TotalCents    int64  `db:"total_cents"`
+
+	IssuedAt time.Time `db:"issued_at"`
+}
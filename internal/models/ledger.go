@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ledger accounts. Every ledger transaction balances: total debits equal
+// total credits.
+const (
+	LedgerAccountGatewayClearing    = "gateway_clearing"    // money collected by the gateway
+	LedgerAccountPlatformCommission = "platform_commission" // platform revenue
+	LedgerAccountMentorPayable      = "mentor_payable"      // owed to the mentor
+)
+
+const (
+	LedgerDebit  = "debit"
+	LedgerCredit = "credit"
+)
+
+const (
+	LedgerKindBookingRevenue = "booking_revenue"
+	LedgerKindPackageRevenue = "package_revenue"
+	LedgerKindRefund         = "refund"
+)
+
+type LedgerTransaction struct {
+	ID uuid.UUID `db:"id"`
+
+	Kind string `db:"kind"` // booking_revenue | package_revenue | refund
+
+	// What caused the transaction, e.g. ("booking", booking ID).
+	// (kind, reference_id) is unique so postings are idempotent.
+	ReferenceType string    `db:"reference_type"`
+	ReferenceID   uuid.UUID `db:"reference_id"`
+
+	MentorID uuid.UUID `db:"mentor_id"`
+	Currency string    `db:"currency"`
+
+	Entries []LedgerEntry `db:"-"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type LedgerEntry struct {
+	ID            uuid.UUID `db:"id"`
+	TransactionID uuid.UUID `db:"transaction_id"`
+
+	Account   string `db:"account"`
+	Direction string `db:"direction"` // debit | credit
+	Amount    int64  `db:"amount"`
+}
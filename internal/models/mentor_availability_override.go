@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AvailabilityOverrideKind string
+
+const (
+	// Removes time from the weekly rules (vacations, blocked dates)
+	AvailabilityOverrideBlock AvailabilityOverrideKind = "block"
+	// Adds one-off hours on top of the weekly rules
+	AvailabilityOverrideExtra AvailabilityOverrideKind = "extra"
+)
+
+// MentorAvailabilityOverride changes availability on the mentor-local
+// dates StartDate..EndDate (inclusive). Without times a block covers the
+// whole day; with times it applies the window on each date, wrapping past
+// midnight like weekly rules do.
+type MentorAvailabilityOverride struct {
+	ID       uuid.UUID
+	MentorID uuid.UUID
+	Kind     AvailabilityOverrideKind
+
+	StartDate time.Time // DATE only
+	EndDate   time.Time // DATE only
+
+	StartTime *time.Time // TIME only, nil for a whole-day block
+	EndTime   *time.Time
+
+	Reason *string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
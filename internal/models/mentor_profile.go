@@ -7,12 +7,19 @@ import (
 )
 
 type MentorProfile struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	UserID    uuid.UUID `json:"user_id" db:"user_id"`
-	Title     string    `json:"title" db:"title"`
-	Bio       string    `json:"bio" db:"bio"`
-	Timezone  string    `json:"timezone" db:"timezone"`
-	IsActive  bool      `json:"is_active" db:"is_active"`
+	ID       uuid.UUID `json:"id" db:"id"`
+	UserID   uuid.UUID `json:"user_id" db:"user_id"`
+	Title    string    `json:"title" db:"title"`
+	Bio      string    `json:"bio" db:"bio"`
+	Timezone string    `json:"timezone" db:"timezone"`
+	IsActive bool      `json:"is_active" db:"is_active"`
+
+	// Booking settings. Zero horizon or cap means no limit.
+	BufferMinutes    int `json:"buffer_minutes" db:"buffer_minutes"`
+	MinNoticeMinutes int `json:"min_notice_minutes" db:"min_notice_minutes"`
+	MaxAdvanceDays   int `json:"max_advance_days" db:"max_advance_days"`
+	DailySessionCap  int `json:"daily_session_cap" db:"daily_session_cap"`
+
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
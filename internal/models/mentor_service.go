@@ -6,6 +6,14 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ServiceTypeOneOnOne = "one_on_one"
+
+	// Group services seat up to Capacity learners in the same slot, each
+	// with their own booking and payment
+	ServiceTypeGroup = "group"
+)
+
 type MentorService struct {
 	ID              uuid.UUID
 	MentorID        uuid.UUID
@@ -14,7 +22,22 @@ type MentorService struct {
 	DurationMinutes int
 	PriceCents      int
 	Currency        string
-	IsActive        bool
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	Type            string
+	Capacity        int // seats per session; 1 for one-on-one services
+
+	// Bookings of the service wait for the mentor to approve them
+	RequiresApproval bool
+
+	IsActive  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (s *MentorService) IsGroup() bool {
+	return s.Type == ServiceTypeGroup
+}
+
+// IsFree services are booked without any payment
+func (s *MentorService) IsFree() bool {
+	return s.PriceCents == 0
 }
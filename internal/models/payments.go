@@ -6,11 +6,25 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	PaymentStatusCreated           = "created"
+	PaymentStatusPaid              = "paid"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusExpired           = "expired"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+)
+
 type Payment struct {
 	ID uuid.UUID `db:"id"`
 
-	BookingID uuid.UUID `db:"booking_id"`
-	UserID    uuid.UUID `db:"user_id"`
+	// Exactly one of BookingID, PackagePurchaseID and SeriesID is set: a
+	// payment is for a single booking, a prepaid package or every
+	// occurrence of a recurring series
+	BookingID         *uuid.UUID `db:"booking_id"`
+	PackagePurchaseID *uuid.UUID `db:"package_purchase_id"`
+	SeriesID          *uuid.UUID `db:"series_id"`
+	UserID            uuid.UUID  `db:"user_id"`
 
 	Gateway string `db:"gateway"`
 
@@ -21,7 +35,10 @@ type Payment struct {
 	Amount   int64  `db:"amount"`
 	Currency string `db:"currency"`
 
-	Status string `db:"status"` // created | paid | failed
+	// Promo discount already taken off Amount, kept for receipts
+	DiscountCents int64 `db:"discount_cents"`
+
+	Status string `db:"status"` // created | paid | failed | expired | refunded | partially_refunded
 
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
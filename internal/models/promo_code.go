@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromoDiscountType string
+
+const (
+	PromoDiscountPercent PromoDiscountType = "percent"
+	PromoDiscountFixed   PromoDiscountType = "fixed"
+)
+
+type PromoCode struct {
+	ID uuid.UUID `db:"id"`
+
+	Code string `db:"code"` // stored upper-case, unique
+
+	// nil for platform-wide codes, otherwise the mentor profile the
+	// code is limited to
+	MentorID *uuid.UUID `db:"mentor_id"`
+
+	DiscountType   PromoDiscountType `db:"discount_type"`
+	PercentOff     int               `db:"percent_off"`      // percent codes, 1-100
+	AmountOffCents int64             `db:"amount_off_cents"` // fixed codes
+	Currency       *string           `db:"currency"`         // fixed codes only
+
+	// nil means unlimited
+	MaxRedemptions *int `db:"max_redemptions"`
+	MaxPerUser     *int `db:"max_per_user"`
+
+	// Active (not released) redemptions
+	RedemptionCount int `db:"redemption_count"`
+
+	StartsAt  *time.Time `db:"starts_at"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	IsActive  bool       `db:"is_active"`
+
+	CreatedBy uuid.UUID `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// PromoRedemption ties a code to the booking it was used on. Cancelling
+// or expiring the booking releases the redemption and frees up the use.
+type PromoRedemption struct {
+	ID          uuid.UUID `db:"id"`
+	PromoCodeID uuid.UUID `db:"promo_code_id"`
+	BookingID   uuid.UUID `db:"booking_id"`
+	UserID      uuid.UUID `db:"user_id"`
+
+	DiscountCents int64 `db:"discount_cents"`
+
+	ReleasedAt *time.Time `db:"released_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusProcessed RefundStatus = "processed"
+	RefundStatusFailed    RefundStatus = "failed"
+)
+
+type Refund struct {
+	ID uuid.UUID `db:"id"`
+
+	PaymentID uuid.UUID  `db:"payment_id"`
+	BookingID *uuid.UUID `db:"booking_id"` // nil for package payments
+
+	GatewayRefundID *string `db:"gateway_refund_id"`
+
+	Amount   int64  `db:"amount"`
+	Currency string `db:"currency"`
+
+	Status RefundStatus `db:"status"`
+	Reason string       `db:"reason"`
+
+	// Last error reported by the gateway, if any
+	FailureReason *string `db:"failure_reason"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
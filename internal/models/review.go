@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review is a learner's rating of a completed session, one per booking
+type Review struct {
+	ID        uuid.UUID `db:"id"`
+	BookingID uuid.UUID `db:"booking_id"`
+	MentorID  uuid.UUID `db:"mentor_id"`
+	UserID    uuid.UUID `db:"user_id"`
+
+	Rating  int    `db:"rating"` // 1-5
+	Comment string `db:"comment"`
+
+	// Reviewer's username, joined in when listing
+	Username string `db:"-"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
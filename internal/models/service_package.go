@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServicePackage is a prepaid bundle of sessions of one MentorService,
+// e.g. "5 sessions for the price of 4"
+type ServicePackage struct {
+	ID        uuid.UUID `db:"id"`
+	MentorID  uuid.UUID `db:"mentor_id"`
+	ServiceID uuid.UUID `db:"service_id"`
+
+	Title        string `db:"title"`
+	SessionCount int    `db:"session_count"`
+
+	PriceCents int    `db:"price_cents"` // for the whole package
+	Currency   string `db:"currency"`
+
+	// Credits expire this many days after purchase; nil = never
+	ValidityDays *int `db:"validity_days"`
+
+	IsActive  bool      `db:"is_active"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type PackagePurchaseStatus string
+
+const (
+	PackagePurchasePending PackagePurchaseStatus = "pending" // awaiting payment
+	PackagePurchaseActive  PackagePurchaseStatus = "active"  // paid, credits usable
+	PackagePurchaseFailed  PackagePurchaseStatus = "failed"  // payment failed
+)
+
+// PackagePurchase is a learner's copy of a package and its remaining credits
+type PackagePurchase struct {
+	ID        uuid.UUID `db:"id"`
+	PackageID uuid.UUID `db:"package_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	MentorID  uuid.UUID `db:"mentor_id"`
+	ServiceID uuid.UUID `db:"service_id"`
+
+	CreditsTotal     int `db:"credits_total"`
+	CreditsRemaining int `db:"credits_remaining"`
+
+	PriceCents int    `db:"price_cents"`
+	Currency   string `db:"currency"`
+
+	Status PackagePurchaseStatus `db:"status"`
+
+	ActivatedAt *time.Time `db:"activated_at"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SlotHold reserves a slot for one learner for a few minutes while they
+// check out. Other learners see the slot as taken until the hold is
+// consumed by a booking, released or expires.
+type SlotHold struct {
+	Token     string    `db:"token"`
+	MentorID  uuid.UUID `db:"mentor_id"`
+	ServiceID uuid.UUID `db:"service_id"`
+	UserID    uuid.UUID `db:"user_id"`
+
+	StartsAt time.Time `db:"starts_at"`
+	EndsAt   time.Time `db:"ends_at"`
+
+	ExpiresAt time.Time `db:"expires_at"`
+}
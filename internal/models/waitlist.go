@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusFulfilled = "fulfilled" // accepted an offer
+	WaitlistStatusCancelled = "cancelled"
+)
+
+const (
+	WaitlistOfferOpen      = "open"
+	WaitlistOfferAccepted  = "accepted"
+	WaitlistOfferExpired   = "expired"
+	WaitlistOfferTaken     = "taken"     // another learner got the slot first
+	WaitlistOfferWithdrawn = "withdrawn" // the entry was fulfilled or cancelled
+)
+
+// WaitlistEntry puts a learner in line for a mentor's slots. Entries are
+// served in the order they were created.
+type WaitlistEntry struct {
+	ID       uuid.UUID `db:"id"`
+	MentorID uuid.UUID `db:"mentor_id"`
+	UserID   uuid.UUID `db:"user_id"`
+
+	// Nil means any of the mentor's one-on-one services
+	ServiceID *uuid.UUID `db:"service_id"`
+
+	// Preferences, read in Timezone. Empty means any day or time.
+	PreferredDays []int64 `db:"preferred_days"` // 0 = Sunday
+	PreferredFrom string  `db:"preferred_from"` // HH:MM
+	PreferredTo   string  `db:"preferred_to"`   // HH:MM
+	Timezone      string  `db:"timezone"`
+
+	Status string `db:"status"`
+
+	// Place in the mentor's line among waiting entries; set when listing
+	Position int `db:"-"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// WaitlistOffer tells a waiting learner about a slot that opened. The
+// first learner to accept an offer for the slot gets a slot hold on it.
+type WaitlistOffer struct {
+	ID        uuid.UUID `db:"id"`
+	EntryID   uuid.UUID `db:"entry_id"`
+	ServiceID uuid.UUID `db:"service_id"`
+
+	StartsAt time.Time `db:"starts_at"`
+	EndsAt   time.Time `db:"ends_at"`
+
+	Status    string    `db:"status"`
+	ExpiresAt time.Time `db:"expires_at"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
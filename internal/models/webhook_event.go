@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEventStatus string
+
+const (
+	WebhookEventStatusPending    WebhookEventStatus = "pending"
+	WebhookEventStatusProcessing WebhookEventStatus = "processing"
+	WebhookEventStatusProcessed  WebhookEventStatus = "processed"
+	WebhookEventStatusFailed     WebhookEventStatus = "failed"
+)
+
+// WebhookEvent is a verified gateway webhook waiting in (or done with)
+// the inbox. (Gateway, EventID) is unique, so redeliveries are dropped.
+type WebhookEvent struct {
+	ID uuid.UUID `db:"id"`
+
+	Gateway   string `db:"gateway"`
+	EventID   string `db:"event_id"` // gateway's event ID, or a hash of the payload
+	EventType string `db:"event_type"`
+
+	// Raw body as received, kept for auditing
+	Payload []byte `db:"payload"`
+	// The parsed, gateway-neutral event the processor applies
+	Event []byte `db:"event"`
+
+	Status        WebhookEventStatus `db:"status"`
+	Attempts      int                `db:"attempts"`
+	LastError     *string            `db:"last_error"`
+	NextAttemptAt time.Time          `db:"next_attempt_at"`
+
+	ReceivedAt  time.Time  `db:"received_at"`
+	ProcessedAt *time.Time `db:"processed_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
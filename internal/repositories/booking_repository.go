@@ -7,35 +7,44 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
 	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
 )
 
 type BookingRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	holds SlotHoldStore
 }
 
-func NewBookingRepository(db *sql.DB) *BookingRepository {
-	return &BookingRepository{db: db}
+func NewBookingRepository(db *sql.DB, holds SlotHoldStore) *BookingRepository {
+	return &BookingRepository{db: db, holds: holds}
 }
 
-func (r *BookingRepository) FindForMentorOnDate(
+// FindForMentorBetween returns the mentor's active bookings overlapping [from, to)
+func (r *BookingRepository) FindForMentorBetween(
 	mentorID uuid.UUID,
-	date time.Time,
+	from time.Time,
+	to time.Time,
 ) ([]*models.Booking, error) {
 
 	const query = `
 	SELECT
 		id,
-		start_time,
-		end_time
+		service_id,
+		starts_at,
+		ends_at,
+		status
 	FROM bookings
 	WHERE mentor_id = $1
-	  AND booking_date = $2
-	  AND status IN ('pending', 'confirmed')
+	  AND starts_at < $3
+	  AND ends_at > $2
+	  AND status IN ('requested', 'pending', 'confirmed')
+	ORDER BY starts_at
 	`
 
-	rows, err := r.db.Query(query, mentorID, date)
+	rows, err := r.db.Query(query, mentorID, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -48,8 +57,10 @@ func (r *BookingRepository) FindForMentorOnDate(
 
 		if err := rows.Scan(
 			&b.ID,
-			&b.StartTime,
-			&b.EndTime,
+			&b.ServiceID,
+			&b.StartsAt,
+			&b.EndsAt,
+			&b.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -60,11 +71,112 @@ func (r *BookingRepository) FindForMentorOnDate(
 	return bookings, nil
 }
 
+// CountForMentorBetweenTx counts the mentor's sessions starting in
+// [from, to), leaving out excludeBookingID. All seats of a group session
+// count as one session.
+func (r *BookingRepository) CountForMentorBetweenTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+	excludeBookingID uuid.UUID,
+	from time.Time,
+	to time.Time,
+) (int, error) {
+
+	const query = `
+	SELECT COUNT(DISTINCT (service_id, starts_at))
+	FROM bookings
+	WHERE mentor_id = $1
+	  AND status IN ('requested','pending','confirmed')
+	  AND starts_at >= $2
+	  AND starts_at < $3
+	  AND id <> $4
+	`
+
+	var count int
+	err := tx.QueryRowContext(ctx, query, mentorID, from, to, excludeBookingID).Scan(&count)
+
+	return count, err
+}
+
+// HasConflictTx reports whether a booking, an external busy block or a
+// slot hold overlaps [start, end). Slot holds live outside tx, so the
+// caller must hold the mentor's lock (see MentorRepository.LockTx) to
+// keep a hold from being placed between this check and the commit.
 func (r *BookingRepository) HasConflictTx(
 	ctx context.Context,
 	tx *sql.Tx,
 	mentorID uuid.UUID,
-	bookingDate time.Time,
+	excludeBookingID uuid.UUID,
+	excludeHoldToken string,
+	start time.Time,
+	end time.Time,
+) (bool, error) {
+
+	// excludeBookingID lets a reschedule ignore the booking being moved;
+	// pass uuid.Nil when creating a new booking. excludeHoldToken is the
+	// caller's own slot hold, if any.
+	return r.hasConflictTx(ctx, tx, mentorID, excludeBookingID, excludeHoldToken, uuid.Nil, time.Time{}, start, end)
+}
+
+// HasGroupConflictTx is HasConflictTx for a new seat in the group session
+// of serviceID starting at sessionStart: the session's other seats don't
+// conflict with it
+func (r *BookingRepository) HasGroupConflictTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+	serviceID uuid.UUID,
+	sessionStart time.Time,
+	start time.Time,
+	end time.Time,
+) (bool, error) {
+
+	return r.hasConflictTx(ctx, tx, mentorID, uuid.Nil, "", serviceID, sessionStart, start, end)
+}
+
+func (r *BookingRepository) hasConflictTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+	excludeBookingID uuid.UUID,
+	excludeHoldToken string,
+	groupServiceID uuid.UUID,
+	groupStart time.Time,
+	start time.Time,
+	end time.Time,
+) (bool, error) {
+
+	booked, err := r.isBookedTx(ctx, tx, mentorID, excludeBookingID, groupServiceID, groupStart, start, end)
+	if err != nil || booked {
+		return booked, err
+	}
+
+	return r.isHeld(ctx, mentorID, excludeHoldToken, start, end)
+}
+
+// IsBookedTx is HasConflictTx without slot holds, for placing a hold:
+// the hold store checks the new hold against the others itself
+func (r *BookingRepository) IsBookedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+	start time.Time,
+	end time.Time,
+) (bool, error) {
+
+	return r.isBookedTx(ctx, tx, mentorID, uuid.Nil, uuid.Nil, time.Time{}, start, end)
+}
+
+// isBookedTx reports whether a live booking or an external busy block
+// overlaps [start, end)
+func (r *BookingRepository) isBookedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+	excludeBookingID uuid.UUID,
+	groupServiceID uuid.UUID,
+	groupStart time.Time,
 	start time.Time,
 	end time.Time,
 ) (bool, error) {
@@ -73,10 +185,11 @@ func (r *BookingRepository) HasConflictTx(
 	SELECT 1
 	FROM bookings
 	WHERE mentor_id = $1
-	  AND booking_date = $2
-	  AND status IN ('pending','confirmed')
-	  AND start_time < $4
-	  AND end_time > $3
+	  AND status IN ('requested','pending','confirmed')
+	  AND starts_at < $3
+	  AND ends_at > $2
+	  AND id <> $4
+	  AND NOT (service_id = $5 AND starts_at = $6)
 	FOR UPDATE
 	LIMIT 1
 	`
@@ -85,16 +198,18 @@ func (r *BookingRepository) HasConflictTx(
 		ctx,
 		query,
 		mentorID,
-		bookingDate,
 		start,
 		end,
+		excludeBookingID,
+		groupServiceID,
+		groupStart,
 	)
 
 	var dummy int
 	err := row.Scan(&dummy)
 
 	if err == sql.ErrNoRows {
-		return false, nil
+		return r.hasExternalBusyTx(ctx, tx, mentorID, start, end)
 	}
 
 	if err != nil {
@@ -104,6 +219,112 @@ func (r *BookingRepository) HasConflictTx(
 	return true, nil
 }
 
+// CountFreeForUserTx counts the learner's free (zero-price, not paid
+// with a package credit or a promo code) bookings with the mentor that
+// weren't cancelled, declined or missed by the mentor
+func (r *BookingRepository) CountFreeForUserTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+	userID uuid.UUID,
+) (int, error) {
+
+	const query = `
+	SELECT COUNT(*)
+	FROM bookings
+	WHERE mentor_id = $1
+	  AND user_id = $2
+	  AND price_cents = 0
+	  AND package_purchase_id IS NULL
+	  AND promo_code_id IS NULL
+	  AND status IN ('requested','pending','confirmed','completed','no_show_user','incomplete')
+	`
+
+	var count int
+	err := tx.QueryRowContext(ctx, query, mentorID, userID).Scan(&count)
+
+	return count, err
+}
+
+// GroupSeatsTx counts the taken seats of the group session of serviceID
+// starting at start, and tells whether userID holds one of them
+func (r *BookingRepository) GroupSeatsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	serviceID uuid.UUID,
+	start time.Time,
+	userID uuid.UUID,
+) (int, bool, error) {
+
+	const query = `
+	SELECT
+		COUNT(*),
+		COALESCE(BOOL_OR(user_id = $3), false)
+	FROM bookings
+	WHERE service_id = $1
+	  AND starts_at = $2
+	  AND status IN ('requested','pending','confirmed')
+	`
+
+	var (
+		taken int
+		mine  bool
+	)
+	err := tx.QueryRowContext(ctx, query, serviceID, start, userID).Scan(&taken, &mine)
+
+	return taken, mine, err
+}
+
+// isHeld reports whether another learner's slot hold covers any of
+// [start, end)
+func (r *BookingRepository) isHeld(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	excludeHoldToken string,
+	start time.Time,
+	end time.Time,
+) (bool, error) {
+
+	holds, err := r.holds.FindBetween(ctx, mentorID, start, end)
+	if err != nil {
+		return false, err
+	}
+
+	for _, h := range holds {
+		if h.Token != excludeHoldToken {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// hasExternalBusyTx reports whether the mentor's imported calendars mark
+// any of [start, end) as busy
+func (r *BookingRepository) hasExternalBusyTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+	start time.Time,
+	end time.Time,
+) (bool, error) {
+
+	const query = `
+	SELECT EXISTS (
+		SELECT 1
+		FROM external_busy_blocks
+		WHERE mentor_id = $1
+		  AND starts_at < $3
+		  AND ends_at > $2
+	)
+	`
+
+	var busy bool
+	err := tx.QueryRowContext(ctx, query, mentorID, start, end).Scan(&busy)
+
+	return busy, err
+}
+
 func (r *BookingRepository) CreateTx(
 	ctx context.Context,
 	tx *sql.Tx,
@@ -116,16 +337,19 @@ func (r *BookingRepository) CreateTx(
 		mentor_id,
 		user_id,
 		service_id,
-		booking_date,
-		start_time,
-		end_time,
+		starts_at,
+		ends_at,
 		status,
 		price_cents,
 		currency,
+		promo_code_id,
+		discount_cents,
+		package_purchase_id,
+		series_id,
 		created_at,
 		updated_at
 	)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,NOW(),NOW())
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NOW(),NOW())
 	`
 
 	_, err := tx.ExecContext(
@@ -135,17 +359,44 @@ func (r *BookingRepository) CreateTx(
 		b.MentorID,
 		b.UserID,
 		b.ServiceID,
-		b.BookingDate,
-		b.StartTime,
-		b.EndTime,
+		b.StartsAt,
+		b.EndsAt,
 		b.Status,
 		b.PriceCents,
 		b.Currency,
+		b.PromoCodeID,
+		b.DiscountCents,
+		b.PackagePurchaseID,
+		b.SeriesID,
 	)
 
 	return err
 }
 
+// WithReadCommittedTx is WithTx at read committed, for work that starts
+// by waiting on a lock: each statement sees what the lock's previous
+// holder committed, which a serializable snapshot taken before the wait
+// would not
+func (r *BookingRepository) WithReadCommittedTx(
+	ctx context.Context,
+	fn func(tx *sql.Tx) error,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *BookingRepository) WithTx(
 	ctx context.Context,
 	fn func(tx *sql.Tx) error,
@@ -175,9 +426,8 @@ func (r *BookingRepository) GetByUserID(
 		b.id,
 		u.username AS mentor_username,
 		s.title AS service_title,
-		b.booking_date,
-		b.start_time,
-		b.end_time,
+		b.starts_at,
+		b.ends_at,
 		b.status,
 		b.price_cents,
 		b.currency
@@ -186,7 +436,7 @@ func (r *BookingRepository) GetByUserID(
 	JOIN users u ON u.id = mp.user_id
 	JOIN mentor_services s ON s.id = b.service_id
 	WHERE b.user_id = $1
-	ORDER BY b.booking_date DESC, b.start_time DESC
+	ORDER BY b.starts_at DESC
 	`
 
 	rows, err := r.db.Query(query, userID)
@@ -199,17 +449,13 @@ func (r *BookingRepository) GetByUserID(
 
 	for rows.Next() {
 		var r dtos.MyBookingResponse
-		var date time.Time
-		var start time.Time
-		var end time.Time
 
 		err := rows.Scan(
 			&r.ID,
 			&r.Mentor,
 			&r.Service,
-			&date,
-			&start,
-			&end,
+			&r.StartsAt,
+			&r.EndsAt,
 			&r.Status,
 			&r.Price,
 			&r.Currency,
@@ -218,9 +464,7 @@ func (r *BookingRepository) GetByUserID(
 			return nil, err
 		}
 
-		r.Date = date.Format("2006-01-02")
-		r.StartTime = start.Format("15:04")
-		r.EndTime = end.Format("15:04")
+		r.FormattedPrice = utils.FormatMoney(int64(r.Price), r.Currency)
 
 		result = append(result, &r)
 	}
@@ -239,12 +483,18 @@ func (r *BookingRepository) GetByID(
 		mentor_id,
 		user_id,
 		service_id,
-		booking_date,
-		start_time,
-		end_time,
+		starts_at,
+		ends_at,
 		status,
 		price_cents,
 		currency,
+		promo_code_id,
+		discount_cents,
+		package_purchase_id,
+		series_id,
+		cancelled_at,
+		cancelled_by,
+		cancellation_reason,
 		created_at,
 		updated_at
 	FROM bookings
@@ -259,12 +509,18 @@ func (r *BookingRepository) GetByID(
 		&b.MentorID,
 		&b.UserID,
 		&b.ServiceID,
-		&b.BookingDate,
-		&b.StartTime,
-		&b.EndTime,
+		&b.StartsAt,
+		&b.EndsAt,
 		&b.Status,
 		&b.PriceCents,
 		&b.Currency,
+		&b.PromoCodeID,
+		&b.DiscountCents,
+		&b.PackagePurchaseID,
+		&b.SeriesID,
+		&b.CancelledAt,
+		&b.CancelledBy,
+		&b.CancellationReason,
 		&b.CreatedAt,
 		&b.UpdatedAt,
 	)
@@ -318,29 +574,81 @@ func (r *BookingRepository) MarkConfirmed(
 	return nil
 }
 
+// ReviveTx confirms a booking that lost its slot for want of payment -
+// its payment failed or it expired unpaid - once a late capture arrives
+func (r *BookingRepository) ReviveTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+) error {
+
+	const query = `
+	UPDATE bookings
+	SET
+		status = 'confirmed',
+		cancelled_at = NULL,
+		cancelled_by = NULL,
+		cancellation_reason = NULL,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND (
+		status = 'payment_failed'
+		OR (status = 'cancelled' AND cancellation_reason = 'payment_timeout')
+	  )
+	`
+
+	result, err := tx.ExecContext(ctx, query, bookingID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "booking can't be revived")
+}
+
 func (r *BookingRepository) GetByMentorIDConfirmed(
 	mentorID uuid.UUID,
 ) ([]*dtos.MentorBookedSessionResponse, error) {
 
-	const query = `
+	return r.findMentorSessions(mentorID, models.BookingStatusConfirmed)
+}
+
+// GetByMentorIDRequested lists the requests waiting for the mentor's
+// approval, soonest first
+func (r *BookingRepository) GetByMentorIDRequested(
+	mentorID uuid.UUID,
+) ([]*dtos.MentorBookedSessionResponse, error) {
+
+	return r.findMentorSessions(mentorID, models.BookingStatusRequested)
+}
+
+func (r *BookingRepository) findMentorSessions(
+	mentorID uuid.UUID,
+	status models.BookingStatus,
+) ([]*dtos.MentorBookedSessionResponse, error) {
+
+	// Requests are worked through soonest first, sessions shown latest first
+	order := "DESC"
+	if status == models.BookingStatusRequested {
+		order = "ASC"
+	}
+
+	query := `
 	SELECT
 		b.id,
 		u.username AS user_username,
 		s.title AS service_title,
-		b.booking_date,
-		b.start_time,
-		b.end_time,
+		b.starts_at,
+		b.ends_at,
 		b.price_cents,
 		b.currency
 	FROM bookings b
 	JOIN users u ON u.id = b.user_id
 	JOIN mentor_services s ON s.id = b.service_id
 	WHERE b.mentor_id = $1
-	  AND b.status = 'confirmed'
-	ORDER BY b.booking_date DESC, b.start_time DESC
-	`
+	  AND b.status = $2
+	ORDER BY b.starts_at ` + order
 
-	rows, err := r.db.Query(query, mentorID)
+	rows, err := r.db.Query(query, mentorID, status)
 	if err != nil {
 		return nil, err
 	}
@@ -350,17 +658,13 @@ func (r *BookingRepository) GetByMentorIDConfirmed(
 
 	for rows.Next() {
 		var resp dtos.MentorBookedSessionResponse
-		var date time.Time
-		var start time.Time
-		var end time.Time
 
 		err := rows.Scan(
 			&resp.ID,
 			&resp.UserUsername,
 			&resp.ServiceTitle,
-			&date,
-			&start,
-			&end,
+			&resp.StartsAt,
+			&resp.EndsAt,
 			&resp.PriceCents,
 			&resp.Currency,
 		)
@@ -368,9 +672,7 @@ func (r *BookingRepository) GetByMentorIDConfirmed(
 			return nil, err
 		}
 
-		resp.BookingDate = date.Format("2006-01-02")
-		resp.StartTime = start.Format("15:04")
-		resp.EndTime = end.Format("15:04")
+		resp.FormattedPrice = utils.FormatMoney(int64(resp.PriceCents), resp.Currency)
 
 		result = append(result, &resp)
 	}
@@ -392,3 +694,440 @@ func (r *BookingRepository) UpdateStatus(
 	_, err := r.db.ExecContext(ctx, query, status, bookingID)
 	return err
 }
+
+// GetByIDForUpdateTx loads a booking and locks its row until the transaction ends
+func (r *BookingRepository) GetByIDForUpdateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	id uuid.UUID,
+) (*models.Booking, error) {
+
+	const query = `
+	SELECT
+		id,
+		mentor_id,
+		user_id,
+		service_id,
+		starts_at,
+		ends_at,
+		status,
+		price_cents,
+		currency,
+		promo_code_id,
+		discount_cents,
+		package_purchase_id,
+		series_id,
+		cancelled_at,
+		cancelled_by,
+		cancellation_reason,
+		created_at,
+		updated_at
+	FROM bookings
+	WHERE id = $1
+	FOR UPDATE
+	`
+
+	var b models.Booking
+
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&b.ID,
+		&b.MentorID,
+		&b.UserID,
+		&b.ServiceID,
+		&b.StartsAt,
+		&b.EndsAt,
+		&b.Status,
+		&b.PriceCents,
+		&b.Currency,
+		&b.PromoCodeID,
+		&b.DiscountCents,
+		&b.PackagePurchaseID,
+		&b.SeriesID,
+		&b.CancelledAt,
+		&b.CancelledBy,
+		&b.CancellationReason,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("booking not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+func (r *BookingRepository) CancelTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+	cancelledBy string,
+	reason string,
+) error {
+
+	const query = `
+	UPDATE bookings
+	SET
+		status = $2,
+		cancelled_at = NOW(),
+		cancelled_by = $3,
+		cancellation_reason = NULLIF($4, ''),
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status IN ('requested', 'pending', 'confirmed')
+	`
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		bookingID,
+		models.BookingStatusCancelled,
+		cancelledBy,
+		reason,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("booking cannot be cancelled")
+	}
+
+	return nil
+}
+
+func (r *BookingRepository) RescheduleTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+	start time.Time,
+	end time.Time,
+) error {
+
+	const query = `
+	UPDATE bookings
+	SET
+		starts_at = $2,
+		ends_at = $3,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status IN ('requested', 'pending', 'confirmed')
+	`
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		bookingID,
+		start,
+		end,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("booking cannot be rescheduled")
+	}
+
+	return nil
+}
+
+// FindUnsettled returns up to `limit` confirmed bookings that ended in
+// [from, to), oldest first
+func (r *BookingRepository) FindUnsettled(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	limit int,
+) ([]*models.Booking, error) {
+
+	const query = `
+	SELECT
+		id,
+		mentor_id,
+		user_id,
+		service_id,
+		starts_at,
+		ends_at,
+		status,
+		price_cents,
+		currency,
+		package_purchase_id
+	FROM bookings
+	WHERE status = 'confirmed'
+	  AND ends_at >= $1
+	  AND ends_at < $2
+	ORDER BY ends_at
+	LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+
+	for rows.Next() {
+		var b models.Booking
+
+		if err := rows.Scan(
+			&b.ID,
+			&b.MentorID,
+			&b.UserID,
+			&b.ServiceID,
+			&b.StartsAt,
+			&b.EndsAt,
+			&b.Status,
+			&b.PriceCents,
+			&b.Currency,
+			&b.PackagePurchaseID,
+		); err != nil {
+			return nil, err
+		}
+
+		bookings = append(bookings, &b)
+	}
+
+	return bookings, rows.Err()
+}
+
+// SettleTx records how a confirmed booking's session went: completed or
+// a no-show
+func (r *BookingRepository) SettleTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+	status models.BookingStatus,
+) error {
+
+	const query = `
+	UPDATE bookings
+	SET
+		status = $2,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status = 'confirmed'
+	`
+
+	result, err := tx.ExecContext(ctx, query, bookingID, status)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "booking already settled")
+}
+
+// ApproveTx moves a requested booking on to `status`: pending payment, or
+// confirmed when there is nothing to pay
+func (r *BookingRepository) ApproveTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+	status models.BookingStatus,
+) error {
+
+	const query = `
+	UPDATE bookings
+	SET
+		status = $2,
+		approved_at = NOW(),
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status = 'requested'
+	`
+
+	result, err := tx.ExecContext(ctx, query, bookingID, status)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "booking is not awaiting approval")
+}
+
+// CloseRequestTx ends a requested booking with `status` - declined by the
+// mentor, or cancelled when the request expires - and frees its slot
+func (r *BookingRepository) CloseRequestTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+	status models.BookingStatus,
+	closedBy string,
+	reason string,
+) error {
+
+	const query = `
+	UPDATE bookings
+	SET
+		status = $2,
+		cancelled_at = NOW(),
+		cancelled_by = $3,
+		cancellation_reason = NULLIF($4, ''),
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status = 'requested'
+	`
+
+	result, err := tx.ExecContext(ctx, query, bookingID, status, closedBy, reason)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "booking is not awaiting approval")
+}
+
+// FindStaleRequested returns up to `limit` requests left unanswered for
+// longer than `ttl`, or whose session has already started
+func (r *BookingRepository) FindStaleRequested(
+	ctx context.Context,
+	ttl time.Duration,
+	limit int,
+) ([]uuid.UUID, error) {
+
+	const query = `
+	SELECT id
+	FROM bookings
+	WHERE status = 'requested'
+	  AND (
+		created_at < NOW() - make_interval(secs => $1)
+		OR starts_at <= NOW()
+	  )
+	ORDER BY created_at
+	LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ttl.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ExpireStalePending cancels up to `limit` pending bookings older than
+// `hold` (counted from approval for approved requests) that never got a
+// captured payment, releasing their slots and promo code redemptions,
+// and expires their open payments. It returns the expired bookings with
+// their IDs and mentors. SKIP LOCKED lets several server instances run
+// this at once without blocking on or double-processing rows.
+func (r *BookingRepository) ExpireStalePending(
+	ctx context.Context,
+	hold time.Duration,
+	limit int,
+) ([]*models.Booking, error) {
+
+	const expireBookings = `
+	WITH stale AS (
+		SELECT b.id
+		FROM bookings b
+		WHERE b.status = 'pending'
+		  AND COALESCE(b.approved_at, b.created_at) < NOW() - make_interval(secs => $1)
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM payments p
+			WHERE (p.booking_id = b.id OR p.series_id = b.series_id)
+			  AND p.status IN ('paid', 'partially_refunded', 'refunded')
+		  )
+		ORDER BY b.created_at
+		LIMIT $2
+		FOR UPDATE OF b SKIP LOCKED
+	)
+	UPDATE bookings b
+	SET
+		status = 'cancelled',
+		cancelled_at = NOW(),
+		cancelled_by = 'system',
+		cancellation_reason = 'payment_timeout',
+		updated_at = NOW()
+	FROM stale
+	WHERE b.id = stale.id
+	RETURNING b.id, b.mentor_id
+	`
+
+	const expirePayments = `
+	UPDATE payments
+	SET
+		status = 'expired',
+		updated_at = NOW()
+	WHERE (
+		booking_id = ANY($1::uuid[])
+		OR series_id IN (SELECT series_id FROM bookings WHERE id = ANY($1::uuid[]))
+	)
+	  AND status = 'created'
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, expireBookings, hold.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		expired []*models.Booking
+		idStrs  []string
+	)
+
+	for rows.Next() {
+		var b models.Booking
+		if err := rows.Scan(&b.ID, &b.MentorID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		expired = append(expired, &b)
+		idStrs = append(idStrs, b.ID.String())
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, expirePayments, pq.Array(idStrs)); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, releasePromoRedemptions, pq.Array(idStrs)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,281 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type BookingSeriesRepository struct {
+	db *sql.DB
+}
+
+func NewBookingSeriesRepository(db *sql.DB) *BookingSeriesRepository {
+	return &BookingSeriesRepository{db: db}
+}
+
+func (r *BookingSeriesRepository) CreateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	series *models.BookingSeries,
+) error {
+
+	const query = `
+	INSERT INTO booking_series (
+		id,
+		mentor_id,
+		user_id,
+		service_id,
+		frequency,
+		interval_weeks,
+		occurrences,
+		price_cents,
+		currency,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NOW(),NOW())
+	RETURNING created_at, updated_at
+	`
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		series.ID,
+		series.MentorID,
+		series.UserID,
+		series.ServiceID,
+		series.Frequency,
+		series.IntervalWeeks,
+		series.Occurrences,
+		series.PriceCents,
+		series.Currency,
+	).Scan(&series.CreatedAt, &series.UpdatedAt)
+}
+
+func (r *BookingSeriesRepository) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*models.BookingSeries, error) {
+
+	const query = `
+	SELECT
+		id,
+		mentor_id,
+		user_id,
+		service_id,
+		frequency,
+		interval_weeks,
+		occurrences,
+		price_cents,
+		currency,
+		created_at,
+		updated_at
+	FROM booking_series
+	WHERE id = $1
+	`
+
+	var s models.BookingSeries
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&s.ID,
+		&s.MentorID,
+		&s.UserID,
+		&s.ServiceID,
+		&s.Frequency,
+		&s.IntervalWeeks,
+		&s.Occurrences,
+		&s.PriceCents,
+		&s.Currency,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("booking series not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// ListBookings returns every occurrence of the series in date order,
+// whatever its status
+func (r *BookingSeriesRepository) ListBookings(
+	ctx context.Context,
+	seriesID uuid.UUID,
+) ([]*models.Booking, error) {
+
+	const query = `
+	SELECT
+		id,
+		mentor_id,
+		user_id,
+		service_id,
+		starts_at,
+		ends_at,
+		status,
+		price_cents,
+		currency,
+		series_id,
+		cancellation_reason
+	FROM bookings
+	WHERE series_id = $1
+	ORDER BY starts_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+
+	for rows.Next() {
+		var b models.Booking
+
+		if err := rows.Scan(
+			&b.ID,
+			&b.MentorID,
+			&b.UserID,
+			&b.ServiceID,
+			&b.StartsAt,
+			&b.EndsAt,
+			&b.Status,
+			&b.PriceCents,
+			&b.Currency,
+			&b.SeriesID,
+			&b.CancellationReason,
+		); err != nil {
+			return nil, err
+		}
+
+		bookings = append(bookings, &b)
+	}
+
+	return bookings, rows.Err()
+}
+
+// ConfirmBookingsTx confirms the series' pending occurrences once its
+// payment is captured and returns them
+func (r *BookingSeriesRepository) ConfirmBookingsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	seriesID uuid.UUID,
+) ([]*models.Booking, error) {
+
+	const query = `
+	UPDATE bookings
+	SET
+		status = $2,
+		updated_at = NOW()
+	WHERE series_id = $1
+	  AND status = $3
+	RETURNING id, mentor_id, price_cents, currency
+	`
+
+	rows, err := tx.QueryContext(
+		ctx,
+		query,
+		seriesID,
+		models.BookingStatusConfirmed,
+		models.BookingStatusPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+
+	for rows.Next() {
+		b := models.Booking{SeriesID: &seriesID, Status: models.BookingStatusConfirmed}
+
+		if err := rows.Scan(&b.ID, &b.MentorID, &b.PriceCents, &b.Currency); err != nil {
+			return nil, err
+		}
+
+		bookings = append(bookings, &b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(bookings) == 0 {
+		return nil, errors.New("booking series not in pending state")
+	}
+
+	return bookings, nil
+}
+
+// ReviveBookingsTx confirms the series' occurrences that lost their slots
+// for want of payment (see BookingRepository.ReviveTx) and returns them
+func (r *BookingSeriesRepository) ReviveBookingsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	seriesID uuid.UUID,
+) ([]*models.Booking, error) {
+
+	const query = `
+	UPDATE bookings
+	SET
+		status = 'confirmed',
+		cancelled_at = NULL,
+		cancelled_by = NULL,
+		cancellation_reason = NULL,
+		updated_at = NOW()
+	WHERE series_id = $1
+	  AND (
+		status = 'payment_failed'
+		OR (status = 'cancelled' AND cancellation_reason = 'payment_timeout')
+	  )
+	RETURNING id, mentor_id, price_cents, currency
+	`
+
+	rows, err := tx.QueryContext(ctx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+
+	for rows.Next() {
+		b := models.Booking{SeriesID: &seriesID, Status: models.BookingStatusConfirmed}
+
+		if err := rows.Scan(&b.ID, &b.MentorID, &b.PriceCents, &b.Currency); err != nil {
+			return nil, err
+		}
+
+		bookings = append(bookings, &b)
+	}
+
+	return bookings, rows.Err()
+}
+
+// MarkPaymentFailedTx moves the series' pending occurrences to payment_failed
+func (r *BookingSeriesRepository) MarkPaymentFailedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	seriesID uuid.UUID,
+) error {
+
+	const query = `
+	UPDATE bookings
+	SET status = 'payment_failed',
+	    updated_at = NOW()
+	WHERE series_id = $1
+	  AND status = 'pending'
+	`
+
+	_, err := tx.ExecContext(ctx, query, seriesID)
+	return err
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type CalendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// SaveFeed stores the user's feed token hash, replacing any previous one
+func (r *CalendarRepository) SaveFeed(
+	ctx context.Context,
+	feed *models.CalendarFeed,
+) error {
+
+	const query = `
+	INSERT INTO calendar_feeds (user_id, token_hash, created_at)
+	VALUES ($1, $2, NOW())
+	ON CONFLICT (user_id) DO UPDATE
+	SET token_hash = EXCLUDED.token_hash,
+		created_at = NOW()
+	RETURNING created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, feed.UserID, feed.TokenHash).Scan(&feed.CreatedAt)
+}
+
+func (r *CalendarRepository) DeleteFeed(
+	ctx context.Context,
+	userID uuid.UUID,
+) error {
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "calendar feed not found")
+}
+
+// FindFeedUser returns the user owning the feed token hash
+func (r *CalendarRepository) FindFeedUser(
+	ctx context.Context,
+	tokenHash string,
+) (uuid.UUID, error) {
+
+	const query = `
+	SELECT f.user_id
+	FROM calendar_feeds f
+	JOIN users u ON u.id = f.user_id
+	WHERE f.token_hash = $1
+	  AND u.deleted_at IS NULL
+	`
+
+	var userID uuid.UUID
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(&userID)
+
+	return userID, err
+}
+
+const calendarEntrySelect = `
+	SELECT
+		b.id,
+		b.status,
+		b.starts_at,
+		b.ends_at,
+		b.created_at,
+		b.updated_at,
+		s.title,
+		lu.id,
+		TRIM(lu.first_name || ' ' || lu.last_name),
+		lu.email,
+		mu.id,
+		TRIM(mu.first_name || ' ' || mu.last_name),
+		mu.email
+	FROM bookings b
+	JOIN mentor_services s ON s.id = b.service_id
+	JOIN users lu ON lu.id = b.user_id
+	JOIN mentor_profiles mp ON mp.id = b.mentor_id
+	JOIN users mu ON mu.id = mp.user_id
+`
+
+// FindEntriesForUser returns the confirmed bookings the user attends as
+// learner or mentor that end after `since`, including past ones already
+// settled as completed or missed
+func (r *CalendarRepository) FindEntriesForUser(
+	ctx context.Context,
+	userID uuid.UUID,
+	since time.Time,
+) ([]*models.BookingCalendarEntry, error) {
+
+	query := calendarEntrySelect + `
+	WHERE (b.user_id = $1 OR mp.user_id = $1)
+	  AND b.status IN ('confirmed', 'completed', 'no_show_mentor', 'no_show_user', 'incomplete')
+	  AND b.ends_at > $2
+	ORDER BY b.starts_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.BookingCalendarEntry
+
+	for rows.Next() {
+		e, err := scanCalendarEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *CalendarRepository) GetEntry(
+	ctx context.Context,
+	bookingID uuid.UUID,
+) (*models.BookingCalendarEntry, error) {
+
+	return scanCalendarEntry(r.db.QueryRowContext(ctx, calendarEntrySelect+`WHERE b.id = $1`, bookingID))
+}
+
+func scanCalendarEntry(row rowScanner) (*models.BookingCalendarEntry, error) {
+	var e models.BookingCalendarEntry
+
+	if err := row.Scan(
+		&e.BookingID,
+		&e.Status,
+		&e.StartsAt,
+		&e.EndsAt,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+		&e.ServiceTitle,
+		&e.LearnerUserID,
+		&e.LearnerName,
+		&e.LearnerEmail,
+		&e.MentorUserID,
+		&e.MentorName,
+		&e.MentorEmail,
+	); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
@@ -0,0 +1,311 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type ExternalCalendarRepository struct {
+	db *sql.DB
+}
+
+func NewExternalCalendarRepository(db *sql.DB) *ExternalCalendarRepository {
+	return &ExternalCalendarRepository{db: db}
+}
+
+const externalCalendarColumns = `
+	id,
+	mentor_id,
+	name,
+	source_url,
+	ics_data,
+	last_synced_at,
+	last_error,
+	next_sync_at,
+	created_at,
+	updated_at
+`
+
+func (r *ExternalCalendarRepository) Create(
+	ctx context.Context,
+	cal *models.ExternalCalendar,
+) error {
+
+	const query = `
+	INSERT INTO external_calendars (
+		id,
+		mentor_id,
+		name,
+		source_url,
+		ics_data,
+		next_sync_at,
+		created_at,
+		updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, NOW(), NOW(), NOW())
+	RETURNING next_sync_at, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		cal.ID,
+		cal.MentorID,
+		cal.Name,
+		cal.SourceURL,
+		cal.ICSData,
+	).Scan(&cal.NextSyncAt, &cal.CreatedAt, &cal.UpdatedAt)
+}
+
+func (r *ExternalCalendarRepository) GetByID(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	id uuid.UUID,
+) (*models.ExternalCalendar, error) {
+
+	query := `SELECT ` + externalCalendarColumns + `
+	FROM external_calendars
+	WHERE id = $1
+	  AND mentor_id = $2
+	`
+
+	return scanExternalCalendar(r.db.QueryRowContext(ctx, query, id, mentorID))
+}
+
+func (r *ExternalCalendarRepository) ListByMentor(
+	ctx context.Context,
+	mentorID uuid.UUID,
+) ([]*models.ExternalCalendar, error) {
+
+	query := `SELECT ` + externalCalendarColumns + `
+	FROM external_calendars
+	WHERE mentor_id = $1
+	ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanExternalCalendars(rows)
+}
+
+// Delete removes the calendar together with its busy blocks
+func (r *ExternalCalendarRepository) Delete(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	id uuid.UUID,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM external_calendars WHERE id = $1 AND mentor_id = $2`,
+		id,
+		mentorID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := expectOneRow(result, "calendar not found"); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM external_busy_blocks WHERE calendar_id = $1`,
+		id,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDue hands out calendars whose next sync is due and pushes their
+// next sync back by `every`, so a calendar is synced by one worker at a
+// time
+func (r *ExternalCalendarRepository) ClaimDue(
+	ctx context.Context,
+	every time.Duration,
+	limit int,
+) ([]*models.ExternalCalendar, error) {
+
+	query := `
+	UPDATE external_calendars
+	SET next_sync_at = NOW() + make_interval(secs => $1)
+	WHERE id IN (
+		SELECT id
+		FROM external_calendars
+		WHERE next_sync_at <= NOW()
+		ORDER BY next_sync_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + externalCalendarColumns
+
+	rows, err := r.db.QueryContext(ctx, query, every.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanExternalCalendars(rows)
+}
+
+// ReplaceBlocks swaps the calendar's busy blocks for `blocks` and records
+// a successful sync
+func (r *ExternalCalendarRepository) ReplaceBlocks(
+	ctx context.Context,
+	cal *models.ExternalCalendar,
+	blocks []models.ExternalBusyBlock,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM external_busy_blocks WHERE calendar_id = $1`,
+		cal.ID,
+	); err != nil {
+		return err
+	}
+
+	const insert = `
+	INSERT INTO external_busy_blocks (calendar_id, mentor_id, starts_at, ends_at)
+	VALUES ($1, $2, $3, $4)
+	`
+
+	for _, b := range blocks {
+		if _, err := tx.ExecContext(ctx, insert, cal.ID, cal.MentorID, b.StartsAt, b.EndsAt); err != nil {
+			return err
+		}
+	}
+
+	const update = `
+	UPDATE external_calendars
+	SET
+		last_synced_at = NOW(),
+		last_error = NULL,
+		updated_at = NOW()
+	WHERE id = $1
+	RETURNING last_synced_at
+	`
+
+	if err := tx.QueryRowContext(ctx, update, cal.ID).Scan(&cal.LastSyncedAt); err != nil {
+		return err
+	}
+
+	cal.LastError = nil
+
+	return tx.Commit()
+}
+
+// MarkSyncFailed records why the last sync failed. The blocks from the
+// last good sync stay in place.
+func (r *ExternalCalendarRepository) MarkSyncFailed(
+	ctx context.Context,
+	id uuid.UUID,
+	reason string,
+) error {
+
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE external_calendars SET last_error = $2, updated_at = NOW() WHERE id = $1`,
+		id,
+		reason,
+	)
+
+	return err
+}
+
+// FindBusyBetween returns the mentor's external busy blocks overlapping
+// [from, to)
+func (r *ExternalCalendarRepository) FindBusyBetween(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	from time.Time,
+	to time.Time,
+) ([]*models.ExternalBusyBlock, error) {
+
+	const query = `
+	SELECT calendar_id, mentor_id, starts_at, ends_at
+	FROM external_busy_blocks
+	WHERE mentor_id = $1
+	  AND starts_at < $3
+	  AND ends_at > $2
+	ORDER BY starts_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*models.ExternalBusyBlock
+
+	for rows.Next() {
+		var b models.ExternalBusyBlock
+
+		if err := rows.Scan(&b.CalendarID, &b.MentorID, &b.StartsAt, &b.EndsAt); err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, &b)
+	}
+
+	return blocks, rows.Err()
+}
+
+func scanExternalCalendars(rows *sql.Rows) ([]*models.ExternalCalendar, error) {
+	defer rows.Close()
+
+	var calendars []*models.ExternalCalendar
+
+	for rows.Next() {
+		cal, err := scanExternalCalendar(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		calendars = append(calendars, cal)
+	}
+
+	return calendars, rows.Err()
+}
+
+func scanExternalCalendar(row rowScanner) (*models.ExternalCalendar, error) {
+	var cal models.ExternalCalendar
+
+	if err := row.Scan(
+		&cal.ID,
+		&cal.MentorID,
+		&cal.Name,
+		&cal.SourceURL,
+		&cal.ICSData,
+		&cal.LastSyncedAt,
+		&cal.LastError,
+		&cal.NextSyncAt,
+		&cal.CreatedAt,
+		&cal.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &cal, nil
+}
@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type InvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// DraftTx loads everything an invoice for paymentID needs - learner,
+// mentor and what was bought - into an unnumbered invoice
+func (r *InvoiceRepository) DraftTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	paymentID uuid.UUID,
+) (*models.Invoice, error) {
+
+	const query = `
+	SELECT
+		p.id,
+		p.booking_id,
+		p.package_purchase_id,
+		p.user_id,
+		TRIM(lu.first_name || ' ' || lu.last_name),
+		lu.email,
+		mp.id,
+		TRIM(mu.first_name || ' ' || mu.last_name),
+		mu.username,
+		CASE
+			WHEN p.booking_id IS NOT NULL THEN
+				ms.title || ' (' || to_char(b.starts_at AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI') || ' UTC)'
+			WHEN p.series_id IS NOT NULL THEN
+				paid.occurrences || ' x ' || ms.title || ' (' || bs.frequency || ' from ' ||
+				to_char(paid.first_starts_at AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI') || ' UTC)'
+			ELSE
+				sp.title || ' - ' || sp.session_count || ' x ' || ms.title
+		END,
+		p.currency,
+		p.discount_cents,
+		p.amount
+	FROM payments p
+	JOIN users lu ON lu.id = p.user_id
+	LEFT JOIN bookings b ON b.id = p.booking_id
+	LEFT JOIN package_purchases pp ON pp.id = p.package_purchase_id
+	LEFT JOIN service_packages sp ON sp.id = pp.package_id
+	LEFT JOIN booking_series bs ON bs.id = p.series_id
+	-- The occurrences the payment covered: those whose revenue was posted.
+	-- Occurrences cancelled before paying were left out of the order.
+	LEFT JOIN LATERAL (
+		SELECT
+			COUNT(*) AS occurrences,
+			MIN(sb.starts_at) AS first_starts_at
+		FROM bookings sb
+		JOIN ledger_transactions lt
+		  ON lt.reference_id = sb.id
+		 AND lt.kind = 'booking_revenue'
+		WHERE sb.series_id = bs.id
+	) paid ON true
+	JOIN mentor_services ms ON ms.id = COALESCE(b.service_id, pp.service_id, bs.service_id)
+	JOIN mentor_profiles mp ON mp.id = ms.mentor_id
+	JOIN users mu ON mu.id = mp.user_id
+	WHERE p.id = $1
+	`
+
+	var inv models.Invoice
+
+	err := tx.QueryRowContext(ctx, query, paymentID).Scan(
+		&inv.PaymentID,
+		&inv.BookingID,
+		&inv.PackagePurchaseID,
+		&inv.UserID,
+		&inv.LearnerName,
+		&inv.LearnerEmail,
+		&inv.MentorID,
+		&inv.MentorName,
+		&inv.MentorUsername,
+		&inv.Description,
+		&inv.Currency,
+		&inv.DiscountCents,
+		&inv.TotalCents,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
+
+// CreateTx numbers and stores an invoice. Numbers come from a counter
+// row locked until the transaction ends, so they are unique and
+// increasing across server instances, and a rolled-back transaction
+// gives its number back. A payment gets at most one invoice: if it
+// already has one, nothing is written and false is returned.
+func (r *InvoiceRepository) CreateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	inv *models.Invoice,
+) (bool, error) {
+
+	const ensureCounter = `
+	INSERT INTO invoice_counters (name, last_number)
+	VALUES ('invoice', 0)
+	ON CONFLICT (name) DO NOTHING
+	`
+
+	const lockCounter = `
+	SELECT last_number
+	FROM invoice_counters
+	WHERE name = 'invoice'
+	FOR UPDATE
+	`
+
+	const exists = `
+	SELECT EXISTS (SELECT 1 FROM invoices WHERE payment_id = $1)
+	`
+
+	const takeNumber = `
+	UPDATE invoice_counters
+	SET last_number = last_number + 1
+	WHERE name = 'invoice'
+	RETURNING last_number
+	`
+
+	const insert = `
+	INSERT INTO invoices (
+		id,
+		number,
+		payment_id,
+		booking_id,
+		package_purchase_id,
+		user_id,
+		learner_name,
+		learner_email,
+		mentor_id,
+		mentor_name,
+		mentor_username,
+		description,
+		currency,
+		discount_cents,
+		subtotal_cents,
+		tax_label,
+		tax_rate_bps,
+		tax_cents,
+		total_cents,
+		issued_at
+	)
+	VALUES (
+		$1,
+		'INV-' || to_char(NOW(), 'YYYY') || '-' || lpad($19::text, 6, '0'),
+		$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,
+		NOW()
+	)
+	RETURNING number, issued_at
+	`
+
+	if _, err := tx.ExecContext(ctx, ensureCounter); err != nil {
+		return false, err
+	}
+
+	// Once the counter is ours, an invoice issued concurrently for the
+	// same payment is committed and visible below, so no number is
+	// taken for a duplicate
+	var last int64
+	if err := tx.QueryRowContext(ctx, lockCounter).Scan(&last); err != nil {
+		return false, err
+	}
+
+	var issued bool
+	if err := tx.QueryRowContext(ctx, exists, inv.PaymentID).Scan(&issued); err != nil {
+		return false, err
+	}
+
+	if issued {
+		return false, nil
+	}
+
+	var number int64
+	if err := tx.QueryRowContext(ctx, takeNumber).Scan(&number); err != nil {
+		return false, err
+	}
+
+	err := tx.QueryRowContext(
+		ctx,
+		insert,
+		inv.ID,
+		inv.PaymentID,
+		inv.BookingID,
+		inv.PackagePurchaseID,
+		inv.UserID,
+		inv.LearnerName,
+		inv.LearnerEmail,
+		inv.MentorID,
+		inv.MentorName,
+		inv.MentorUsername,
+		inv.Description,
+		inv.Currency,
+		inv.DiscountCents,
+		inv.SubtotalCents,
+		inv.TaxLabel,
+		inv.TaxRateBps,
+		inv.TaxCents,
+		inv.TotalCents,
+		number,
+	).Scan(&inv.Number, &inv.IssuedAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetByPaymentID returns the invoice of a payment or sql.ErrNoRows
+func (r *InvoiceRepository) GetByPaymentID(
+	ctx context.Context,
+	paymentID uuid.UUID,
+) (*models.Invoice, error) {
+
+	const query = `
+	SELECT
+		id,
+		number,
+		payment_id,
+		booking_id,
+		package_purchase_id,
+		user_id,
+		learner_name,
+		learner_email,
+		mentor_id,
+		mentor_name,
+		mentor_username,
+		description,
+		currency,
+		discount_cents,
+		subtotal_cents,
+		tax_label,
+		tax_rate_bps,
+		tax_cents,
+		total_cents,
+		issued_at
+	FROM invoices
+	WHERE payment_id = $1
+	`
+
+	var inv models.Invoice
+
+	err := r.db.QueryRowContext(ctx, query, paymentID).Scan(
+		&inv.ID,
+		&inv.Number,
+		&inv.PaymentID,
+		&inv.BookingID,
+		&inv.PackagePurchaseID,
+		&inv.UserID,
+		&inv.LearnerName,
+		&inv.LearnerEmail,
+		&inv.MentorID,
+		&inv.MentorName,
+		&inv.MentorUsername,
+		&inv.Description,
+		&inv.Currency,
+		&inv.DiscountCents,
+		&inv.SubtotalCents,
+		&inv.TaxLabel,
+		&inv.TaxRateBps,
+		&inv.TaxCents,
+		&inv.TotalCents,
+		&inv.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type LedgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// CreateTx writes a transaction and its entries. It returns false without
+// writing anything when a transaction with the same (kind, reference_id)
+// was already posted.
+func (r *LedgerRepository) CreateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	t *models.LedgerTransaction,
+) (bool, error) {
+
+	const insertTransaction = `
+	INSERT INTO ledger_transactions (
+		id,
+		kind,
+		reference_type,
+		reference_id,
+		mentor_id,
+		currency,
+		created_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,NOW())
+	ON CONFLICT (kind, reference_id) DO NOTHING
+	RETURNING created_at
+	`
+
+	const insertEntry = `
+	INSERT INTO ledger_entries (
+		id,
+		transaction_id,
+		account,
+		direction,
+		amount
+	)
+	VALUES ($1,$2,$3,$4,$5)
+	`
+
+	err := tx.QueryRowContext(
+		ctx,
+		insertTransaction,
+		t.ID,
+		t.Kind,
+		t.ReferenceType,
+		t.ReferenceID,
+		t.MentorID,
+		t.Currency,
+	).Scan(&t.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, e := range t.Entries {
+		if _, err := tx.ExecContext(
+			ctx,
+			insertEntry,
+			uuid.New(),
+			t.ID,
+			e.Account,
+			e.Direction,
+			e.Amount,
+		); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// FindRevenueByReferenceTx returns the booking_revenue or package_revenue
+// transaction posted for a reference, with its entries, or sql.ErrNoRows
+func (r *LedgerRepository) FindRevenueByReferenceTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	referenceID uuid.UUID,
+) (*models.LedgerTransaction, error) {
+
+	const query = `
+	SELECT
+		t.id,
+		t.kind,
+		t.reference_type,
+		t.reference_id,
+		t.mentor_id,
+		t.currency,
+		t.created_at,
+		e.id,
+		e.account,
+		e.direction,
+		e.amount
+	FROM ledger_transactions t
+	JOIN ledger_entries e ON e.transaction_id = t.id
+	WHERE t.kind IN ($1, $2)
+	  AND t.reference_id = $3
+	`
+
+	rows, err := tx.QueryContext(
+		ctx,
+		query,
+		models.LedgerKindBookingRevenue,
+		models.LedgerKindPackageRevenue,
+		referenceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var t *models.LedgerTransaction
+
+	for rows.Next() {
+		var (
+			head models.LedgerTransaction
+			e    models.LedgerEntry
+		)
+
+		if err := rows.Scan(
+			&head.ID,
+			&head.Kind,
+			&head.ReferenceType,
+			&head.ReferenceID,
+			&head.MentorID,
+			&head.Currency,
+			&head.CreatedAt,
+			&e.ID,
+			&e.Account,
+			&e.Direction,
+			&e.Amount,
+		); err != nil {
+			return nil, err
+		}
+
+		if t == nil {
+			t = &head
+		}
+
+		e.TransactionID = t.ID
+		t.Entries = append(t.Entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if t == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return t, nil
+}
+
+// MentorBalances returns what is currently owed to the mentor, per currency
+func (r *LedgerRepository) MentorBalances(
+	ctx context.Context,
+	mentorID uuid.UUID,
+) ([]dtos.EarningsBalance, error) {
+
+	const query = `
+	SELECT
+		t.currency,
+		COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END), 0)
+	FROM ledger_transactions t
+	JOIN ledger_entries e ON e.transaction_id = t.id
+	WHERE t.mentor_id = $1
+	  AND e.account = $2
+	GROUP BY t.currency
+	ORDER BY t.currency
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID, models.LedgerAccountMentorPayable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []dtos.EarningsBalance{}
+
+	for rows.Next() {
+		var b dtos.EarningsBalance
+		if err := rows.Scan(&b.Currency, &b.BalanceCents); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+// MentorStatements aggregates the mentor's ledger per period and currency.
+// period must be one of day, week or month.
+func (r *LedgerRepository) MentorStatements(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	period string,
+	from time.Time,
+	to time.Time,
+) ([]dtos.EarningsStatement, error) {
+
+	const query = `
+	SELECT
+		date_trunc($2, t.created_at) AS period_start,
+		t.currency,
+		COUNT(DISTINCT t.id) FILTER (WHERE t.kind = 'booking_revenue'),
+		COALESCE(SUM(e.amount) FILTER (
+			WHERE e.account = 'gateway_clearing' AND e.direction = 'debit'
+		), 0),
+		COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) FILTER (
+			WHERE e.account = 'platform_commission'
+		), 0),
+		COALESCE(SUM(e.amount) FILTER (
+			WHERE e.account = 'gateway_clearing' AND e.direction = 'credit'
+		), 0),
+		COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) FILTER (
+			WHERE e.account = 'mentor_payable'
+		), 0)
+	FROM ledger_transactions t
+	JOIN ledger_entries e ON e.transaction_id = t.id
+	WHERE t.mentor_id = $1
+	  AND t.created_at >= $3
+	  AND t.created_at < $4
+	GROUP BY period_start, t.currency
+	ORDER BY period_start DESC, t.currency
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statements := []dtos.EarningsStatement{}
+
+	for rows.Next() {
+		var (
+			s           dtos.EarningsStatement
+			periodStart time.Time
+		)
+
+		if err := rows.Scan(
+			&periodStart,
+			&s.Currency,
+			&s.Bookings,
+			&s.GrossCents,
+			&s.CommissionCents,
+			&s.RefundedCents,
+			&s.NetCents,
+		); err != nil {
+			return nil, err
+		}
+
+		s.PeriodStart = periodStart.Format("2006-01-02")
+		statements = append(statements, s)
+	}
+
+	return statements, rows.Err()
+}
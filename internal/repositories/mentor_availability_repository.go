@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -53,6 +54,112 @@ func (r *MentorAvailabilityRepository) Create(
 	return rule, nil
 }
 
+// Update replaces a weekly rule of rule.MentorID; it returns an error if
+// the mentor has no such rule
+func (r *MentorAvailabilityRepository) Update(
+	ctx context.Context,
+	rule *models.MentorAvailabilityRule,
+) error {
+
+	const query = `
+	UPDATE mentor_availability_rules
+	SET
+		day_of_week = $3,
+		start_time = $4,
+		end_time = $5,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND mentor_id = $2
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		rule.ID,
+		rule.MentorID,
+		rule.DayOfWeek,
+		rule.StartTime,
+		rule.EndTime,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "availability rule not found")
+}
+
+func (r *MentorAvailabilityRepository) Delete(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	id uuid.UUID,
+) error {
+
+	const query = `
+	DELETE FROM mentor_availability_rules
+	WHERE id = $1
+	  AND mentor_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, mentorID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "availability rule not found")
+}
+
+// ReplaceAll swaps every weekly rule of the mentor for `rules` in one
+// transaction, so readers never see a half-written week
+func (r *MentorAvailabilityRepository) ReplaceAll(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	rules []*models.MentorAvailabilityRule,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM mentor_availability_rules WHERE mentor_id = $1`,
+		mentorID,
+	); err != nil {
+		return err
+	}
+
+	const insert = `
+	INSERT INTO mentor_availability_rules (
+		id,
+		mentor_id,
+		day_of_week,
+		start_time,
+		end_time,
+		created_at,
+		updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+	`
+
+	for _, rule := range rules {
+		if _, err := tx.ExecContext(
+			ctx,
+			insert,
+			rule.ID,
+			mentorID,
+			rule.DayOfWeek,
+			rule.StartTime,
+			rule.EndTime,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *MentorAvailabilityRepository) FindByUsername(
 	username string,
 ) ([]*models.MentorAvailabilityRule, error) {
@@ -97,9 +204,9 @@ func (r *MentorAvailabilityRepository) FindByUsername(
 	return rules, nil
 }
 
-func (r *MentorAvailabilityRepository) FindByMentorAndDay(
+// FindByMentor returns all weekly rules of a mentor
+func (r *MentorAvailabilityRepository) FindByMentor(
 	mentorID uuid.UUID,
-	dayOfWeek int,
 ) ([]*models.MentorAvailabilityRule, error) {
 
 	const query = `
@@ -110,11 +217,10 @@ func (r *MentorAvailabilityRepository) FindByMentorAndDay(
 		end_time
 	FROM mentor_availability_rules
 	WHERE mentor_id = $1
-	  AND day_of_week = $2
-	ORDER BY start_time
+	ORDER BY day_of_week, start_time
 	`
 
-	rows, err := r.db.Query(query, mentorID, dayOfWeek)
+	rows, err := r.db.Query(query, mentorID)
 	if err != nil {
 		return nil, err
 	}
@@ -139,3 +245,175 @@ func (r *MentorAvailabilityRepository) FindByMentorAndDay(
 
 	return rules, nil
 }
+
+const overrideColumns = `
+	id,
+	mentor_id,
+	kind,
+	start_date,
+	end_date,
+	start_time,
+	end_time,
+	reason,
+	created_at,
+	updated_at
+`
+
+func (r *MentorAvailabilityRepository) CreateOverride(
+	ctx context.Context,
+	o *models.MentorAvailabilityOverride,
+) error {
+
+	const query = `
+	INSERT INTO mentor_availability_overrides (
+		id,
+		mentor_id,
+		kind,
+		start_date,
+		end_date,
+		start_time,
+		end_time,
+		reason,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NOW(),NOW())
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		o.ID,
+		o.MentorID,
+		o.Kind,
+		o.StartDate,
+		o.EndDate,
+		o.StartTime,
+		o.EndTime,
+		o.Reason,
+	)
+
+	return err
+}
+
+// UpdateOverride replaces an override of mentorID; it returns an error if
+// the mentor has no such override
+func (r *MentorAvailabilityRepository) UpdateOverride(
+	ctx context.Context,
+	o *models.MentorAvailabilityOverride,
+) error {
+
+	const query = `
+	UPDATE mentor_availability_overrides
+	SET
+		kind = $3,
+		start_date = $4,
+		end_date = $5,
+		start_time = $6,
+		end_time = $7,
+		reason = $8,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND mentor_id = $2
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		o.ID,
+		o.MentorID,
+		o.Kind,
+		o.StartDate,
+		o.EndDate,
+		o.StartTime,
+		o.EndTime,
+		o.Reason,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "availability override not found")
+}
+
+func (r *MentorAvailabilityRepository) DeleteOverride(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	id uuid.UUID,
+) error {
+
+	const query = `
+	DELETE FROM mentor_availability_overrides
+	WHERE id = $1
+	  AND mentor_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, mentorID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, "availability override not found")
+}
+
+// FindOverridesBetween returns the mentor's overrides touching the dates
+// from..to (inclusive), ordered by date
+func (r *MentorAvailabilityRepository) FindOverridesBetween(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	from time.Time,
+	to time.Time,
+) ([]*models.MentorAvailabilityOverride, error) {
+
+	query := `SELECT ` + overrideColumns + `
+	FROM mentor_availability_overrides
+	WHERE mentor_id = $1
+	  AND start_date <= $3
+	  AND end_date >= $2
+	ORDER BY start_date, start_time NULLS FIRST
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*models.MentorAvailabilityOverride
+
+	for rows.Next() {
+		var o models.MentorAvailabilityOverride
+
+		if err := rows.Scan(
+			&o.ID,
+			&o.MentorID,
+			&o.Kind,
+			&o.StartDate,
+			&o.EndDate,
+			&o.StartTime,
+			&o.EndTime,
+			&o.Reason,
+			&o.CreatedAt,
+			&o.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		overrides = append(overrides, &o)
+	}
+
+	return overrides, rows.Err()
+}
+
+func expectOneRow(result sql.Result, notFound string) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New(notFound)
+	}
+
+	return nil
+}
@@ -30,10 +30,14 @@ func (r *MentorRepository) CreateProfile(
 		bio,
 		timezone,
 		is_active,
+		buffer_minutes,
+		min_notice_minutes,
+		max_advance_days,
+		daily_session_cap,
 		created_at,
 		updated_at
 	)
-	VALUES ($1,$2,$3,$4,$5,true,NOW(),NOW())
+	VALUES ($1,$2,$3,$4,$5,true,$6,$7,$8,$9,NOW(),NOW())
 	RETURNING created_at, updated_at
 	`
 
@@ -48,6 +52,10 @@ func (r *MentorRepository) CreateProfile(
 		profile.Title,
 		profile.Bio,
 		profile.Timezone,
+		profile.BufferMinutes,
+		profile.MinNoticeMinutes,
+		profile.MaxAdvanceDays,
+		profile.DailySessionCap,
 	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
 }
 
@@ -63,6 +71,10 @@ func (r *MentorRepository) FindByUserID(
 		bio,
 		timezone,
 		is_active,
+		buffer_minutes,
+		min_notice_minutes,
+		max_advance_days,
+		daily_session_cap,
 		created_at,
 		updated_at
 	FROM mentor_profiles
@@ -80,6 +92,10 @@ func (r *MentorRepository) FindByUserID(
 		&mentor.Bio,
 		&mentor.Timezone,
 		&mentor.IsActive,
+		&mentor.BufferMinutes,
+		&mentor.MinNoticeMinutes,
+		&mentor.MaxAdvanceDays,
+		&mentor.DailySessionCap,
 		&mentor.CreatedAt,
 		&mentor.UpdatedAt,
 	)
@@ -151,6 +167,10 @@ func (r *MentorRepository) FindByUsernameRaw(
 		mp.bio,
 		mp.timezone,
 		mp.is_active,
+		mp.buffer_minutes,
+		mp.min_notice_minutes,
+		mp.max_advance_days,
+		mp.daily_session_cap,
 		mp.created_at,
 		mp.updated_at
 	FROM users u
@@ -171,6 +191,10 @@ func (r *MentorRepository) FindByUsernameRaw(
 		&mentor.Bio,
 		&mentor.Timezone,
 		&mentor.IsActive,
+		&mentor.BufferMinutes,
+		&mentor.MinNoticeMinutes,
+		&mentor.MaxAdvanceDays,
+		&mentor.DailySessionCap,
 		&mentor.CreatedAt,
 		&mentor.UpdatedAt,
 	)
@@ -194,6 +218,10 @@ func (r *MentorRepository) FindByID(
 		bio,
 		timezone,
 		is_active,
+		buffer_minutes,
+		min_notice_minutes,
+		max_advance_days,
+		daily_session_cap,
 		created_at,
 		updated_at
 	FROM mentor_profiles
@@ -210,6 +238,10 @@ func (r *MentorRepository) FindByID(
 		&mentor.Bio,
 		&mentor.Timezone,
 		&mentor.IsActive,
+		&mentor.BufferMinutes,
+		&mentor.MinNoticeMinutes,
+		&mentor.MaxAdvanceDays,
+		&mentor.DailySessionCap,
 		&mentor.CreatedAt,
 		&mentor.UpdatedAt,
 	)
@@ -220,3 +252,52 @@ func (r *MentorRepository) FindByID(
 
 	return &mentor, nil
 }
+
+// UpdateBookingSettings stores the booking settings of the mentor profile
+func (r *MentorRepository) UpdateBookingSettings(
+	ctx context.Context,
+	mentor *models.MentorProfile,
+) error {
+
+	const query = `
+	UPDATE mentor_profiles
+	SET
+		buffer_minutes = $2,
+		min_notice_minutes = $3,
+		max_advance_days = $4,
+		daily_session_cap = $5,
+		updated_at = NOW()
+	WHERE id = $1
+	RETURNING updated_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		mentor.ID,
+		mentor.BufferMinutes,
+		mentor.MinNoticeMinutes,
+		mentor.MaxAdvanceDays,
+		mentor.DailySessionCap,
+	).Scan(&mentor.UpdatedAt)
+}
+
+// LockTx locks the mentor's profile row until the transaction ends, so
+// checks spanning several of the mentor's bookings (like the daily cap)
+// can't be raced by a concurrent booking
+func (r *MentorRepository) LockTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentorID uuid.UUID,
+) error {
+
+	const query = `
+	SELECT id
+	FROM mentor_profiles
+	WHERE id = $1
+	FOR UPDATE
+	`
+
+	var id uuid.UUID
+	return tx.QueryRowContext(ctx, query, mentorID).Scan(&id)
+}
@@ -30,11 +30,14 @@ func (r *MentorServiceRepository) Create(
 		duration_minutes,
 		price_cents,
 		currency,
+		service_type,
+		capacity,
+		requires_approval,
 		is_active,
 		created_at,
 		updated_at
 	)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,true,NOW(),NOW())
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,true,NOW(),NOW())
 	`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -50,6 +53,9 @@ func (r *MentorServiceRepository) Create(
 		service.DurationMinutes,
 		service.PriceCents,
 		service.Currency,
+		service.Type,
+		service.Capacity,
+		service.RequiresApproval,
 	)
 
 	return err
@@ -68,6 +74,9 @@ func (r *MentorServiceRepository) FindByUsername(
 		ms.duration_minutes,
 		ms.price_cents,
 		ms.currency,
+		ms.service_type,
+		ms.capacity,
+		ms.requires_approval,
 		ms.is_active,
 		ms.created_at,
 		ms.updated_at
@@ -90,29 +99,64 @@ func (r *MentorServiceRepository) FindByUsername(
 	var services []*models.MentorService
 
 	for rows.Next() {
-		var s models.MentorService
-
-		if err := rows.Scan(
-			&s.ID,
-			&s.MentorID,
-			&s.Title,
-			&s.Description,
-			&s.DurationMinutes,
-			&s.PriceCents,
-			&s.Currency,
-			&s.IsActive,
-			&s.CreatedAt,
-			&s.UpdatedAt,
-		); err != nil {
+		s, err := scanMentorService(rows)
+		if err != nil {
 			return nil, err
 		}
 
-		services = append(services, &s)
+		services = append(services, s)
 	}
 
 	return services, nil
 }
 
+// FindActiveByMentorID lists the mentor's active services
+func (r *MentorServiceRepository) FindActiveByMentorID(
+	ctx context.Context,
+	mentorID uuid.UUID,
+) ([]*models.MentorService, error) {
+
+	const query = `
+	SELECT
+		id,
+		mentor_id,
+		title,
+		description,
+		duration_minutes,
+		price_cents,
+		currency,
+		service_type,
+		capacity,
+		requires_approval,
+		is_active,
+		created_at,
+		updated_at
+	FROM mentor_services
+	WHERE mentor_id = $1
+	  AND is_active = true
+	ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*models.MentorService
+
+	for rows.Next() {
+		s, err := scanMentorService(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		services = append(services, s)
+	}
+
+	return services, rows.Err()
+}
+
 func (r *MentorServiceRepository) FindByID(
 	serviceID uuid.UUID,
 ) (*models.MentorService, error) {
@@ -126,6 +170,9 @@ func (r *MentorServiceRepository) FindByID(
 		duration_minutes,
 		price_cents,
 		currency,
+		service_type,
+		capacity,
+		requires_approval,
 		is_active,
 		created_at,
 		updated_at
@@ -134,12 +181,42 @@ func (r *MentorServiceRepository) FindByID(
 	  AND is_active = true
 	`
 
+	return scanMentorService(r.db.QueryRow(query, serviceID))
+}
+
+// FindByIDIncludingInactive also finds services the mentor has since
+// retired, for looking at existing bookings
+func (r *MentorServiceRepository) FindByIDIncludingInactive(
+	ctx context.Context,
+	serviceID uuid.UUID,
+) (*models.MentorService, error) {
+
+	const query = `
+	SELECT
+		id,
+		mentor_id,
+		title,
+		description,
+		duration_minutes,
+		price_cents,
+		currency,
+		service_type,
+		capacity,
+		requires_approval,
+		is_active,
+		created_at,
+		updated_at
+	FROM mentor_services
+	WHERE id = $1
+	`
+
+	return scanMentorService(r.db.QueryRowContext(ctx, query, serviceID))
+}
+
+func scanMentorService(row rowScanner) (*models.MentorService, error) {
 	var service models.MentorService
 
-	err := r.db.QueryRow(
-		query,
-		serviceID,
-	).Scan(
+	if err := row.Scan(
 		&service.ID,
 		&service.MentorID,
 		&service.Title,
@@ -147,12 +224,13 @@ func (r *MentorServiceRepository) FindByID(
 		&service.DurationMinutes,
 		&service.PriceCents,
 		&service.Currency,
+		&service.Type,
+		&service.Capacity,
+		&service.RequiresApproval,
 		&service.IsActive,
 		&service.CreatedAt,
 		&service.UpdatedAt,
-	)
-
-	if err != nil {
+	); err != nil {
 		return nil, err
 	}
 
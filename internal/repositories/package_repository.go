@@ -0,0 +1,410 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type PackageRepository struct {
+	db *sql.DB
+}
+
+func NewPackageRepository(db *sql.DB) *PackageRepository {
+	return &PackageRepository{db: db}
+}
+
+func (r *PackageRepository) Create(
+	ctx context.Context,
+	p *models.ServicePackage,
+) error {
+
+	const query = `
+	INSERT INTO service_packages (
+		id,
+		mentor_id,
+		service_id,
+		title,
+		session_count,
+		price_cents,
+		currency,
+		validity_days,
+		is_active,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NOW(),NOW())
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		p.ID,
+		p.MentorID,
+		p.ServiceID,
+		p.Title,
+		p.SessionCount,
+		p.PriceCents,
+		p.Currency,
+		p.ValidityDays,
+		p.IsActive,
+	)
+
+	return err
+}
+
+func (r *PackageRepository) FindByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*models.ServicePackage, error) {
+
+	const query = `
+	SELECT
+		id,
+		mentor_id,
+		service_id,
+		title,
+		session_count,
+		price_cents,
+		currency,
+		validity_days,
+		is_active,
+		created_at,
+		updated_at
+	FROM service_packages
+	WHERE id = $1
+	`
+
+	var p models.ServicePackage
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID,
+		&p.MentorID,
+		&p.ServiceID,
+		&p.Title,
+		&p.SessionCount,
+		&p.PriceCents,
+		&p.Currency,
+		&p.ValidityDays,
+		&p.IsActive,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("package not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// FindActiveByUsername lists the active packages of a mentor's active services
+func (r *PackageRepository) FindActiveByUsername(
+	ctx context.Context,
+	username string,
+) ([]*models.ServicePackage, error) {
+
+	const query = `
+	SELECT
+		sp.id,
+		sp.mentor_id,
+		sp.service_id,
+		sp.title,
+		sp.session_count,
+		sp.price_cents,
+		sp.currency,
+		sp.validity_days,
+		sp.is_active,
+		sp.created_at,
+		sp.updated_at
+	FROM users u
+	JOIN mentor_profiles mp ON mp.user_id = u.id
+	JOIN service_packages sp ON sp.mentor_id = mp.id
+	JOIN mentor_services ms ON ms.id = sp.service_id
+	WHERE u.username = $1
+	  AND u.deleted_at IS NULL
+	  AND mp.is_active = true
+	  AND ms.is_active = true
+	  AND sp.is_active = true
+	ORDER BY sp.created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var packages []*models.ServicePackage
+
+	for rows.Next() {
+		var p models.ServicePackage
+
+		if err := rows.Scan(
+			&p.ID,
+			&p.MentorID,
+			&p.ServiceID,
+			&p.Title,
+			&p.SessionCount,
+			&p.PriceCents,
+			&p.Currency,
+			&p.ValidityDays,
+			&p.IsActive,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		packages = append(packages, &p)
+	}
+
+	return packages, rows.Err()
+}
+
+func (r *PackageRepository) CreatePurchase(
+	ctx context.Context,
+	p *models.PackagePurchase,
+) error {
+
+	const query = `
+	INSERT INTO package_purchases (
+		id,
+		package_id,
+		user_id,
+		mentor_id,
+		service_id,
+		credits_total,
+		credits_remaining,
+		price_cents,
+		currency,
+		status,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,NOW(),NOW())
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		p.ID,
+		p.PackageID,
+		p.UserID,
+		p.MentorID,
+		p.ServiceID,
+		p.CreditsTotal,
+		p.CreditsRemaining,
+		p.PriceCents,
+		p.Currency,
+		p.Status,
+	)
+
+	return err
+}
+
+const packagePurchaseColumns = `
+	id,
+	package_id,
+	user_id,
+	mentor_id,
+	service_id,
+	credits_total,
+	credits_remaining,
+	price_cents,
+	currency,
+	status,
+	activated_at,
+	expires_at,
+	created_at,
+	updated_at
+`
+
+func (r *PackageRepository) GetPurchaseByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*models.PackagePurchase, error) {
+
+	query := `SELECT ` + packagePurchaseColumns + `
+	FROM package_purchases
+	WHERE id = $1
+	`
+
+	p, err := scanPackagePurchase(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("package purchase not found")
+	}
+
+	return p, err
+}
+
+func (r *PackageRepository) ListPurchasesByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*models.PackagePurchase, error) {
+
+	query := `SELECT ` + packagePurchaseColumns + `
+	FROM package_purchases
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purchases []*models.PackagePurchase
+
+	for rows.Next() {
+		p, err := scanPackagePurchase(rows)
+		if err != nil {
+			return nil, err
+		}
+		purchases = append(purchases, p)
+	}
+
+	return purchases, rows.Err()
+}
+
+// ActivatePurchaseTx grants the credits of a paid purchase and starts its
+// validity period. A purchase whose payment failed is activated by a late
+// capture. It returns sql.ErrNoRows if the purchase is neither pending nor
+// failed.
+func (r *PackageRepository) ActivatePurchaseTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	id uuid.UUID,
+) (*models.PackagePurchase, error) {
+
+	query := `
+	UPDATE package_purchases pp
+	SET
+		status = 'active',
+		credits_remaining = pp.credits_total,
+		activated_at = NOW(),
+		expires_at = NOW() + (
+			SELECT make_interval(days => sp.validity_days)
+			FROM service_packages sp
+			WHERE sp.id = pp.package_id
+		),
+		updated_at = NOW()
+	WHERE pp.id = $1
+	  AND pp.status IN ('pending', 'failed')
+	RETURNING ` + packagePurchaseColumns
+
+	return scanPackagePurchase(tx.QueryRowContext(ctx, query, id))
+}
+
+func (r *PackageRepository) MarkPurchaseFailedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	id uuid.UUID,
+) error {
+
+	const query = `
+	UPDATE package_purchases
+	SET
+		status = 'failed',
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status = 'pending'
+	`
+
+	_, err := tx.ExecContext(ctx, query, id)
+	return err
+}
+
+// SpendCreditTx takes one credit from the learner's active, unexpired
+// purchase of serviceID. The conditional update is atomic, so concurrent
+// bookings can't overspend.
+func (r *PackageRepository) SpendCreditTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	purchaseID uuid.UUID,
+	userID uuid.UUID,
+	serviceID uuid.UUID,
+) error {
+
+	const query = `
+	UPDATE package_purchases
+	SET
+		credits_remaining = credits_remaining - 1,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND user_id = $2
+	  AND service_id = $3
+	  AND status = 'active'
+	  AND credits_remaining > 0
+	  AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	result, err := tx.ExecContext(ctx, query, purchaseID, userID, serviceID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("no package credits available for this service")
+	}
+
+	return nil
+}
+
+// ReturnCreditTx gives back the credit spent on a cancelled booking
+func (r *PackageRepository) ReturnCreditTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	purchaseID uuid.UUID,
+) error {
+
+	const query = `
+	UPDATE package_purchases
+	SET
+		credits_remaining = LEAST(credits_remaining + 1, credits_total),
+		updated_at = NOW()
+	WHERE id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, purchaseID)
+	return err
+}
+
+func scanPackagePurchase(row rowScanner) (*models.PackagePurchase, error) {
+	var p models.PackagePurchase
+
+	err := row.Scan(
+		&p.ID,
+		&p.PackageID,
+		&p.UserID,
+		&p.MentorID,
+		&p.ServiceID,
+		&p.CreditsTotal,
+		&p.CreditsRemaining,
+		&p.PriceCents,
+		&p.Currency,
+		&p.Status,
+		&p.ActivatedAt,
+		&p.ExpiresAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
@@ -3,11 +3,17 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/preetsinghmakkar/OpenCall/internal/models"
 )
 
+// ErrPaymentSettled is returned when a payment has already moved past the
+// state a transition expects, e.g. a second capture of a paid payment
+var ErrPaymentSettled = errors.New("payment already settled")
+
 type PaymentRepository struct {
 	db *sql.DB
 }
@@ -26,14 +32,17 @@ func (r *PaymentRepository) Create(
 		INSERT INTO payments (
 			id,
 			booking_id,
+			package_purchase_id,
+			series_id,
 			user_id,
 			gateway,
 			gateway_order_id,
 			amount,
 			currency,
+			discount_cents,
 			status
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
 	`
 
 	_, err := tx.ExecContext(
@@ -41,11 +50,14 @@ func (r *PaymentRepository) Create(
 		query,
 		p.ID,
 		p.BookingID,
+		p.PackagePurchaseID,
+		p.SeriesID,
 		p.UserID,
 		p.Gateway,
 		p.GatewayOrderID,
 		p.Amount,
 		p.Currency,
+		p.DiscountCents,
 		p.Status,
 	)
 
@@ -61,6 +73,8 @@ func (r *PaymentRepository) GetByID(
 		SELECT
 			id,
 			booking_id,
+			package_purchase_id,
+			series_id,
 			user_id,
 			gateway,
 			gateway_order_id,
@@ -68,6 +82,7 @@ func (r *PaymentRepository) GetByID(
 			gateway_signature,
 			amount,
 			currency,
+			discount_cents,
 			status,
 			created_at,
 			updated_at
@@ -80,6 +95,8 @@ func (r *PaymentRepository) GetByID(
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&p.ID,
 		&p.BookingID,
+		&p.PackagePurchaseID,
+		&p.SeriesID,
 		&p.UserID,
 		&p.Gateway,
 		&p.GatewayOrderID,
@@ -87,6 +104,7 @@ func (r *PaymentRepository) GetByID(
 		&p.GatewaySignature,
 		&p.Amount,
 		&p.Currency,
+		&p.DiscountCents,
 		&p.Status,
 		&p.CreatedAt,
 		&p.UpdatedAt,
@@ -99,6 +117,32 @@ func (r *PaymentRepository) GetByID(
 	return &p, nil
 }
 
+// GetMentorID returns the mentor paid by the payment, through whatever
+// it was for: a booking, a package purchase or a series
+func (r *PaymentRepository) GetMentorID(
+	ctx context.Context,
+	paymentID uuid.UUID,
+) (uuid.UUID, error) {
+
+	const query = `
+		SELECT ms.mentor_id
+		FROM payments p
+		LEFT JOIN bookings b ON b.id = p.booking_id
+		LEFT JOIN package_purchases pp ON pp.id = p.package_purchase_id
+		LEFT JOIN booking_series bs ON bs.id = p.series_id
+		JOIN mentor_services ms ON ms.id = COALESCE(b.service_id, pp.service_id, bs.service_id)
+		WHERE p.id = $1
+	`
+
+	var mentorID uuid.UUID
+	err := r.db.QueryRowContext(ctx, query, paymentID).Scan(&mentorID)
+
+	return mentorID, err
+}
+
+// MarkPaid records a capture confirmed by the checkout signature. Failed
+// and expired payments can still be captured late; anything already paid
+// or refunded returns ErrPaymentSettled.
 func (r *PaymentRepository) MarkPaid(
 	ctx context.Context,
 	tx *sql.Tx,
@@ -115,17 +159,21 @@ func (r *PaymentRepository) MarkPaid(
 			gateway_signature = $3,
 			updated_at = now()
 		WHERE id = $1
+		  AND status IN ('created', 'failed', 'expired')
 	`
 
-	_, err := tx.ExecContext(
+	result, err := tx.ExecContext(
 		ctx,
 		query,
 		paymentID,
 		razorpayPaymentID,
 		signature,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return expectSettleable(result)
 }
 
 func (r *PaymentRepository) GetByGatewayOrderID(
@@ -137,6 +185,8 @@ func (r *PaymentRepository) GetByGatewayOrderID(
 		SELECT
 			id,
 			booking_id,
+			package_purchase_id,
+			series_id,
 			user_id,
 			gateway,
 			gateway_order_id,
@@ -144,6 +194,7 @@ func (r *PaymentRepository) GetByGatewayOrderID(
 			gateway_signature,
 			amount,
 			currency,
+			discount_cents,
 			status,
 			created_at,
 			updated_at
@@ -155,6 +206,8 @@ func (r *PaymentRepository) GetByGatewayOrderID(
 	err := r.db.QueryRowContext(ctx, query, orderID).Scan(
 		&p.ID,
 		&p.BookingID,
+		&p.PackagePurchaseID,
+		&p.SeriesID,
 		&p.UserID,
 		&p.Gateway,
 		&p.GatewayOrderID,
@@ -162,6 +215,7 @@ func (r *PaymentRepository) GetByGatewayOrderID(
 		&p.GatewaySignature,
 		&p.Amount,
 		&p.Currency,
+		&p.DiscountCents,
 		&p.Status,
 		&p.CreatedAt,
 		&p.UpdatedAt,
@@ -169,6 +223,7 @@ func (r *PaymentRepository) GetByGatewayOrderID(
 	return &p, err
 }
 
+// MarkPaidByGateway is MarkPaid for a capture reported by the gateway
 func (r *PaymentRepository) MarkPaidByGateway(
 	tx *sql.Tx,
 	paymentID uuid.UUID,
@@ -182,15 +237,19 @@ func (r *PaymentRepository) MarkPaidByGateway(
 			gateway_payment_id = $2,
 			updated_at = now()
 		WHERE id = $1
+		  AND status IN ('created', 'failed', 'expired')
 	`
 
-	_, err := tx.Exec(
+	result, err := tx.Exec(
 		query,
 		paymentID,
 		razorpayPaymentID,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return expectSettleable(result)
 }
 
 func (r *BookingRepository) MarkPaymentFailed(
@@ -221,8 +280,277 @@ func (r *PaymentRepository) MarkFailedByGateway(
 			status = 'failed',
 			updated_at = now()
 		WHERE id = $1
+		  AND status = 'created'
+	`
+
+	result, err := tx.Exec(query, paymentID)
+	if err != nil {
+		return err
+	}
+
+	return expectSettleable(result)
+}
+
+// expectSettleable maps a guarded status update that matched nothing to
+// ErrPaymentSettled
+func expectSettleable(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPaymentSettled
+	}
+
+	return nil
+}
+
+// ClaimForReconciliation returns up to `limit` payments of `gateway` that
+// are still created (or failed, or were expired unpaid) more than
+// staleAfter after they were opened, so their order can be checked with
+// the gateway.
+// Claiming stamps reconciled_at: a payment is checked again at most once
+// per recheckAfter, and is given up on after a week. SKIP LOCKED keeps
+// concurrent reconcilers from checking the same payment.
+func (r *PaymentRepository) ClaimForReconciliation(
+	ctx context.Context,
+	gateway string,
+	staleAfter time.Duration,
+	recheckAfter time.Duration,
+	limit int,
+) ([]*models.Payment, error) {
+
+	query := `
+		UPDATE payments
+		SET reconciled_at = now()
+		WHERE id IN (
+			SELECT id
+			FROM payments
+			WHERE gateway = $1
+			  AND status IN ('created', 'failed', 'expired')
+			  AND gateway_order_id IS NOT NULL
+			  AND created_at < now() - make_interval(secs => $2)
+			  AND created_at > now() - interval '7 days'
+			  AND (reconciled_at IS NULL OR reconciled_at < now() - make_interval(secs => $3))
+			ORDER BY created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id,
+			booking_id,
+			package_purchase_id,
+			series_id,
+			user_id,
+			gateway,
+			gateway_order_id,
+			gateway_payment_id,
+			gateway_signature,
+			amount,
+			currency,
+			discount_cents,
+			status,
+			created_at,
+			updated_at
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		gateway,
+		staleAfter.Seconds(),
+		recheckAfter.Seconds(),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*models.Payment
+
+	for rows.Next() {
+		var p models.Payment
+
+		if err := rows.Scan(
+			&p.ID,
+			&p.BookingID,
+			&p.PackagePurchaseID,
+			&p.SeriesID,
+			&p.UserID,
+			&p.Gateway,
+			&p.GatewayOrderID,
+			&p.GatewayPaymentID,
+			&p.GatewaySignature,
+			&p.Amount,
+			&p.Currency,
+			&p.DiscountCents,
+			&p.Status,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		payments = append(payments, &p)
+	}
+
+	return payments, rows.Err()
+}
+
+// GetRefundableByBookingID returns the captured payment for a booking, if
+// any - its own payment, or the payment of the series it belongs to
+func (r *PaymentRepository) GetRefundableByBookingID(
+	ctx context.Context,
+	bookingID uuid.UUID,
+) (*models.Payment, error) {
+
+	query := `
+		SELECT
+			id,
+			booking_id,
+			package_purchase_id,
+			series_id,
+			user_id,
+			gateway,
+			gateway_order_id,
+			gateway_payment_id,
+			gateway_signature,
+			amount,
+			currency,
+			discount_cents,
+			status,
+			created_at,
+			updated_at
+		FROM payments
+		WHERE (
+			booking_id = $1
+			OR series_id = (SELECT series_id FROM bookings WHERE id = $1)
+		)
+		  AND status IN ('paid', 'partially_refunded')
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var p models.Payment
+
+	err := r.db.QueryRowContext(ctx, query, bookingID).Scan(
+		&p.ID,
+		&p.BookingID,
+		&p.PackagePurchaseID,
+		&p.SeriesID,
+		&p.UserID,
+		&p.Gateway,
+		&p.GatewayOrderID,
+		&p.GatewayPaymentID,
+		&p.GatewaySignature,
+		&p.Amount,
+		&p.Currency,
+		&p.DiscountCents,
+		&p.Status,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("no captured payment for booking")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r *PaymentRepository) GetByGatewayPaymentID(
+	ctx context.Context,
+	gatewayPaymentID string,
+) (*models.Payment, error) {
+
+	query := `
+		SELECT
+			id,
+			booking_id,
+			package_purchase_id,
+			series_id,
+			user_id,
+			gateway,
+			gateway_order_id,
+			gateway_payment_id,
+			gateway_signature,
+			amount,
+			currency,
+			discount_cents,
+			status,
+			created_at,
+			updated_at
+		FROM payments
+		WHERE gateway_payment_id = $1
+	`
+
+	var p models.Payment
+
+	err := r.db.QueryRowContext(ctx, query, gatewayPaymentID).Scan(
+		&p.ID,
+		&p.BookingID,
+		&p.PackagePurchaseID,
+		&p.SeriesID,
+		&p.UserID,
+		&p.Gateway,
+		&p.GatewayOrderID,
+		&p.GatewayPaymentID,
+		&p.GatewaySignature,
+		&p.Amount,
+		&p.Currency,
+		&p.DiscountCents,
+		&p.Status,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// LockTx takes a row lock on the payment so concurrent refunds
+// can't exceed the captured amount
+func (r *PaymentRepository) LockTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	paymentID uuid.UUID,
+) error {
+
+	query := `
+		SELECT id
+		FROM payments
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var id uuid.UUID
+	return tx.QueryRowContext(ctx, query, paymentID).Scan(&id)
+}
+
+func (r *PaymentRepository) UpdateStatusTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	paymentID uuid.UUID,
+	status string,
+) error {
+
+	query := `
+		UPDATE payments
+		SET
+			status = $2,
+			updated_at = now()
+		WHERE id = $1
 	`
 
-	_, err := tx.Exec(query, paymentID)
+	_, err := tx.ExecContext(ctx, query, paymentID, status)
 	return err
 }
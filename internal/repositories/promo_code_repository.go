@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type PromoCodeRepository struct {
+	db *sql.DB
+}
+
+func NewPromoCodeRepository(db *sql.DB) *PromoCodeRepository {
+	return &PromoCodeRepository{db: db}
+}
+
+const promoCodeColumns = `
+	id,
+	code,
+	mentor_id,
+	discount_type,
+	percent_off,
+	amount_off_cents,
+	currency,
+	max_redemptions,
+	max_per_user,
+	redemption_count,
+	starts_at,
+	expires_at,
+	is_active,
+	created_by,
+	created_at,
+	updated_at
+`
+
+func (r *PromoCodeRepository) Create(
+	ctx context.Context,
+	p *models.PromoCode,
+) error {
+
+	const query = `
+	INSERT INTO promo_codes (
+		id,
+		code,
+		mentor_id,
+		discount_type,
+		percent_off,
+		amount_off_cents,
+		currency,
+		max_redemptions,
+		max_per_user,
+		redemption_count,
+		starts_at,
+		expires_at,
+		is_active,
+		created_by,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,0,$10,$11,$12,$13,NOW(),NOW())
+	RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		p.ID,
+		p.Code,
+		p.MentorID,
+		p.DiscountType,
+		p.PercentOff,
+		p.AmountOffCents,
+		p.Currency,
+		p.MaxRedemptions,
+		p.MaxPerUser,
+		p.StartsAt,
+		p.ExpiresAt,
+		p.IsActive,
+		p.CreatedBy,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return errors.New("promo code already exists")
+	}
+
+	return err
+}
+
+// ListByMentor returns the mentor's own codes, or the platform-wide
+// codes when mentorID is nil
+func (r *PromoCodeRepository) ListByMentor(
+	ctx context.Context,
+	mentorID *uuid.UUID,
+) ([]*models.PromoCode, error) {
+
+	query := `SELECT ` + promoCodeColumns + `
+	FROM promo_codes
+	WHERE mentor_id IS NOT DISTINCT FROM $1
+	ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []*models.PromoCode{}
+
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, p)
+	}
+
+	return codes, rows.Err()
+}
+
+// GetByCodeForUpdateTx loads a code by its (case-insensitive) text and
+// locks it, so concurrent bookings redeem it one at a time
+func (r *PromoCodeRepository) GetByCodeForUpdateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	code string,
+) (*models.PromoCode, error) {
+
+	query := `SELECT ` + promoCodeColumns + `
+	FROM promo_codes
+	WHERE code = UPPER($1)
+	FOR UPDATE
+	`
+
+	p, err := scanPromoCode(tx.QueryRowContext(ctx, query, code))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("invalid promo code")
+	}
+
+	return p, err
+}
+
+// CountUserRedemptionsTx counts the user's active redemptions of a code
+func (r *PromoCodeRepository) CountUserRedemptionsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	promoCodeID uuid.UUID,
+	userID uuid.UUID,
+) (int, error) {
+
+	const query = `
+	SELECT COUNT(*)
+	FROM promo_redemptions
+	WHERE promo_code_id = $1
+	  AND user_id = $2
+	  AND released_at IS NULL
+	`
+
+	var n int
+	err := tx.QueryRowContext(ctx, query, promoCodeID, userID).Scan(&n)
+	return n, err
+}
+
+// RedeemTx records a redemption and bumps the code's redemption count.
+// The caller must hold the code's row lock.
+func (r *PromoCodeRepository) RedeemTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	redemption *models.PromoRedemption,
+) error {
+
+	const insertRedemption = `
+	INSERT INTO promo_redemptions (
+		id,
+		promo_code_id,
+		booking_id,
+		user_id,
+		discount_cents,
+		created_at
+	)
+	VALUES ($1,$2,$3,$4,$5,NOW())
+	`
+
+	const bumpCount = `
+	UPDATE promo_codes
+	SET
+		redemption_count = redemption_count + 1,
+		updated_at = NOW()
+	WHERE id = $1
+	`
+
+	if _, err := tx.ExecContext(
+		ctx,
+		insertRedemption,
+		redemption.ID,
+		redemption.PromoCodeID,
+		redemption.BookingID,
+		redemption.UserID,
+		redemption.DiscountCents,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, bumpCount, redemption.PromoCodeID)
+	return err
+}
+
+// ReleaseByBookingTx releases the booking's redemption, if any, so the use
+// counts towards the code's limits again
+func (r *PromoCodeRepository) ReleaseByBookingTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+) error {
+
+	_, err := tx.ExecContext(ctx, releasePromoRedemptions, pq.Array([]string{bookingID.String()}))
+	return err
+}
+
+// releasePromoRedemptions releases the redemptions of every booking in
+// $1 (a uuid[]) and gives the uses back to their codes. Shared with the
+// booking reaper, which expires bookings in bulk.
+const releasePromoRedemptions = `
+	WITH released AS (
+		UPDATE promo_redemptions
+		SET released_at = NOW()
+		WHERE booking_id = ANY($1::uuid[])
+		  AND released_at IS NULL
+		RETURNING promo_code_id
+	)
+	UPDATE promo_codes p
+	SET
+		redemption_count = p.redemption_count - r.n,
+		updated_at = NOW()
+	FROM (
+		SELECT promo_code_id, COUNT(*) AS n
+		FROM released
+		GROUP BY promo_code_id
+	) r
+	WHERE p.id = r.promo_code_id
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPromoCode(row rowScanner) (*models.PromoCode, error) {
+	var p models.PromoCode
+
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.MentorID,
+		&p.DiscountType,
+		&p.PercentOff,
+		&p.AmountOffCents,
+		&p.Currency,
+		&p.MaxRedemptions,
+		&p.MaxPerUser,
+		&p.RedemptionCount,
+		&p.StartsAt,
+		&p.ExpiresAt,
+		&p.IsActive,
+		&p.CreatedBy,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
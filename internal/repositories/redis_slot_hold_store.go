@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisSlotHoldStore keeps each mentor's holds in one Redis hash, token ->
+// JSON, so a new hold can be checked against the others atomically in a
+// script. The hash expires with its last hold.
+type RedisSlotHoldStore struct {
+	client *redis.Client
+}
+
+func NewRedisSlotHoldStore(client *redis.Client) *RedisSlotHoldStore {
+	return &RedisSlotHoldStore{client: client}
+}
+
+type redisSlotHold struct {
+	ServiceID uuid.UUID `json:"service_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	StartsAt  int64     `json:"starts_at"`  // unix seconds
+	EndsAt    int64     `json:"ends_at"`    // unix seconds
+	ExpiresAt int64     `json:"expires_at"` // unix milliseconds
+}
+
+// createHoldScript drops expired holds, refuses one when another
+// learner's hold overlaps [from, to) and stores the new hold.
+// KEYS[1] mentor hash; ARGV token, hold JSON, from, to, now (ms), expires_at (ms), user_id
+var createHoldScript = redis.NewScript(`
+local now = tonumber(ARGV[5])
+local fields = redis.call('HGETALL', KEYS[1])
+
+for i = 1, #fields, 2 do
+	local h = cjson.decode(fields[i + 1])
+	if h.expires_at <= now then
+		redis.call('HDEL', KEYS[1], fields[i])
+	elseif h.user_id ~= ARGV[7] and h.starts_at < tonumber(ARGV[4]) and h.ends_at > tonumber(ARGV[3]) then
+		return 0
+	end
+end
+
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+
+local ttl = tonumber(ARGV[6]) - now
+if redis.call('PTTL', KEYS[1]) < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+
+return 1
+`)
+
+func (s *RedisSlotHoldStore) Create(
+	ctx context.Context,
+	hold *models.SlotHold,
+	buffer time.Duration,
+) error {
+
+	value, err := json.Marshal(redisSlotHold{
+		ServiceID: hold.ServiceID,
+		UserID:    hold.UserID,
+		StartsAt:  hold.StartsAt.Unix(),
+		EndsAt:    hold.EndsAt.Unix(),
+		ExpiresAt: hold.ExpiresAt.UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	created, err := createHoldScript.Run(
+		ctx,
+		s.client,
+		[]string{slotHoldKey(hold.MentorID)},
+		hold.Token,
+		value,
+		hold.StartsAt.Add(-buffer).Unix(),
+		hold.EndsAt.Add(buffer).Unix(),
+		time.Now().UnixMilli(),
+		hold.ExpiresAt.UnixMilli(),
+		hold.UserID.String(),
+	).Int()
+	if err != nil {
+		return err
+	}
+
+	if created == 0 {
+		return ErrSlotHeld
+	}
+
+	return nil
+}
+
+func (s *RedisSlotHoldStore) Get(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	token string,
+) (*models.SlotHold, error) {
+
+	value, err := s.client.HGet(ctx, slotHoldKey(mentorID), token).Result()
+	if err == redis.Nil {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := decodeRedisSlotHold(mentorID, token, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hold.ExpiresAt.After(time.Now()) {
+		return nil, ErrHoldNotFound
+	}
+
+	return hold, nil
+}
+
+func (s *RedisSlotHoldStore) Release(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	token string,
+) error {
+
+	return s.client.HDel(ctx, slotHoldKey(mentorID), token).Err()
+}
+
+func (s *RedisSlotHoldStore) FindBetween(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	from time.Time,
+	to time.Time,
+) ([]*models.SlotHold, error) {
+
+	values, err := s.client.HGetAll(ctx, slotHoldKey(mentorID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var holds []*models.SlotHold
+
+	for token, value := range values {
+		hold, err := decodeRedisSlotHold(mentorID, token, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if hold.ExpiresAt.After(now) && hold.StartsAt.Before(to) && hold.EndsAt.After(from) {
+			holds = append(holds, hold)
+		}
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].StartsAt.Before(holds[j].StartsAt)
+	})
+
+	return holds, nil
+}
+
+func slotHoldKey(mentorID uuid.UUID) string {
+	return "opencall:slot_holds:" + mentorID.String()
+}
+
+func decodeRedisSlotHold(
+	mentorID uuid.UUID,
+	token string,
+	value string,
+) (*models.SlotHold, error) {
+
+	var h redisSlotHold
+	if err := json.Unmarshal([]byte(value), &h); err != nil {
+		return nil, err
+	}
+
+	return &models.SlotHold{
+		Token:     token,
+		MentorID:  mentorID,
+		ServiceID: h.ServiceID,
+		UserID:    h.UserID,
+		StartsAt:  time.Unix(h.StartsAt, 0).UTC(),
+		EndsAt:    time.Unix(h.EndsAt, 0).UTC(),
+		ExpiresAt: time.UnixMilli(h.ExpiresAt).UTC(),
+	}, nil
+}
@@ -0,0 +1,305 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type RefundRepository struct {
+	db *sql.DB
+}
+
+func NewRefundRepository(db *sql.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+func (r *RefundRepository) CreateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	refund *models.Refund,
+) error {
+
+	const query = `
+	INSERT INTO refunds (
+		id,
+		payment_id,
+		booking_id,
+		gateway_refund_id,
+		amount,
+		currency,
+		status,
+		reason,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NOW(),NOW())
+	`
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		refund.ID,
+		refund.PaymentID,
+		refund.BookingID,
+		refund.GatewayRefundID,
+		refund.Amount,
+		refund.Currency,
+		refund.Status,
+		refund.Reason,
+	)
+
+	return err
+}
+
+// SumOutstandingTx returns how much of a payment is already refunded or
+// on its way to being refunded. Failed refunds don't count.
+func (r *RefundRepository) SumOutstandingTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	paymentID uuid.UUID,
+) (int64, error) {
+
+	const query = `
+	SELECT COALESCE(SUM(amount), 0)
+	FROM refunds
+	WHERE payment_id = $1
+	  AND status IN ('pending', 'processed')
+	`
+
+	var total int64
+	err := tx.QueryRowContext(ctx, query, paymentID).Scan(&total)
+	return total, err
+}
+
+// SumProcessedTx returns how much of a payment the gateway has actually refunded
+func (r *RefundRepository) SumProcessedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	paymentID uuid.UUID,
+) (int64, error) {
+
+	const query = `
+	SELECT COALESCE(SUM(amount), 0)
+	FROM refunds
+	WHERE payment_id = $1
+	  AND status = 'processed'
+	`
+
+	var total int64
+	err := tx.QueryRowContext(ctx, query, paymentID).Scan(&total)
+	return total, err
+}
+
+// MarkSubmitted records the gateway's ID for a refund it accepted, unless
+// its webhook got there first
+func (r *RefundRepository) MarkSubmitted(
+	ctx context.Context,
+	refundID uuid.UUID,
+	gatewayRefundID string,
+) error {
+
+	const query = `
+	UPDATE refunds
+	SET
+		gateway_refund_id = $2,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND gateway_refund_id IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, refundID, gatewayRefundID)
+	return err
+}
+
+func (r *RefundRepository) MarkFailed(
+	ctx context.Context,
+	refundID uuid.UUID,
+	failureReason string,
+) error {
+
+	const query = `
+	UPDATE refunds
+	SET
+		status = $2,
+		failure_reason = $3,
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status <> $4
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		refundID,
+		models.RefundStatusFailed,
+		failureReason,
+		models.RefundStatusProcessed,
+	)
+	return err
+}
+
+func (r *RefundRepository) MarkProcessedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	refundID uuid.UUID,
+) error {
+
+	const query = `
+	UPDATE refunds
+	SET
+		status = $2,
+		failure_reason = NULL,
+		updated_at = NOW()
+	WHERE id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, refundID, models.RefundStatusProcessed)
+	return err
+}
+
+const refundColumns = `
+	id,
+	payment_id,
+	booking_id,
+	gateway_refund_id,
+	amount,
+	currency,
+	status,
+	reason,
+	failure_reason,
+	created_at,
+	updated_at
+`
+
+// GetByGatewayRefundIDTx loads and locks a refund by the gateway's refund ID.
+// Returns sql.ErrNoRows for refunds we didn't initiate (e.g. from the
+// dashboard) and for ours whose gateway ID isn't recorded yet.
+func (r *RefundRepository) GetByGatewayRefundIDTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	gatewayRefundID string,
+) (*models.Refund, error) {
+
+	query := `SELECT ` + refundColumns + `
+	FROM refunds
+	WHERE gateway_refund_id = $1
+	FOR UPDATE
+	`
+
+	return scanRefund(tx.QueryRowContext(ctx, query, gatewayRefundID))
+}
+
+// GetByGatewayRefundID is GetByGatewayRefundIDTx without the lock
+func (r *RefundRepository) GetByGatewayRefundID(
+	ctx context.Context,
+	gatewayRefundID string,
+) (*models.Refund, error) {
+
+	query := `SELECT ` + refundColumns + `
+	FROM refunds
+	WHERE gateway_refund_id = $1
+	`
+
+	return scanRefund(r.db.QueryRowContext(ctx, query, gatewayRefundID))
+}
+
+// GetUnsubmitted loads a pending refund the gateway hasn't acknowledged
+// yet - its gateway refund ID isn't recorded. Returns sql.ErrNoRows
+// otherwise.
+func (r *RefundRepository) GetUnsubmitted(
+	ctx context.Context,
+	id uuid.UUID,
+) (*models.Refund, error) {
+
+	query := `SELECT ` + refundColumns + `
+	FROM refunds
+	WHERE id = $1
+	  AND gateway_refund_id IS NULL
+	  AND status = 'pending'
+	`
+
+	return scanRefund(r.db.QueryRowContext(ctx, query, id))
+}
+
+// ListUnsubmittedTx loads and locks the payment's pending refunds whose
+// gateway refund ID isn't recorded yet, oldest first
+func (r *RefundRepository) ListUnsubmittedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	paymentID uuid.UUID,
+) ([]*models.Refund, error) {
+
+	query := `SELECT ` + refundColumns + `
+	FROM refunds
+	WHERE payment_id = $1
+	  AND gateway_refund_id IS NULL
+	  AND status = 'pending'
+	ORDER BY created_at
+	FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []*models.Refund
+
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+// AttachGatewayRefundIDTx records the gateway's ID for one of our refunds
+func (r *RefundRepository) AttachGatewayRefundIDTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	refundID uuid.UUID,
+	gatewayRefundID string,
+) error {
+
+	const query = `
+	UPDATE refunds
+	SET
+		gateway_refund_id = $2,
+		updated_at = NOW()
+	WHERE id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, refundID, gatewayRefundID)
+	return err
+}
+
+func scanRefund(row rowScanner) (*models.Refund, error) {
+	var refund models.Refund
+
+	err := row.Scan(
+		&refund.ID,
+		&refund.PaymentID,
+		&refund.BookingID,
+		&refund.GatewayRefundID,
+		&refund.Amount,
+		&refund.Currency,
+		&refund.Status,
+		&refund.Reason,
+		&refund.FailureReason,
+		&refund.CreatedAt,
+		&refund.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+// ErrAlreadyReviewed is returned when the booking already has a review
+var ErrAlreadyReviewed = errors.New("booking already reviewed")
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (r *ReviewRepository) Create(
+	ctx context.Context,
+	review *models.Review,
+) error {
+
+	const query = `
+	INSERT INTO reviews (
+		id,
+		booking_id,
+		mentor_id,
+		user_id,
+		rating,
+		comment,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,NOW(),NOW())
+	RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		review.ID,
+		review.BookingID,
+		review.MentorID,
+		review.UserID,
+		review.Rating,
+		review.Comment,
+	).Scan(&review.CreatedAt, &review.UpdatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrAlreadyReviewed
+	}
+
+	return err
+}
+
+// ListByMentor returns the mentor's latest `limit` reviews with their
+// reviewers' usernames
+func (r *ReviewRepository) ListByMentor(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	limit int,
+) ([]*models.Review, error) {
+
+	const query = `
+	SELECT
+		rv.id,
+		rv.booking_id,
+		rv.mentor_id,
+		rv.user_id,
+		rv.rating,
+		rv.comment,
+		u.username,
+		rv.created_at,
+		rv.updated_at
+	FROM reviews rv
+	JOIN users u ON u.id = rv.user_id
+	WHERE rv.mentor_id = $1
+	ORDER BY rv.created_at DESC
+	LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*models.Review
+
+	for rows.Next() {
+		var rv models.Review
+
+		if err := rows.Scan(
+			&rv.ID,
+			&rv.BookingID,
+			&rv.MentorID,
+			&rv.UserID,
+			&rv.Rating,
+			&rv.Comment,
+			&rv.Username,
+			&rv.CreatedAt,
+			&rv.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, &rv)
+	}
+
+	return reviews, rows.Err()
+}
+
+// SummaryForMentor returns the mentor's average rating and review count
+func (r *ReviewRepository) SummaryForMentor(
+	ctx context.Context,
+	mentorID uuid.UUID,
+) (float64, int, error) {
+
+	const query = `
+	SELECT
+		COALESCE(AVG(rating), 0),
+		COUNT(*)
+	FROM reviews
+	WHERE mentor_id = $1
+	`
+
+	var (
+		average float64
+		count   int
+	)
+	err := r.db.QueryRowContext(ctx, query, mentorID).Scan(&average, &count)
+
+	return average, count, err
+}
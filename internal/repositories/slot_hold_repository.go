@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+// PostgresSlotHoldStore keeps slot holds in the slot_holds table
+type PostgresSlotHoldStore struct {
+	db *sql.DB
+}
+
+func NewPostgresSlotHoldStore(db *sql.DB) *PostgresSlotHoldStore {
+	return &PostgresSlotHoldStore{db: db}
+}
+
+// Create takes a per-mentor advisory lock so two overlapping holds can't
+// be taken at once. It can't lock the mentor's profile row: placeHold
+// already holds that lock, on another connection, while it calls Create.
+func (s *PostgresSlotHoldStore) Create(
+	ctx context.Context,
+	hold *models.SlotHold,
+	buffer time.Duration,
+) error {
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`SELECT pg_advisory_xact_lock(hashtextextended('slot_holds:' || $1::text, 0))`,
+		hold.MentorID,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM slot_holds WHERE mentor_id = $1 AND expires_at <= NOW()`,
+		hold.MentorID,
+	); err != nil {
+		return err
+	}
+
+	var held bool
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (
+			SELECT 1
+			FROM slot_holds
+			WHERE mentor_id = $1
+			  AND user_id <> $4
+			  AND starts_at < $3
+			  AND ends_at > $2
+		)`,
+		hold.MentorID,
+		hold.StartsAt.Add(-buffer),
+		hold.EndsAt.Add(buffer),
+		hold.UserID,
+	).Scan(&held); err != nil {
+		return err
+	}
+
+	if held {
+		return ErrSlotHeld
+	}
+
+	const insert = `
+	INSERT INTO slot_holds (
+		token,
+		mentor_id,
+		service_id,
+		user_id,
+		starts_at,
+		ends_at,
+		expires_at,
+		created_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,NOW())
+	`
+
+	if _, err := tx.ExecContext(
+		ctx,
+		insert,
+		hold.Token,
+		hold.MentorID,
+		hold.ServiceID,
+		hold.UserID,
+		hold.StartsAt,
+		hold.EndsAt,
+		hold.ExpiresAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresSlotHoldStore) Get(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	token string,
+) (*models.SlotHold, error) {
+
+	const query = `
+	SELECT token, mentor_id, service_id, user_id, starts_at, ends_at, expires_at
+	FROM slot_holds
+	WHERE token = $1
+	  AND mentor_id = $2
+	  AND expires_at > NOW()
+	`
+
+	hold, err := scanSlotHold(s.db.QueryRowContext(ctx, query, token, mentorID))
+	if err == sql.ErrNoRows {
+		return nil, ErrHoldNotFound
+	}
+
+	return hold, err
+}
+
+func (s *PostgresSlotHoldStore) Release(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	token string,
+) error {
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM slot_holds WHERE token = $1 AND mentor_id = $2`,
+		token,
+		mentorID,
+	)
+
+	return err
+}
+
+func (s *PostgresSlotHoldStore) FindBetween(
+	ctx context.Context,
+	mentorID uuid.UUID,
+	from time.Time,
+	to time.Time,
+) ([]*models.SlotHold, error) {
+
+	const query = `
+	SELECT token, mentor_id, service_id, user_id, starts_at, ends_at, expires_at
+	FROM slot_holds
+	WHERE mentor_id = $1
+	  AND starts_at < $3
+	  AND ends_at > $2
+	  AND expires_at > NOW()
+	ORDER BY starts_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, mentorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*models.SlotHold
+
+	for rows.Next() {
+		hold, err := scanSlotHold(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+func scanSlotHold(row rowScanner) (*models.SlotHold, error) {
+	var h models.SlotHold
+
+	if err := row.Scan(
+		&h.Token,
+		&h.MentorID,
+		&h.ServiceID,
+		&h.UserID,
+		&h.StartsAt,
+		&h.EndsAt,
+		&h.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &h, nil
+}
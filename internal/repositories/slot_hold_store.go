@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+var (
+	// ErrSlotHeld is returned when a new hold comes too close to another
+	// learner's live hold
+	ErrSlotHeld = errors.New("slot is held by another learner")
+
+	// ErrHoldNotFound is returned for unknown and expired holds
+	ErrHoldNotFound = errors.New("hold not found or expired")
+)
+
+// SlotHoldStore keeps short-lived slot holds. Expired holds must never
+// be returned; stores drop them lazily.
+type SlotHoldStore interface {
+	// Create stores the hold, or returns ErrSlotHeld when another
+	// learner's live hold of the same mentor is within buffer of it. The
+	// check and the write are atomic. The learner's own holds are left
+	// for the caller to replace.
+	Create(ctx context.Context, hold *models.SlotHold, buffer time.Duration) error
+
+	Get(ctx context.Context, mentorID uuid.UUID, token string) (*models.SlotHold, error)
+
+	// Release drops the hold; releasing an unknown hold is not an error
+	Release(ctx context.Context, mentorID uuid.UUID, token string) error
+
+	// FindBetween returns the mentor's live holds overlapping [from, to)
+	FindBetween(ctx context.Context, mentorID uuid.UUID, from time.Time, to time.Time) ([]*models.SlotHold, error)
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/preetsinghmakkar/OpenCall/internal/models"
 )
 
+// ErrVideoSessionNotFound is returned when a booking's call was never opened
+var ErrVideoSessionNotFound = errors.New("video session not found")
+
 type VideoSessionRepository struct {
 	db *sql.DB
 }
@@ -87,7 +91,7 @@ func (r *VideoSessionRepository) GetByBookingID(ctx context.Context, bookingID u
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("video session not found")
+		return nil, ErrVideoSessionNotFound
 	}
 
 	if err != nil {
@@ -109,11 +113,11 @@ func (r *VideoSessionRepository) UpdateStatus(ctx context.Context, bookingID uui
 	return err
 }
 
-// Record when mentor joined
+// Record when mentor first joined; reconnects keep the first join
 func (r *VideoSessionRepository) RecordMentorJoined(ctx context.Context, bookingID uuid.UUID) error {
 	const query = `
 	UPDATE video_sessions
-	SET mentor_joined_at = NOW(), updated_at = NOW()
+	SET mentor_joined_at = COALESCE(mentor_joined_at, NOW()), updated_at = NOW()
 	WHERE booking_id = $1
 	`
 
@@ -121,11 +125,11 @@ func (r *VideoSessionRepository) RecordMentorJoined(ctx context.Context, booking
 	return err
 }
 
-// Record when user joined
+// Record when user first joined; reconnects keep the first join
 func (r *VideoSessionRepository) RecordUserJoined(ctx context.Context, bookingID uuid.UUID) error {
 	const query = `
 	UPDATE video_sessions
-	SET user_joined_at = NOW(), updated_at = NOW()
+	SET user_joined_at = COALESCE(user_joined_at, NOW()), updated_at = NOW()
 	WHERE booking_id = $1
 	`
 
@@ -187,3 +191,30 @@ func (r *VideoSessionRepository) MarkActive(ctx context.Context, bookingID uuid.
 	_, err := r.db.ExecContext(ctx, query, models.VideoSessionStatusActive, bookingID, models.VideoSessionStatusWaiting)
 	return err
 }
+
+// MentorAttendanceForGroup returns when the mentor first joined and last
+// left the group session of serviceID starting at startsAt, across its
+// seats. The leave time is nil while the mentor is still in one.
+func (r *VideoSessionRepository) MentorAttendanceForGroup(
+	ctx context.Context,
+	serviceID uuid.UUID,
+	startsAt time.Time,
+) (*time.Time, *time.Time, error) {
+	const query = `
+	SELECT
+		MIN(vs.mentor_joined_at),
+		CASE
+			WHEN bool_or(vs.mentor_joined_at IS NOT NULL AND vs.mentor_left_at IS NULL) THEN NULL
+			ELSE MAX(vs.mentor_left_at)
+		END
+	FROM video_sessions vs
+	JOIN bookings b ON b.id = vs.booking_id
+	WHERE b.service_id = $1
+	  AND b.starts_at = $2
+	`
+
+	var joined, left *time.Time
+	err := r.db.QueryRowContext(ctx, query, serviceID, startsAt).Scan(&joined, &left)
+
+	return joined, left, err
+}
@@ -0,0 +1,552 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+// ErrAlreadyWaitlisted is returned when the learner already waits for
+// the same mentor and service
+var ErrAlreadyWaitlisted = errors.New("already on this waitlist")
+
+type WaitlistRepository struct {
+	db *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+const waitlistEntryColumns = `
+	id,
+	mentor_id,
+	user_id,
+	service_id,
+	preferred_days,
+	preferred_from,
+	preferred_to,
+	timezone,
+	status,
+	created_at,
+	updated_at
+`
+
+const waitlistOfferColumns = `
+	id,
+	entry_id,
+	service_id,
+	starts_at,
+	ends_at,
+	status,
+	expires_at,
+	created_at,
+	updated_at
+`
+
+// Create adds the entry at the end of the mentor's line. A learner has
+// at most one waiting entry per mentor and service.
+func (r *WaitlistRepository) Create(
+	ctx context.Context,
+	e *models.WaitlistEntry,
+) error {
+
+	const query = `
+	INSERT INTO waitlist_entries (
+		id,
+		mentor_id,
+		user_id,
+		service_id,
+		preferred_days,
+		preferred_from,
+		preferred_to,
+		timezone,
+		status,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NOW(),NOW())
+	RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		e.ID,
+		e.MentorID,
+		e.UserID,
+		e.ServiceID,
+		pq.Array(e.PreferredDays),
+		e.PreferredFrom,
+		e.PreferredTo,
+		e.Timezone,
+		e.Status,
+	).Scan(&e.CreatedAt, &e.UpdatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrAlreadyWaitlisted
+	}
+
+	return err
+}
+
+func (r *WaitlistRepository) GetByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*models.WaitlistEntry, error) {
+
+	query := `SELECT ` + waitlistEntryColumns + ` FROM waitlist_entries WHERE id = $1`
+
+	e, err := scanWaitlistEntry(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("waitlist entry not found")
+	}
+
+	return e, err
+}
+
+// ListByUser returns the learner's entries, newest first, with the
+// place of each waiting entry in its mentor's line
+func (r *WaitlistRepository) ListByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*models.WaitlistEntry, error) {
+
+	query := `
+	SELECT ` + waitlistEntryColumns + `,
+		CASE WHEN w.status = 'waiting' THEN (
+			SELECT COUNT(*)
+			FROM waitlist_entries o
+			WHERE o.mentor_id = w.mentor_id
+			  AND o.status = 'waiting'
+			  AND o.created_at <= w.created_at
+		) ELSE 0 END
+	FROM waitlist_entries w
+	WHERE w.user_id = $1
+	ORDER BY w.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.WaitlistEntry
+
+	for rows.Next() {
+		var e models.WaitlistEntry
+
+		if err := rows.Scan(
+			&e.ID,
+			&e.MentorID,
+			&e.UserID,
+			&e.ServiceID,
+			pq.Array(&e.PreferredDays),
+			&e.PreferredFrom,
+			&e.PreferredTo,
+			&e.Timezone,
+			&e.Status,
+			&e.CreatedAt,
+			&e.UpdatedAt,
+			&e.Position,
+		); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListWaiting returns the mentor's waiting entries in line order
+func (r *WaitlistRepository) ListWaiting(
+	ctx context.Context,
+	mentorID uuid.UUID,
+) ([]*models.WaitlistEntry, error) {
+
+	query := `
+	SELECT ` + waitlistEntryColumns + `
+	FROM waitlist_entries
+	WHERE mentor_id = $1
+	  AND status = 'waiting'
+	ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mentorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.WaitlistEntry
+
+	for rows.Next() {
+		e, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// MentorsWaiting lists the mentors with at least one waiting entry
+func (r *WaitlistRepository) MentorsWaiting(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT DISTINCT mentor_id FROM waitlist_entries WHERE status = 'waiting'`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Cancel takes the learner's waiting entry out of line and withdraws its
+// open offers
+func (r *WaitlistRepository) Cancel(
+	ctx context.Context,
+	userID uuid.UUID,
+	entryID uuid.UUID,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`UPDATE waitlist_entries
+		SET status = 'cancelled', updated_at = NOW()
+		WHERE id = $1
+		  AND user_id = $2
+		  AND status = 'waiting'`,
+		entryID,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := expectOneRow(result, "waitlist entry not found"); err != nil {
+		return err
+	}
+
+	if err := withdrawOffersTx(ctx, tx, entryID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateOffer offers a slot to a waiting entry. An earlier offer of the
+// same slot is re-opened when the slot went to someone else and has come
+// back (taken), or when the learner let it expire more than reofferAfter
+// ago. It reports false when the entry's earlier offer still stands.
+func (r *WaitlistRepository) CreateOffer(
+	ctx context.Context,
+	o *models.WaitlistOffer,
+	reofferAfter time.Duration,
+) (bool, error) {
+
+	const query = `
+	INSERT INTO waitlist_offers (
+		id,
+		entry_id,
+		service_id,
+		starts_at,
+		ends_at,
+		status,
+		expires_at,
+		created_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,NOW(),NOW())
+	ON CONFLICT (entry_id, starts_at) DO UPDATE
+	SET
+		service_id = EXCLUDED.service_id,
+		ends_at = EXCLUDED.ends_at,
+		status = EXCLUDED.status,
+		expires_at = EXCLUDED.expires_at,
+		updated_at = NOW()
+	WHERE waitlist_offers.status = 'taken'
+	   OR (
+		waitlist_offers.status = 'expired'
+		AND waitlist_offers.updated_at < NOW() - make_interval(secs => $8)
+	   )
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		o.ID,
+		o.EntryID,
+		o.ServiceID,
+		o.StartsAt,
+		o.EndsAt,
+		o.Status,
+		o.ExpiresAt,
+		reofferAfter.Seconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+
+	return rows == 1, err
+}
+
+// CountOpenOffers counts the entry's offers that can still be accepted
+func (r *WaitlistRepository) CountOpenOffers(
+	ctx context.Context,
+	entryID uuid.UUID,
+) (int, error) {
+
+	const query = `
+	SELECT COUNT(*)
+	FROM waitlist_offers
+	WHERE entry_id = $1
+	  AND status = 'open'
+	  AND expires_at > NOW()
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, entryID).Scan(&count)
+
+	return count, err
+}
+
+// ListOpenOffersByUser returns the learner's offers that can still be
+// accepted, soonest slot first
+func (r *WaitlistRepository) ListOpenOffersByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*models.WaitlistOffer, error) {
+
+	const query = `
+	SELECT
+		o.id,
+		o.entry_id,
+		o.service_id,
+		o.starts_at,
+		o.ends_at,
+		o.status,
+		o.expires_at,
+		o.created_at,
+		o.updated_at
+	FROM waitlist_offers o
+	JOIN waitlist_entries e ON e.id = o.entry_id
+	WHERE e.user_id = $1
+	  AND o.status = 'open'
+	  AND o.expires_at > NOW()
+	ORDER BY o.starts_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offers []*models.WaitlistOffer
+
+	for rows.Next() {
+		o, err := scanWaitlistOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		offers = append(offers, o)
+	}
+
+	return offers, rows.Err()
+}
+
+func (r *WaitlistRepository) GetOffer(
+	ctx context.Context,
+	id uuid.UUID,
+) (*models.WaitlistOffer, error) {
+
+	query := `SELECT ` + waitlistOfferColumns + ` FROM waitlist_offers WHERE id = $1`
+
+	o, err := scanWaitlistOffer(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("waitlist offer not found")
+	}
+
+	return o, err
+}
+
+// AcceptOffer records that the learner took the offered slot: their entry
+// is fulfilled, its other offers withdrawn, and the other learners'
+// open offers for overlapping slots of the mentor are marked taken
+func (r *WaitlistRepository) AcceptOffer(
+	ctx context.Context,
+	offer *models.WaitlistOffer,
+	mentorID uuid.UUID,
+) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`UPDATE waitlist_offers
+		SET status = 'accepted', updated_at = NOW()
+		WHERE id = $1
+		  AND status = 'open'`,
+		offer.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := expectOneRow(result, "waitlist offer is no longer open"); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE waitlist_entries
+		SET status = 'fulfilled', updated_at = NOW()
+		WHERE id = $1`,
+		offer.EntryID,
+	); err != nil {
+		return err
+	}
+
+	if err := withdrawOffersTx(ctx, tx, offer.EntryID); err != nil {
+		return err
+	}
+
+	const taken = `
+	UPDATE waitlist_offers o
+	SET status = 'taken', updated_at = NOW()
+	FROM waitlist_entries e
+	WHERE e.id = o.entry_id
+	  AND e.mentor_id = $1
+	  AND o.status = 'open'
+	  AND o.starts_at < $3
+	  AND o.ends_at > $2
+	`
+
+	if _, err := tx.ExecContext(ctx, taken, mentorID, offer.StartsAt, offer.EndsAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkOfferTaken closes an offer whose slot went to someone else
+func (r *WaitlistRepository) MarkOfferTaken(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE waitlist_offers
+		SET status = 'taken', updated_at = NOW()
+		WHERE id = $1
+		  AND status = 'open'`,
+		id,
+	)
+
+	return err
+}
+
+// ExpireOffers closes the open offers nobody accepted in time
+func (r *WaitlistRepository) ExpireOffers(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE waitlist_offers
+		SET status = 'expired', updated_at = NOW()
+		WHERE status = 'open'
+		  AND expires_at <= NOW()`,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func withdrawOffersTx(ctx context.Context, tx *sql.Tx, entryID uuid.UUID) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`UPDATE waitlist_offers
+		SET status = 'withdrawn', updated_at = NOW()
+		WHERE entry_id = $1
+		  AND status = 'open'`,
+		entryID,
+	)
+
+	return err
+}
+
+func scanWaitlistEntry(row rowScanner) (*models.WaitlistEntry, error) {
+	var e models.WaitlistEntry
+
+	if err := row.Scan(
+		&e.ID,
+		&e.MentorID,
+		&e.UserID,
+		&e.ServiceID,
+		pq.Array(&e.PreferredDays),
+		&e.PreferredFrom,
+		&e.PreferredTo,
+		&e.Timezone,
+		&e.Status,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func scanWaitlistOffer(row rowScanner) (*models.WaitlistOffer, error) {
+	var o models.WaitlistOffer
+
+	if err := row.Scan(
+		&o.ID,
+		&o.EntryID,
+		&o.ServiceID,
+		&o.StartsAt,
+		&o.EndsAt,
+		&o.Status,
+		&o.ExpiresAt,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+type WebhookEventRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookEventRepository(db *sql.DB) *WebhookEventRepository {
+	return &WebhookEventRepository{db: db}
+}
+
+const webhookEventColumns = `
+	id,
+	gateway,
+	event_id,
+	event_type,
+	payload,
+	event,
+	status,
+	attempts,
+	last_error,
+	next_attempt_at,
+	received_at,
+	processed_at,
+	updated_at
+`
+
+// Insert stores a received event for processing. If the gateway already
+// delivered an event with the same ID nothing is written and false is
+// returned.
+func (r *WebhookEventRepository) Insert(
+	ctx context.Context,
+	e *models.WebhookEvent,
+) (bool, error) {
+
+	const query = `
+	INSERT INTO webhook_events (
+		id,
+		gateway,
+		event_id,
+		event_type,
+		payload,
+		event,
+		status,
+		attempts,
+		next_attempt_at,
+		received_at,
+		updated_at
+	)
+	VALUES ($1,$2,$3,$4,$5,$6,'pending',0,NOW(),NOW(),NOW())
+	ON CONFLICT (gateway, event_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		e.ID,
+		e.Gateway,
+		e.EventID,
+		e.EventType,
+		e.Payload,
+		e.Event,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// ClaimDue marks up to `limit` due events as processing and returns them,
+// counting the attempt. Events left processing for longer than staleAfter
+// (the worker died mid-event) are claimed again. SKIP LOCKED lets several
+// server instances claim concurrently without handing out the same event.
+func (r *WebhookEventRepository) ClaimDue(
+	ctx context.Context,
+	limit int,
+	staleAfter time.Duration,
+) ([]*models.WebhookEvent, error) {
+
+	query := `
+	UPDATE webhook_events
+	SET
+		status = 'processing',
+		attempts = attempts + 1,
+		updated_at = NOW()
+	WHERE id IN (
+		SELECT id
+		FROM webhook_events
+		WHERE (status = 'pending' AND next_attempt_at <= NOW())
+		   OR (status = 'processing' AND updated_at < NOW() - make_interval(secs => $2))
+		ORDER BY next_attempt_at ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + webhookEventColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limit, staleAfter.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookEvents(rows)
+}
+
+func (r *WebhookEventRepository) MarkProcessed(
+	ctx context.Context,
+	id uuid.UUID,
+) error {
+
+	const query = `
+	UPDATE webhook_events
+	SET
+		status = 'processed',
+		last_error = NULL,
+		processed_at = NOW(),
+		updated_at = NOW()
+	WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkRetry puts a failed event back in the queue until nextAttemptAt
+func (r *WebhookEventRepository) MarkRetry(
+	ctx context.Context,
+	id uuid.UUID,
+	nextAttemptAt time.Time,
+	lastError string,
+) error {
+
+	const query = `
+	UPDATE webhook_events
+	SET
+		status = 'pending',
+		last_error = $2,
+		next_attempt_at = $3,
+		updated_at = NOW()
+	WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, lastError, nextAttemptAt)
+	return err
+}
+
+// MarkFailed parks an event that ran out of attempts until it is replayed
+func (r *WebhookEventRepository) MarkFailed(
+	ctx context.Context,
+	id uuid.UUID,
+	lastError string,
+) error {
+
+	const query = `
+	UPDATE webhook_events
+	SET
+		status = 'failed',
+		last_error = $2,
+		updated_at = NOW()
+	WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, lastError)
+	return err
+}
+
+// List returns the newest events, optionally only those with `status`
+func (r *WebhookEventRepository) List(
+	ctx context.Context,
+	status string,
+	limit int,
+) ([]*models.WebhookEvent, error) {
+
+	query := `SELECT ` + webhookEventColumns + `
+	FROM webhook_events
+	WHERE ($1 = '' OR status = $1)
+	ORDER BY received_at DESC
+	LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookEvents(rows)
+}
+
+// Replay queues a failed event again with a fresh set of attempts
+func (r *WebhookEventRepository) Replay(
+	ctx context.Context,
+	id uuid.UUID,
+) (*models.WebhookEvent, error) {
+
+	query := `
+	UPDATE webhook_events
+	SET
+		status = 'pending',
+		attempts = 0,
+		next_attempt_at = NOW(),
+		updated_at = NOW()
+	WHERE id = $1
+	  AND status = 'failed'
+	RETURNING ` + webhookEventColumns
+
+	e, err := scanWebhookEvent(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("failed webhook event not found")
+	}
+
+	return e, err
+}
+
+func scanWebhookEvents(rows *sql.Rows) ([]*models.WebhookEvent, error) {
+	var events []*models.WebhookEvent
+
+	for rows.Next() {
+		e, err := scanWebhookEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+func scanWebhookEvent(row rowScanner) (*models.WebhookEvent, error) {
+	var e models.WebhookEvent
+
+	err := row.Scan(
+		&e.ID,
+		&e.Gateway,
+		&e.EventID,
+		&e.EventType,
+		&e.Payload,
+		&e.Event,
+		&e.Status,
+		&e.Attempts,
+		&e.LastError,
+		&e.NextAttemptAt,
+		&e.ReceivedAt,
+		&e.ProcessedAt,
+		&e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
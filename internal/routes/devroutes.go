@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/preetsinghmakkar/OpenCall/internal/handlers"
+)
+
+// RegisterFakeGatewayEndpoints exposes the fake gateway's checkout
+// simulation. Only call this when the fake gateway is configured.
+func RegisterFakeGatewayEndpoints(
+	router *gin.Engine,
+	fakeGatewayHandler *handlers.FakeGatewayHandler,
+) {
+	dev := router.Group("/api/dev/fake-gateway")
+
+	dev.POST("/orders/:order_id/capture", fakeGatewayHandler.Capture)
+	dev.POST("/orders/:order_id/fail", fakeGatewayHandler.Fail)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/preetsinghmakkar/OpenCall/internal/constants"
 	"github.com/preetsinghmakkar/OpenCall/internal/handlers"
 	"github.com/preetsinghmakkar/OpenCall/internal/middlewares"
 )
@@ -14,6 +15,16 @@ func RegisterProtectedEndpoints(
 	mentorAvailabilityHandler *handlers.MentorAvailabilityHandler,
 	bookingHandler *handlers.BookingHandler,
 	paymentHandler *handlers.PaymentHandler,
+	earningsHandler *handlers.EarningsHandler,
+	promoCodeHandler *handlers.PromoCodeHandler,
+	packageHandler *handlers.PackageHandler,
+	receiptHandler *handlers.ReceiptHandler,
+	webhookHandler *handlers.WebhookHandler,
+	reconciliationHandler *handlers.ReconciliationHandler,
+	calendarHandler *handlers.CalendarHandler,
+	externalCalendarHandler *handlers.ExternalCalendarHandler,
+	waitlistHandler *handlers.WaitlistHandler,
+	reviewHandler *handlers.ReviewHandler,
 	webSocketHandler *handlers.WebSocketHandler,
 	jwtSecret string,
 ) {
@@ -21,14 +32,62 @@ func RegisterProtectedEndpoints(
 	protected.Use(middlewares.AuthMiddleware(jwtSecret))
 
 	protected.POST("/mentor/profile", mentorHandler.CreateProfile)
+	protected.GET("/mentor/booking-settings", mentorHandler.GetBookingSettings)
+	protected.PUT("/mentor/booking-settings", mentorHandler.UpdateBookingSettings)
 	protected.POST("/mentor/services", mentorServiceHandler.Create)
 	protected.POST("/mentor/availability", mentorAvailabilityHandler.Create)
+	protected.PUT("/mentor/availability", mentorAvailabilityHandler.ReplaceWeek)
+	protected.POST("/mentor/availability/overrides", mentorAvailabilityHandler.CreateOverride)
+	protected.GET("/mentor/availability/overrides", mentorAvailabilityHandler.ListOverrides)
+	protected.PUT("/mentor/availability/overrides/:id", mentorAvailabilityHandler.UpdateOverride)
+	protected.DELETE("/mentor/availability/overrides/:id", mentorAvailabilityHandler.DeleteOverride)
+	protected.PUT("/mentor/availability/:id", mentorAvailabilityHandler.Update)
+	protected.DELETE("/mentor/availability/:id", mentorAvailabilityHandler.Delete)
+	protected.POST("/mentor/calendars", externalCalendarHandler.Subscribe)
+	protected.POST("/mentor/calendars/upload", externalCalendarHandler.Upload)
+	protected.GET("/mentor/calendars", externalCalendarHandler.List)
+	protected.DELETE("/mentor/calendars/:id", externalCalendarHandler.Delete)
+	protected.POST("/mentor/calendars/:id/sync", externalCalendarHandler.Sync)
 	protected.POST("/bookings", bookingHandler.CreateBooking)
 	protected.GET("/bookings/me", bookingHandler.GetMyBookings)
+	protected.POST("/bookings/:id/cancel", bookingHandler.CancelBooking)
+	protected.POST("/bookings/:id/reschedule", bookingHandler.RescheduleBooking)
+	protected.POST("/bookings/:id/review", reviewHandler.Create)
+	protected.POST("/mentors/:username/holds", bookingHandler.HoldSlot)
+	protected.DELETE("/mentors/:username/holds/:token", bookingHandler.ReleaseHold)
+	protected.POST("/mentors/:username/waitlist", waitlistHandler.Join)
+	protected.GET("/waitlist/me", waitlistHandler.GetMine)
+	protected.DELETE("/waitlist/:id", waitlistHandler.Leave)
+	protected.POST("/waitlist/offers/:id/accept", waitlistHandler.AcceptOffer)
+	protected.GET("/bookings/series/:id", bookingHandler.GetSeries)
+	protected.POST("/bookings/series/:id/cancel", bookingHandler.CancelSeries)
+	protected.GET("/bookings/:id/ics", calendarHandler.BookingInvite)
+	protected.POST("/calendar/feed", calendarHandler.CreateFeed)
+	protected.DELETE("/calendar/feed", calendarHandler.DeleteFeed)
 	protected.GET("/mentor/booked-sessions", bookingHandler.GetMentorBookedSessions)
+	protected.GET("/mentor/booking-requests", bookingHandler.GetMentorRequests)
+	protected.POST("/mentor/bookings/:id/approve", bookingHandler.ApproveBooking)
+	protected.POST("/mentor/bookings/:id/decline", bookingHandler.DeclineBooking)
+	protected.GET("/mentor/earnings", earningsHandler.GetEarnings)
+	protected.GET("/mentor/earnings/export", earningsHandler.ExportEarnings)
+	protected.POST("/mentor/promo-codes", promoCodeHandler.CreateMentorCode)
+	protected.GET("/mentor/promo-codes", promoCodeHandler.ListMentorCodes)
+	protected.POST("/mentor/packages", packageHandler.Create)
+	protected.POST("/packages/:id/purchase", packageHandler.Purchase)
+	protected.GET("/packages/me", packageHandler.GetMyPurchases)
 
 	protected.POST("/payments", paymentHandler.CreatePayment)
 	protected.POST("/payments/verify", paymentHandler.VerifyPayment)
+	protected.GET("/payments/:id/receipt", receiptHandler.GetReceipt)
+
+	admin := protected.Group("/admin")
+	admin.Use(middlewares.RequireRole(constants.RoleAdmin))
+
+	admin.POST("/promo-codes", promoCodeHandler.CreatePlatformCode)
+	admin.GET("/promo-codes", promoCodeHandler.ListPlatformCodes)
+	admin.GET("/webhook-events", webhookHandler.List)
+	admin.POST("/webhook-events/:id/replay", webhookHandler.Replay)
+	admin.POST("/payments/reconcile", reconciliationHandler.Reconcile)
 
 	// WebSocket for video calls - uses custom token auth (query param), not middleware
 	protected.GET("/session/info", webSocketHandler.GetSessionInfo)
@@ -15,8 +15,13 @@ func RegisterPublicEndpoints(
 	mentorServiceHandler *handlers.MentorServiceHandler,
 	mentorAvailabilityHandler *handlers.MentorAvailabilityHandler,
 	paymentHandler *handlers.PaymentHandler,
+	webhookHandler *handlers.WebhookHandler,
+	packageHandler *handlers.PackageHandler,
+	calendarHandler *handlers.CalendarHandler,
+	reviewHandler *handlers.ReviewHandler,
 	webSocketHandler *handlers.WebSocketHandler,
 	bookingRepo *repositories.BookingRepository,
+	serviceRepo *repositories.MentorServiceRepository,
 	userRepo *repositories.UserRepository,
 	mentorRepo *repositories.MentorRepository,
 	jwtSecret string,
@@ -31,14 +36,19 @@ func RegisterPublicEndpoints(
 	public.GET("/users/:username", userHandlers.GetUserProfile)
 	public.GET("/mentors/:username", mentorHandler.GetProfile)
 	public.GET("/mentors/:username/services", mentorServiceHandler.GetByUsername)
+	public.GET("/mentors/:username/packages", packageHandler.GetByUsername)
+	public.GET("/mentors/:username/reviews", reviewHandler.GetByUsername)
 
 	public.GET("/mentors/:username/availability", mentorAvailabilityHandler.GetByUsername)
 
 	public.POST("/webhooks/razorpay", paymentHandler.RazorpayWebhook)
+	public.POST("/webhooks/:gateway", webhookHandler.Receive)
+
+	public.GET("/calendar/feeds/:token", calendarHandler.Feed)
 
 	// WebSocket endpoint with secure authentication middleware
 	// Middleware validates JWT, loads booking, derives role, loads username from DB
-	wsAuth := middlewares.WebSocketAuthMiddleware(jwtSecret, bookingRepo, userRepo, mentorRepo)
+	wsAuth := middlewares.WebSocketAuthMiddleware(jwtSecret, bookingRepo, serviceRepo, userRepo, mentorRepo)
 	public.GET("/ws/video", wsAuth, webSocketHandler.HandleWebSocket)
 
 }
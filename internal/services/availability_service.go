@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +17,8 @@ type AvailabilityService struct {
 	serviceRepo      *repositories.MentorServiceRepository
 	availabilityRepo *repositories.MentorAvailabilityRepository
 	bookingRepo      *repositories.BookingRepository
+	externalRepo     *repositories.ExternalCalendarRepository
+	holds            repositories.SlotHoldStore
 }
 
 func NewAvailabilityService(
@@ -22,19 +26,31 @@ func NewAvailabilityService(
 	serviceRepo *repositories.MentorServiceRepository,
 	availabilityRepo *repositories.MentorAvailabilityRepository,
 	bookingRepo *repositories.BookingRepository,
+	externalRepo *repositories.ExternalCalendarRepository,
+	holds repositories.SlotHoldStore,
 ) *AvailabilityService {
 	return &AvailabilityService{
 		mentorRepo:       mentorRepo,
 		serviceRepo:      serviceRepo,
 		availabilityRepo: availabilityRepo,
 		bookingRepo:      bookingRepo,
+		externalRepo:     externalRepo,
+		holds:            holds,
 	}
 }
 
+// maxCalendarDays caps the range of one calendar request
+const maxCalendarDays = 62
+
+// GetAvailableSlots lists the free slots starting on `dateStr` as seen in
+// the timezone `tz` (the mentor's own when empty). Rules are applied in
+// the mentor's timezone, so a learner elsewhere may see slots from two of
+// the mentor's days.
 func (s *AvailabilityService) GetAvailableSlots(
 	username string,
 	serviceID uuid.UUID,
 	dateStr string,
+	tz string,
 ) (*dtos.AvailabilityResponse, error) {
 
 	date, err := time.Parse("2006-01-02", dateStr)
@@ -42,81 +58,278 @@ func (s *AvailabilityService) GetAvailableSlots(
 		return nil, errors.New("invalid date")
 	}
 
-	mentor, err := s.mentorRepo.FindByUsernameRaw(username)
+	days, _, err := s.slotsByDay(username, serviceID, date, date, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	return &days[0], nil
+}
+
+// GetCalendar lists the free slots of every day from `fromStr` to `toStr`
+// (inclusive, at most maxCalendarDays) grouped by day in the timezone
+// `tz`, plus the first free slot of the range. The whole range is read
+// in a handful of queries.
+func (s *AvailabilityService) GetCalendar(
+	username string,
+	serviceID uuid.UUID,
+	fromStr string,
+	toStr string,
+	tz string,
+) (*dtos.AvailabilityCalendarResponse, error) {
+
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		return nil, errors.New("invalid from date")
+	}
+
+	to := from
+	if toStr != "" {
+		to, err = time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return nil, errors.New("invalid to date")
+		}
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+
+	if to.Sub(from) >= maxCalendarDays*24*time.Hour {
+		return nil, fmt.Errorf("range cannot exceed %d days", maxCalendarDays)
+	}
+
+	days, loc, err := s.slotsByDay(username, serviceID, from, to, tz)
 	if err != nil {
 		return nil, err
 	}
 
+	resp := &dtos.AvailabilityCalendarResponse{
+		From:     fromStr,
+		To:       to.Format("2006-01-02"),
+		Timezone: loc.String(),
+		Days:     days,
+	}
+
+	for _, d := range days {
+		if len(d.Slots) > 0 {
+			first := d.Slots[0]
+			resp.FirstAvailable = &first
+			break
+		}
+	}
+
+	return resp, nil
+}
+
+// slotsByDay computes the free slots of the dates from..to as seen in
+// `tz`, one entry per date in order
+func (s *AvailabilityService) slotsByDay(
+	username string,
+	serviceID uuid.UUID,
+	from time.Time,
+	to time.Time,
+	tz string,
+) ([]dtos.AvailabilityResponse, *time.Location, error) {
+
+	mentor, err := s.mentorRepo.FindByUsernameRaw(username)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	service, err := s.serviceRepo.FindByID(serviceID)
 	if err != nil || service.MentorID != mentor.ID {
-		return nil, errors.New("invalid service")
+		return nil, nil, errors.New("invalid service")
+	}
+
+	loc, err := loadLocation(tz, mentorLocation(mentor))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	dayOfWeek := int(date.Weekday())
+	rangeStart := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	rangeEnd := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, loc)
+
+	slots, err := s.freeSlots(mentor, service, rangeStart, rangeEnd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var days []dtos.AvailabilityResponse
+	for day := dateOf(from); !day.After(dateOf(to)); day = day.AddDate(0, 0, 1) {
+		days = append(days, dtos.AvailabilityResponse{
+			Date:     day.Format("2006-01-02"),
+			Timezone: loc.String(),
+			Slots:    []dtos.AvailableSlot{},
+		})
+	}
+
+	for _, w := range slots {
+		i := int(dateOf(w.Start.In(loc)).Sub(dateOf(from)) / (24 * time.Hour))
+
+		days[i].Slots = append(days[i].Slots, dtos.AvailableSlot{
+			Start:     w.Start.In(loc).Format("15:04"),
+			End:       w.End.In(loc).Format("15:04"),
+			StartsAt:  w.Start.UTC(),
+			EndsAt:    w.End.UTC(),
+			SeatsLeft: w.SeatsLeft,
+		})
+	}
+
+	return days, loc, nil
+}
+
+// freeSlots lists, in order, the bookable slots of `service` starting in
+// [from, to), honouring the mentor's availability, existing bookings and
+// booking settings
+func (s *AvailabilityService) freeSlots(
+	mentor *models.MentorProfile,
+	service *models.MentorService,
+	from time.Time,
+	to time.Time,
+) ([]availabilityWindow, error) {
+
+	mentorLoc := mentorLocation(mentor)
+	duration := time.Duration(service.DurationMinutes) * time.Minute
 
-	rules, err := s.availabilityRepo.FindByMentorAndDay(mentor.ID, dayOfWeek)
+	windows, err := loadWindows(context.Background(), s.availabilityRepo, mentor, from, to)
 	if err != nil {
 		return nil, err
 	}
 
-	bookings, err := s.bookingRepo.FindForMentorOnDate(
-		mentor.ID,
-		date,
-	)
+	buffer := sessionBuffer(mentor)
+
+	// Fetch enough bookings to apply the buffer around the slots and to
+	// count sessions on each of the mentor's days the slots touch
+	fetchFrom, _ := mentorDay(from, mentorLoc)
+	fetchFrom = earliest(fetchFrom, from.Add(-buffer))
+	_, fetchTo := mentorDay(to.Add(duration), mentorLoc)
+
+	bookings, err := s.bookingRepo.FindForMentorBetween(mentor.ID, fetchFrom, fetchTo)
 	if err != nil {
 		return nil, err
 	}
 
-	duration := time.Duration(service.DurationMinutes) * time.Minute
-	slots := []dtos.AvailableSlot{}
-
-	for _, rule := range rules {
-		start := time.Date(
-			date.Year(), date.Month(), date.Day(),
-			rule.StartTime.Hour(), rule.StartTime.Minute(), 0, 0, time.UTC,
-		)
-
-		end := time.Date(
-			date.Year(), date.Month(), date.Day(),
-			rule.EndTime.Hour(), rule.EndTime.Minute(), 0, 0, time.UTC,
-		)
-
-		for start.Add(duration).Equal(end) || start.Add(duration).Before(end) {
-			slotEnd := start.Add(duration)
-
-			if !overlaps(start, slotEnd, bookings) {
-				slots = append(slots, dtos.AvailableSlot{
-					Start: start.Format("15:04"),
-					End:   slotEnd.Format("15:04"),
-				})
-			}
+	// Events in the mentor's other calendars block slots like bookings,
+	// but don't count towards the daily cap
+	external, err := s.externalRepo.FindBusyBetween(context.Background(), mentor.ID, fetchFrom, fetchTo)
+	if err != nil {
+		return nil, err
+	}
 
-			start = slotEnd
-		}
+	// Slots held by learners checking out are taken until the hold ends
+	holds, err := s.holds.FindBetween(context.Background(), mentor.ID, fetchFrom, fetchTo)
+	if err != nil {
+		return nil, err
 	}
 
-	return &dtos.AvailabilityResponse{
-		Date:  dateStr,
-		Slots: slots,
-	}, nil
-}
+	perDay := map[time.Time]int{}
+	busy := make([]availabilityWindow, 0, len(bookings)+len(external)+len(holds))
+
+	// Seats of this service's group sessions, by session start. They
+	// don't block their own session until it is full.
+	seats := map[int64]int{}
+	var sessions []availabilityWindow
+
+	// A group session counts once towards the daily cap, however many
+	// seats are taken
+	counted := map[string]bool{}
 
-func overlaps(start, end time.Time, bookings []*models.Booking) bool {
 	for _, b := range bookings {
+		key := b.ServiceID.String() + b.StartsAt.UTC().Format(time.RFC3339)
+		if !counted[key] {
+			counted[key] = true
+			perDay[dateOf(b.StartsAt.In(mentorLoc))]++
+		}
+
+		if service.IsGroup() && b.ServiceID == service.ID {
+			if seats[b.StartsAt.Unix()] == 0 {
+				sessions = append(sessions, availabilityWindow{Start: b.StartsAt, End: b.EndsAt})
+			}
+			seats[b.StartsAt.Unix()]++
+			continue
+		}
+
+		busy = append(busy, availabilityWindow{Start: b.StartsAt, End: b.EndsAt})
+	}
+
+	for _, b := range external {
+		busy = append(busy, availabilityWindow{Start: b.StartsAt, End: b.EndsAt})
+	}
+
+	for _, h := range holds {
+		busy = append(busy, availabilityWindow{Start: h.StartsAt, End: h.EndsAt})
+	}
+
+	now := time.Now()
+	var slots []availabilityWindow
+
+	for _, w := range windows {
+		for start := w.Start; !start.Add(duration).After(w.End); start = start.Add(duration + buffer) {
+			end := start.Add(duration)
 
-		bStart := time.Date(
-			start.Year(), start.Month(), start.Day(),
-			b.StartTime.Hour(), b.StartTime.Minute(), 0, 0, time.UTC,
-		)
+			if start.Before(from) || !start.Before(to) {
+				continue
+			}
+
+			if checkLeadTime(mentor, start, now) != nil {
+				continue
+			}
+
+			// Joining a group session that already runs doesn't add a
+			// session to the mentor's day
+			taken := seats[start.Unix()]
 
-		bEnd := time.Date(
-			start.Year(), start.Month(), start.Day(),
-			b.EndTime.Hour(), b.EndTime.Minute(), 0, 0, time.UTC,
-		)
+			if taken == 0 && mentor.DailySessionCap > 0 && perDay[dateOf(start.In(mentorLoc))] >= mentor.DailySessionCap {
+				continue
+			}
+
+			if overlaps(start.Add(-buffer), end.Add(buffer), busy) {
+				continue
+			}
+
+			slot := availabilityWindow{Start: start, End: end}
+
+			if service.IsGroup() {
+				if taken >= service.Capacity || overlapsOtherSession(start, end, buffer, sessions) {
+					continue
+				}
+				slot.SeatsLeft = service.Capacity - taken
+			}
+
+			slots = append(slots, slot)
+		}
+	}
 
-		if start.Before(bEnd) && end.After(bStart) {
+	return slots, nil
+}
+
+func overlaps(start, end time.Time, busy []availabilityWindow) bool {
+	for _, b := range busy {
+		if start.Before(b.End) && end.After(b.Start) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsOtherSession reports whether a group session other than the one
+// starting at `start` is too close to [start, end)
+func overlapsOtherSession(start, end time.Time, buffer time.Duration, sessions []availabilityWindow) bool {
+	for _, sess := range sessions {
+		if sess.Start.Equal(start) {
+			continue
+		}
+		if start.Add(-buffer).Before(sess.End) && end.Add(buffer).After(sess.Start) {
 			return true
 		}
 	}
 	return false
 }
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
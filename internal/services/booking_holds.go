@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+)
+
+// HoldSlot reserves a free slot for the learner while they check out.
+// Until the hold is consumed by CreateBooking (via hold_token), released
+// or expired, everyone else sees the slot as taken. A new hold replaces
+// the learner's own overlapping holds.
+func (s *BookingService) HoldSlot(
+	userID uuid.UUID,
+	username string,
+	req *dtos.CreateSlotHoldRequest,
+) (*dtos.SlotHoldResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUsernameRaw(username)
+	if err != nil || !mentor.IsActive {
+		return nil, errors.New("mentor not available")
+	}
+
+	service, err := s.serviceRepo.FindByID(req.ServiceID)
+	if err != nil || !service.IsActive || service.MentorID != mentor.ID {
+		return nil, errors.New("invalid service")
+	}
+
+	// A seat in a group session is taken by booking it; holding the slot
+	// would lock the other learners out
+	if service.IsGroup() {
+		return nil, errors.New("seats in group sessions cannot be held")
+	}
+
+	loc, err := loadLocation(req.Timezone, mentorLocation(mentor))
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := parseLocalTime(req.BookingDate, req.StartTime, loc)
+	if err != nil {
+		return nil, err
+	}
+	end := start.Add(time.Duration(service.DurationMinutes) * time.Minute)
+
+	if err := checkLeadTime(mentor, start, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateAgainstRules(mentor, start, end); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hold, err := placeHold(ctx, s.bookingRepo, s.mentorRepo, s.holds, mentor, service.ID, userID, start, end, s.holdFor)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSlotHoldResponse(hold), nil
+}
+
+// placeHold holds [start, end) of the mentor for the learner for
+// `holdFor`, replacing the learner's own overlapping holds. The slot must
+// be free of bookings and of other learners' holds, including the
+// mentor's buffer around it.
+//
+// Everything happens under the mentor's lock, which every booking takes
+// before its own conflict check, so a booking either sees the new hold or
+// is seen by the check here.
+func placeHold(
+	ctx context.Context,
+	bookingRepo *repositories.BookingRepository,
+	mentorRepo *repositories.MentorRepository,
+	holds repositories.SlotHoldStore,
+	mentor *models.MentorProfile,
+	serviceID uuid.UUID,
+	userID uuid.UUID,
+	start time.Time,
+	end time.Time,
+	holdFor time.Duration,
+) (*models.SlotHold, error) {
+
+	buffer := sessionBuffer(mentor)
+
+	token, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &models.SlotHold{
+		Token:     token,
+		MentorID:  mentor.ID,
+		ServiceID: serviceID,
+		UserID:    userID,
+		StartsAt:  start.UTC(),
+		EndsAt:    end.UTC(),
+		ExpiresAt: time.Now().Add(holdFor).UTC(),
+	}
+
+	created := false
+
+	err = bookingRepo.WithReadCommittedTx(ctx, func(tx *sql.Tx) error {
+		if err := mentorRepo.LockTx(ctx, tx, mentor.ID); err != nil {
+			return err
+		}
+
+		booked, err := bookingRepo.IsBookedTx(
+			ctx,
+			tx,
+			mentor.ID,
+			start.Add(-buffer),
+			end.Add(buffer),
+		)
+		if err != nil {
+			return err
+		}
+
+		if booked {
+			return ErrSlotTaken
+		}
+
+		if err := holds.Create(ctx, hold, buffer); err != nil {
+			return err
+		}
+		created = true
+
+		// The learner's old holds go only once the new one is in place,
+		// so a failed attempt leaves them as they were
+		existing, err := holds.FindBetween(ctx, mentor.ID, start.Add(-buffer), end.Add(buffer))
+		if err != nil {
+			return err
+		}
+
+		for _, h := range existing {
+			if h.UserID != userID || h.Token == hold.Token {
+				continue
+			}
+			if err := holds.Release(ctx, mentor.ID, h.Token); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if created {
+			holds.Release(ctx, mentor.ID, hold.Token)
+		}
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func toSlotHoldResponse(hold *models.SlotHold) *dtos.SlotHoldResponse {
+	return &dtos.SlotHoldResponse{
+		HoldToken: hold.Token,
+		ServiceID: hold.ServiceID,
+		StartsAt:  hold.StartsAt,
+		EndsAt:    hold.EndsAt,
+		ExpiresAt: hold.ExpiresAt,
+	}
+}
+
+// ReleaseHold gives a held slot back before the hold expires
+func (s *BookingService) ReleaseHold(
+	userID uuid.UUID,
+	username string,
+	token string,
+) error {
+
+	mentor, err := s.mentorRepo.FindByUsernameRaw(username)
+	if err != nil {
+		return errors.New("mentor not available")
+	}
+
+	ctx := context.Background()
+
+	hold, err := s.holds.Get(ctx, mentor.ID, token)
+	if err != nil {
+		return err
+	}
+
+	if hold.UserID != userID {
+		return errors.New("unauthorized")
+	}
+
+	if err := s.holds.Release(ctx, mentor.ID, token); err != nil {
+		return err
+	}
+
+	s.waitlist.SlotOpened(mentor.ID)
+	return nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/rs/zerolog/log"
+)
+
+// reaperBatchSize caps how many bookings one tick expires
+const reaperBatchSize = 100
+
+// BookingReaper cancels pending bookings that were never paid within the
+// hold window, and booking requests the mentor never answered, so they
+// stop blocking the mentor's slot. The freed slots go to the mentor's
+// waitlist.
+type BookingReaper struct {
+	bookingRepo *repositories.BookingRepository
+	bookings    *BookingService
+	waitlist    *WaitlistService
+	hold        time.Duration
+	requestTTL  time.Duration
+	interval    time.Duration
+}
+
+func NewBookingReaper(
+	bookingRepo *repositories.BookingRepository,
+	bookings *BookingService,
+	waitlist *WaitlistService,
+	hold time.Duration,
+	requestTTL time.Duration,
+	interval time.Duration,
+) *BookingReaper {
+	return &BookingReaper{
+		bookingRepo: bookingRepo,
+		bookings:    bookings,
+		waitlist:    waitlist,
+		hold:        hold,
+		requestTTL:  requestTTL,
+		interval:    interval,
+	}
+}
+
+// Run reaps on every tick until ctx is cancelled
+func (r *BookingReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.ReapOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("booking reaper failed")
+			}
+			if _, err := r.ExpireRequestsOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("booking request expiry failed")
+			}
+		}
+	}
+}
+
+// ReapOnce expires stale pending bookings until none are left
+// and returns how many it expired
+func (r *BookingReaper) ReapOnce(ctx context.Context) (int, error) {
+	total := 0
+
+	for {
+		tickCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		expired, err := r.bookingRepo.ExpireStalePending(tickCtx, r.hold, reaperBatchSize)
+		cancel()
+
+		if err != nil {
+			return total, err
+		}
+
+		total += len(expired)
+
+		for _, b := range expired {
+			log.Info().Str("booking_id", b.ID.String()).Msg("expired unpaid booking")
+			r.waitlist.SlotOpened(b.MentorID)
+		}
+
+		if len(expired) < reaperBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// ExpireRequestsOnce cancels up to one batch of unanswered booking
+// requests and returns how many it looked at
+func (r *BookingReaper) ExpireRequestsOnce(ctx context.Context) (int, error) {
+	tickCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	ids, err := r.bookingRepo.FindStaleRequested(tickCtx, r.requestTTL, reaperBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := r.bookings.ExpireRequest(tickCtx, id); err != nil {
+			log.Warn().Err(err).Str("booking_id", id.String()).Msg("booking request not expired")
+		}
+	}
+
+	return len(ids), nil
+}
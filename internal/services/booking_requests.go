@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// GetMentorRequests lists the booking requests waiting for the mentor's
+// approval with times shown in `tz` (the mentor's own when empty)
+func (s *BookingService) GetMentorRequests(
+	mentor *models.MentorProfile,
+	tz string,
+) ([]*dtos.MentorBookedSessionResponse, error) {
+
+	loc, err := loadLocation(tz, mentorLocation(mentor))
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := s.bookingRepo.GetByMentorIDRequested(mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return localizeSessions(requests, loc), nil
+}
+
+// ApproveBooking accepts a learner's booking request. The booking then
+// waits for payment like any other; free and package sessions have
+// nothing to pay and are confirmed straight away.
+func (s *BookingService) ApproveBooking(
+	mentor *models.MentorProfile,
+	bookingID uuid.UUID,
+) (*dtos.BookingResponse, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var booking *models.Booking
+
+	err := s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+
+		booking, err = s.requestForUpdateTx(ctx, tx, mentor, bookingID)
+		if err != nil {
+			return err
+		}
+
+		if !booking.StartsAt.After(time.Now()) {
+			return errors.New("session already started")
+		}
+
+		status := models.BookingStatusPending
+		if booking.PriceCents == 0 {
+			status = models.BookingStatusConfirmed
+		}
+
+		if err := s.bookingRepo.ApproveTx(ctx, tx, booking.ID, status); err != nil {
+			return err
+		}
+
+		booking.Status = status
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toBookingResponse(booking, mentorLocation(mentor)), nil
+}
+
+// DeclineBooking turns a learner's booking request down and frees its slot
+func (s *BookingService) DeclineBooking(
+	mentor *models.MentorProfile,
+	bookingID uuid.UUID,
+	req *dtos.CancelBookingRequest,
+) (*dtos.BookingResponse, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var booking *models.Booking
+
+	err := s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+
+		booking, err = s.requestForUpdateTx(ctx, tx, mentor, bookingID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.bookingRepo.CloseRequestTx(
+			ctx,
+			tx,
+			booking.ID,
+			models.BookingStatusDeclined,
+			"mentor",
+			req.Reason,
+		); err != nil {
+			return err
+		}
+
+		booking.Status = models.BookingStatusDeclined
+		return s.releaseRequestTx(ctx, tx, booking)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.waitlist.SlotOpened(booking.MentorID)
+
+	return toBookingResponse(booking, mentorLocation(mentor)), nil
+}
+
+// ExpireRequest cancels a request the mentor never answered. Requests
+// answered in the meantime are left alone.
+func (s *BookingService) ExpireRequest(
+	ctx context.Context,
+	bookingID uuid.UUID,
+) error {
+
+	expired := false
+	var booking *models.Booking
+
+	err := s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+
+		booking, err = s.bookingRepo.GetByIDForUpdateTx(ctx, tx, bookingID)
+		if err != nil {
+			return err
+		}
+
+		if booking.Status != models.BookingStatusRequested {
+			return nil
+		}
+
+		if err := s.bookingRepo.CloseRequestTx(
+			ctx,
+			tx,
+			booking.ID,
+			models.BookingStatusCancelled,
+			"system",
+			"request_expired",
+		); err != nil {
+			return err
+		}
+
+		expired = true
+		return s.releaseRequestTx(ctx, tx, booking)
+	})
+
+	if err != nil || !expired {
+		return err
+	}
+
+	log.Info().Str("booking_id", booking.ID.String()).Msg("expired unanswered booking request")
+	s.waitlist.SlotOpened(booking.MentorID)
+
+	return nil
+}
+
+// requestForUpdateTx locks one of the mentor's requested bookings
+func (s *BookingService) requestForUpdateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentor *models.MentorProfile,
+	bookingID uuid.UUID,
+) (*models.Booking, error) {
+
+	booking, err := s.bookingRepo.GetByIDForUpdateTx(ctx, tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.MentorID != mentor.ID {
+		return nil, errors.New("unauthorized")
+	}
+
+	if booking.Status != models.BookingStatusRequested {
+		return nil, errors.New("booking is not awaiting approval")
+	}
+
+	return booking, nil
+}
+
+// releaseRequestTx gives back what a closed request took when it was made
+func (s *BookingService) releaseRequestTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	booking *models.Booking,
+) error {
+
+	if booking.PackagePurchaseID != nil {
+		if err := s.packageRepo.ReturnCreditTx(ctx, tx, *booking.PackagePurchaseID); err != nil {
+			return err
+		}
+	}
+
+	return s.promoService.ReleaseTx(ctx, tx, booking.ID)
+}
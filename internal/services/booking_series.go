@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// SeriesUnavailableError lists the occurrences that kept a recurring
+// booking from being made. Nothing of the series is booked.
+type SeriesUnavailableError struct {
+	Failures []dtos.SeriesOccurrenceFailure
+}
+
+func (e *SeriesUnavailableError) Error() string {
+	return fmt.Sprintf("%d of the requested dates cannot be booked", len(e.Failures))
+}
+
+// CreateSeries books the same slot every week (or every few weeks) for
+// req.Recurrence.Count occurrences. Every occurrence is checked against
+// the mentor's settings, availability, bookings and daily cap in one
+// transaction: either all are booked, pending one payment for the whole
+// series, or a *SeriesUnavailableError reports the dates that failed.
+func (s *BookingService) CreateSeries(
+	userID uuid.UUID,
+	req *dtos.CreateBookingRequest,
+) (*dtos.BookingSeriesResponse, error) {
+
+	rec := req.Recurrence
+
+	service, err := s.serviceRepo.FindByID(req.ServiceID)
+	if err != nil || !service.IsActive {
+		return nil, errors.New("invalid service")
+	}
+
+	mentor, err := s.mentorRepo.FindByID(service.MentorID)
+	if err != nil || !mentor.IsActive {
+		return nil, errors.New("mentor not available")
+	}
+
+	if service.IsGroup() {
+		return nil, errors.New("group sessions cannot be booked as a series")
+	}
+
+	if service.IsFree() {
+		return nil, errors.New("free services cannot be booked as a series")
+	}
+
+	// One payment covers the whole series, so it can't wait on the mentor
+	// approving each occurrence
+	if service.RequiresApproval {
+		return nil, errors.New("services that require approval cannot be booked as a series")
+	}
+
+	if req.PromoCode != "" || req.PackagePurchaseID != nil {
+		return nil, errors.New("promo codes and package credits cannot be used with recurring bookings")
+	}
+
+	loc, err := loadLocation(req.Timezone, mentorLocation(mentor))
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := time.Parse("2006-01-02", req.BookingDate)
+	if err != nil {
+		return nil, errors.New("invalid date format")
+	}
+
+	interval := rec.Interval
+	if interval == 0 {
+		interval = 1
+	}
+
+	series := &models.BookingSeries{
+		ID:            uuid.New(),
+		MentorID:      mentor.ID,
+		UserID:        userID,
+		ServiceID:     service.ID,
+		Frequency:     rec.Frequency,
+		IntervalWeeks: interval,
+		Occurrences:   rec.Count,
+		PriceCents:    service.PriceCents * rec.Count,
+		Currency:      service.Currency,
+	}
+
+	if err := s.paymentService.ValidateAmount(int64(series.PriceCents), series.Currency); err != nil {
+		return nil, errors.New("series price cannot be charged: " + err.Error())
+	}
+
+	duration := time.Duration(service.DurationMinutes) * time.Minute
+
+	var (
+		bookings []*models.Booking
+		failures []dtos.SeriesOccurrenceFailure
+	)
+
+	fail := func(b *models.Booking, reason string) {
+		failures = append(failures, dtos.SeriesOccurrenceFailure{
+			Date:      b.StartsAt.In(loc).Format("2006-01-02"),
+			StartTime: b.StartsAt.In(loc).Format("15:04"),
+			Reason:    reason,
+		})
+	}
+
+	// Occurrences keep the same wall-clock time, across DST changes too
+	for i := 0; i < rec.Count; i++ {
+		date := first.AddDate(0, 0, 7*interval*i).Format("2006-01-02")
+
+		start, err := parseLocalTime(date, req.StartTime, loc)
+		if err != nil {
+			failures = append(failures, dtos.SeriesOccurrenceFailure{
+				Date:      date,
+				StartTime: req.StartTime,
+				Reason:    err.Error(),
+			})
+			continue
+		}
+
+		bookings = append(bookings, &models.Booking{
+			ID:         uuid.New(),
+			MentorID:   mentor.ID,
+			UserID:     userID,
+			ServiceID:  service.ID,
+			StartsAt:   start.UTC(),
+			EndsAt:     start.Add(duration).UTC(),
+			Status:     models.BookingStatusPending,
+			PriceCents: service.PriceCents,
+			Currency:   service.Currency,
+			SeriesID:   &series.ID,
+		})
+	}
+
+	if len(bookings) > 0 {
+		windows, err := loadWindows(
+			context.Background(),
+			s.availabilityRepo,
+			mentor,
+			bookings[0].StartsAt,
+			bookings[len(bookings)-1].EndsAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		for _, b := range bookings {
+			if err := checkLeadTime(mentor, b.StartsAt, now); err != nil {
+				fail(b, err.Error())
+			} else if !fitsWindows(windows, b.StartsAt, b.EndsAt) {
+				fail(b, "selected slot outside availability")
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, &SeriesUnavailableError{Failures: failures}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := s.mentorRepo.LockTx(ctx, tx, mentor.ID); err != nil {
+			return err
+		}
+
+		buffer := sessionBuffer(mentor)
+
+		for _, b := range bookings {
+			err := s.checkDailyCapTx(ctx, tx, mentor, uuid.Nil, b.StartsAt)
+			if errors.Is(err, ErrDailyCapReached) {
+				fail(b, err.Error())
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			conflict, err := s.bookingRepo.HasConflictTx(
+				ctx,
+				tx,
+				mentor.ID,
+				uuid.Nil,
+				"",
+				b.StartsAt.Add(-buffer),
+				b.EndsAt.Add(buffer),
+			)
+			if err != nil {
+				return err
+			}
+
+			if conflict {
+				fail(b, "slot already booked")
+			}
+		}
+
+		if len(failures) > 0 {
+			return &SeriesUnavailableError{Failures: failures}
+		}
+
+		if err := s.seriesRepo.CreateTx(ctx, tx, series); err != nil {
+			return err
+		}
+
+		for _, b := range bookings {
+			if err := s.bookingRepo.CreateTx(ctx, tx, b); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toSeriesResponse(series, bookings, loc), nil
+}
+
+// GetSeries shows a series and all of its occurrences to its learner or
+// mentor, with times in `tz` (the mentor's own when empty)
+func (s *BookingService) GetSeries(
+	userID uuid.UUID,
+	seriesID uuid.UUID,
+	tz string,
+) (*dtos.BookingSeriesResponse, error) {
+
+	ctx := context.Background()
+
+	series, err := s.seriesRepo.GetByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.resolveActor(userID, &models.Booking{
+		UserID:   series.UserID,
+		MentorID: series.MentorID,
+	}); err != nil {
+		return nil, err
+	}
+
+	mentor, err := s.mentorRepo.FindByID(series.MentorID)
+	if err != nil {
+		return nil, errors.New("mentor not available")
+	}
+
+	loc, err := loadLocation(tz, mentorLocation(mentor))
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.seriesRepo.ListBookings(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSeriesResponse(series, bookings, loc), nil
+}
+
+// CancelSeries cancels every occurrence of the series that hasn't started
+// yet. Each one is cancelled - and refunded under the policy - exactly as
+// if it had been cancelled on its own.
+func (s *BookingService) CancelSeries(
+	userID uuid.UUID,
+	seriesID uuid.UUID,
+	req *dtos.CancelBookingRequest,
+) (*dtos.CancelSeriesResponse, error) {
+
+	ctx := context.Background()
+
+	series, err := s.seriesRepo.GetByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.resolveActor(userID, &models.Booking{
+		UserID:   series.UserID,
+		MentorID: series.MentorID,
+	}); err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.seriesRepo.ListBookings(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dtos.CancelSeriesResponse{
+		ID:        series.ID,
+		Cancelled: []dtos.CancelBookingResponse{},
+		Currency:  series.Currency,
+	}
+
+	now := time.Now()
+
+	for _, b := range bookings {
+		if b.Status != models.BookingStatusPending && b.Status != models.BookingStatusConfirmed {
+			continue
+		}
+		if !b.StartsAt.After(now) {
+			continue
+		}
+
+		cancelled, err := s.CancelBooking(userID, b.ID, req)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("series_id", series.ID.String()).
+				Str("booking_id", b.ID.String()).
+				Msg("series occurrence not cancelled")
+			continue
+		}
+
+		resp.Cancelled = append(resp.Cancelled, *cancelled)
+		resp.RefundCents += cancelled.RefundCents
+	}
+
+	if len(resp.Cancelled) == 0 {
+		return nil, errors.New("nothing left to cancel in this series")
+	}
+
+	return resp, nil
+}
+
+func toSeriesResponse(
+	series *models.BookingSeries,
+	bookings []*models.Booking,
+	loc *time.Location,
+) *dtos.BookingSeriesResponse {
+
+	resp := &dtos.BookingSeriesResponse{
+		ID:             series.ID,
+		Frequency:      series.Frequency,
+		Interval:       series.IntervalWeeks,
+		Occurrences:    series.Occurrences,
+		Price:          series.PriceCents,
+		Currency:       series.Currency,
+		FormattedPrice: utils.FormatMoney(int64(series.PriceCents), series.Currency),
+		Bookings:       make([]dtos.BookingResponse, 0, len(bookings)),
+	}
+
+	for _, b := range bookings {
+		resp.Bookings = append(resp.Bookings, *toBookingResponse(b, loc))
+	}
+
+	return resp
+}
@@ -4,19 +4,46 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
 	"github.com/preetsinghmakkar/OpenCall/internal/models"
 	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
 )
 
+// ErrDailyCapReached is returned when the mentor's day already holds
+// their daily session cap
+var ErrDailyCapReached = errors.New("mentor is fully booked on this day")
+
+// ErrSlotTaken is returned when another booking or hold overlaps the slot
+var ErrSlotTaken = errors.New("slot already booked")
+
+// ErrFreeSessionUsed is returned when the learner already has their free
+// sessions with the mentor
+var ErrFreeSessionUsed = errors.New("free session with this mentor already used")
+
+// freeSessionsPerMentor caps the free sessions a learner books with one
+// mentor, cancelled and declined ones aside
+const freeSessionsPerMentor = 1
+
 type BookingService struct {
 	bookingRepo      *repositories.BookingRepository
 	mentorRepo       *repositories.MentorRepository
 	serviceRepo      *repositories.MentorServiceRepository
 	availabilityRepo *repositories.MentorAvailabilityRepository
+	packageRepo      *repositories.PackageRepository
+	seriesRepo       *repositories.BookingSeriesRepository
+	holds            repositories.SlotHoldStore
+	paymentService   *PaymentService
+	promoService     *PromoService
+	waitlist         *WaitlistService
+	policy           CancellationPolicy
+
+	// How long a slot hold keeps a slot for its learner
+	holdFor time.Duration
 }
 
 func NewBookingService(
@@ -24,12 +51,27 @@ func NewBookingService(
 	mentorRepo *repositories.MentorRepository,
 	serviceRepo *repositories.MentorServiceRepository,
 	availabilityRepo *repositories.MentorAvailabilityRepository,
+	packageRepo *repositories.PackageRepository,
+	seriesRepo *repositories.BookingSeriesRepository,
+	holds repositories.SlotHoldStore,
+	paymentService *PaymentService,
+	promoService *PromoService,
+	waitlist *WaitlistService,
+	holdFor time.Duration,
 ) *BookingService {
 	return &BookingService{
 		bookingRepo:      bookingRepo,
 		mentorRepo:       mentorRepo,
 		serviceRepo:      serviceRepo,
 		availabilityRepo: availabilityRepo,
+		packageRepo:      packageRepo,
+		seriesRepo:       seriesRepo,
+		holds:            holds,
+		paymentService:   paymentService,
+		promoService:     promoService,
+		waitlist:         waitlist,
+		policy:           DefaultCancellationPolicy,
+		holdFor:          holdFor,
 	}
 }
 
@@ -38,132 +80,235 @@ func (s *BookingService) CreateBooking(
 	req *dtos.CreateBookingRequest,
 ) (*dtos.BookingResponse, error) {
 
-	// 1️⃣ Parse date
-	bookingDate, err := time.Parse("2006-01-02", req.BookingDate)
-	if err != nil {
-		return nil, errors.New("invalid date format")
-	}
-
-	// 2️⃣ Parse start time
-	startTimeParsed, err := time.Parse("15:04", req.StartTime)
-	if err != nil {
-		return nil, errors.New("invalid start time")
-	}
-
-	// 3️⃣ Fetch service
+	// 1️⃣ Fetch service
 	service, err := s.serviceRepo.FindByID(req.ServiceID)
 	if err != nil || !service.IsActive {
 		return nil, errors.New("invalid service")
 	}
 
-	// 4️⃣ Fetch mentor
+	// 2️⃣ Fetch mentor
 	mentor, err := s.mentorRepo.FindByID(service.MentorID)
 	if err != nil || !mentor.IsActive {
 		return nil, errors.New("mentor not available")
 	}
 
-	// 5️⃣ Build start/end datetime (UTC)
-	start := time.Date(
-		bookingDate.Year(), bookingDate.Month(), bookingDate.Day(),
-		startTimeParsed.Hour(), startTimeParsed.Minute(),
-		0, 0, time.UTC,
-	)
+	if req.PromoCode != "" && req.PackagePurchaseID != nil {
+		return nil, errors.New("promo codes cannot be used with package credits")
+	}
+
+	if req.PromoCode != "" && service.IsFree() {
+		return nil, errors.New("promo codes cannot be used with free services")
+	}
+
+	// 3️⃣ Resolve the requested wall-clock time to an instant
+	loc, err := loadLocation(req.Timezone, mentorLocation(mentor))
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := parseLocalTime(req.BookingDate, req.StartTime, loc)
+	if err != nil {
+		return nil, err
+	}
 
 	duration := time.Duration(service.DurationMinutes) * time.Minute
 	end := start.Add(duration)
 
-	// 6️⃣ Validate availability rules
-	dayOfWeek := int(bookingDate.Weekday())
+	// 4️⃣ Validate the mentor's booking settings and availability rules
+	if err := checkLeadTime(mentor, start, time.Now()); err != nil {
+		return nil, err
+	}
 
-	rules, err := s.availabilityRepo.FindByMentorAndDay(mentor.ID, dayOfWeek)
-	if err != nil || len(rules) == 0 {
-		return nil, errors.New("mentor not available on this day")
+	if err := s.validateAgainstRules(mentor, start, end); err != nil {
+		return nil, err
 	}
 
-	valid := false
-	for _, r := range rules {
-		ruleStart := time.Date(
-			start.Year(), start.Month(), start.Day(),
-			r.StartTime.Hour(), r.StartTime.Minute(),
-			0, 0, time.UTC,
-		)
+	// 5️⃣ TRANSACTION START
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-		ruleEnd := time.Date(
-			start.Year(), start.Month(), start.Day(),
-			r.EndTime.Hour(), r.EndTime.Minute(),
-			0, 0, time.UTC,
-		)
+	// The learner's own hold on this slot doesn't block them. An expired
+	// hold is no error: the booking goes ahead if the slot is still free.
+	if req.HoldToken != "" {
+		hold, err := s.holds.Get(ctx, mentor.ID, req.HoldToken)
+		if err != nil && !errors.Is(err, repositories.ErrHoldNotFound) {
+			return nil, err
+		}
 
-		if !start.Before(ruleStart) && !end.After(ruleEnd) {
-			valid = true
-			break
+		if hold != nil && (hold.UserID != userID ||
+			hold.ServiceID != service.ID ||
+			!hold.StartsAt.Equal(start)) {
+			return nil, errors.New("hold does not match this booking")
 		}
 	}
 
-	if !valid {
-		return nil, errors.New("selected slot outside availability")
+	booking := &models.Booking{
+		ID:         uuid.New(),
+		MentorID:   mentor.ID,
+		UserID:     userID,
+		ServiceID:  service.ID,
+		StartsAt:   start.UTC(),
+		EndsAt:     end.UTC(),
+		Status:     models.BookingStatusPending,
+		PriceCents: service.PriceCents,
+		Currency:   service.Currency,
 	}
 
-	// 7️⃣ TRANSACTION START
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	err = s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
 
-	bookingID := uuid.New()
+		if service.IsGroup() {
+			if err := s.checkSeatTx(ctx, tx, mentor, service, userID, start, end); err != nil {
+				return err
+			}
+		} else {
+			if err := s.checkDailyCapTx(ctx, tx, mentor, uuid.Nil, start); err != nil {
+				return err
+			}
+
+			// The lock keeps a slot hold from landing between the
+			// conflict check and the commit
+			if err := s.mentorRepo.LockTx(ctx, tx, mentor.ID); err != nil {
+				return err
+			}
+
+			// The buffer keeps the new session clear of its neighbours
+			buffer := sessionBuffer(mentor)
+
+			conflict, err := s.bookingRepo.HasConflictTx(
+				ctx,
+				tx,
+				mentor.ID,
+				uuid.Nil,
+				req.HoldToken,
+				start.Add(-buffer),
+				end.Add(buffer),
+			)
+			if err != nil {
+				return err
+			}
+
+			if conflict {
+				return ErrSlotTaken
+			}
+		}
 
-	err = s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		// Package sessions are already paid for, so they are confirmed
+		// straight away and don't go through the payment flow
+		if req.PackagePurchaseID != nil {
+			if err := s.packageRepo.SpendCreditTx(
+				ctx,
+				tx,
+				*req.PackagePurchaseID,
+				userID,
+				service.ID,
+			); err != nil {
+				return err
+			}
+
+			booking.PackagePurchaseID = req.PackagePurchaseID
+			booking.Status = models.BookingStatusConfirmed
+			booking.PriceCents = 0
+		}
 
-		conflict, err := s.bookingRepo.HasConflictTx(
-			ctx,
-			tx,
-			mentor.ID,
-			bookingDate,
-			start,
-			end,
-		)
-		if err != nil {
-			return err
+		// The code stays locked until commit, so its limits hold
+		// under concurrent bookings
+		var promo *models.PromoCode
+		if req.PromoCode != "" {
+			var discount int
+
+			promo, discount, err = s.promoService.ReserveTx(ctx, tx, req.PromoCode, userID, service)
+			if err != nil {
+				return err
+			}
+
+			// A code covering the whole price leaves nothing to charge
+			if discount < service.PriceCents {
+				if err := s.paymentService.ValidateAmount(
+					int64(service.PriceCents-discount),
+					service.Currency,
+				); err != nil {
+					return errors.New("discounted price cannot be charged: " + err.Error())
+				}
+			}
+
+			booking.PromoCodeID = &promo.ID
+			booking.DiscountCents = discount
+			booking.PriceCents = service.PriceCents - discount
 		}
 
-		if conflict {
-			return errors.New("slot already booked")
+		// Free sessions have nothing to pay, so they are confirmed
+		// straight away. Locking the mentor keeps concurrent bookings
+		// from both slipping under the limit.
+		if service.IsFree() && req.PackagePurchaseID == nil {
+			if err := s.mentorRepo.LockTx(ctx, tx, mentor.ID); err != nil {
+				return err
+			}
+
+			used, err := s.bookingRepo.CountFreeForUserTx(ctx, tx, mentor.ID, userID)
+			if err != nil {
+				return err
+			}
+
+			if used >= freeSessionsPerMentor {
+				return ErrFreeSessionUsed
+			}
+
+			booking.Status = models.BookingStatusConfirmed
+		}
+
+		// A fully discounted booking has nothing to pay either. The code's
+		// own limits apply to it, not the free session cap.
+		if promo != nil && booking.PriceCents == 0 {
+			booking.Status = models.BookingStatusConfirmed
+		}
+
+		// A request holds the slot until the mentor answers it. Package
+		// credits and promo uses are taken now and given back if the
+		// request is declined or expires.
+		if service.RequiresApproval {
+			booking.Status = models.BookingStatusRequested
+		}
+
+		if err := s.bookingRepo.CreateTx(ctx, tx, booking); err != nil {
+			return err
 		}
 
-		booking := &models.Booking{
-			ID:          bookingID,
-			MentorID:    mentor.ID,
-			UserID:      userID,
-			ServiceID:   service.ID,
-			BookingDate: bookingDate,
-			StartTime:   start,
-			EndTime:     end,
-			Status:      models.BookingStatusPending,
-			PriceCents:  service.PriceCents,
-			Currency:    service.Currency,
+		if promo != nil {
+			return s.promoService.RedeemTx(ctx, tx, promo, booking.ID, userID, booking.DiscountCents)
 		}
 
-		return s.bookingRepo.CreateTx(ctx, tx, booking)
+		return nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	// The booking now blocks the slot itself; a hold left behind would
+	// expire anyway
+	if req.HoldToken != "" {
+		s.holds.Release(context.Background(), mentor.ID, req.HoldToken)
+	}
+
 	// Response
-	return &dtos.BookingResponse{
-		ID:        bookingID,
-		Status:    string(models.BookingStatusPending),
-		Date:      req.BookingDate,
-		StartTime: start.Format("15:04"),
-		EndTime:   end.Format("15:04"),
-		Price:     service.PriceCents,
-		Currency:  service.Currency,
-	}, nil
+	resp := toBookingResponse(booking, loc)
+	resp.PromoCode = strings.ToUpper(strings.TrimSpace(req.PromoCode))
+
+	return resp, nil
 }
 
+// GetMyBookings lists the learner's bookings with times shown in `tz`
+// (UTC when empty)
 func (s *BookingService) GetMyBookings(
 	userID uuid.UUID,
+	tz string,
 ) ([]*dtos.MyBookingResponse, error) {
 
+	loc, err := loadLocation(tz, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
 	bookings, err := s.bookingRepo.GetByUserID(userID)
 	if err != nil {
 		return nil, err
@@ -173,21 +318,380 @@ func (s *BookingService) GetMyBookings(
 		return []*dtos.MyBookingResponse{}, nil
 	}
 
+	for _, b := range bookings {
+		b.Timezone = loc.String()
+		b.Date = b.StartsAt.In(loc).Format("2006-01-02")
+		b.StartTime = b.StartsAt.In(loc).Format("15:04")
+		b.EndTime = b.EndsAt.In(loc).Format("15:04")
+	}
+
 	return bookings, nil
 }
 
+// GetMentorBookedSessions lists the mentor's confirmed sessions with times
+// shown in `tz` (the mentor's own when empty)
 func (s *BookingService) GetMentorBookedSessions(
-	mentorID uuid.UUID,
+	mentor *models.MentorProfile,
+	tz string,
 ) ([]*dtos.MentorBookedSessionResponse, error) {
 
-	sessions, err := s.bookingRepo.GetByMentorIDConfirmed(mentorID)
+	loc, err := loadLocation(tz, mentorLocation(mentor))
 	if err != nil {
 		return nil, err
 	}
 
+	sessions, err := s.bookingRepo.GetByMentorIDConfirmed(mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return localizeSessions(sessions, loc), nil
+}
+
+func localizeSessions(
+	sessions []*dtos.MentorBookedSessionResponse,
+	loc *time.Location,
+) []*dtos.MentorBookedSessionResponse {
+
 	if sessions == nil {
-		return []*dtos.MentorBookedSessionResponse{}, nil
+		return []*dtos.MentorBookedSessionResponse{}
+	}
+
+	for _, b := range sessions {
+		b.Timezone = loc.String()
+		b.BookingDate = b.StartsAt.In(loc).Format("2006-01-02")
+		b.StartTime = b.StartsAt.In(loc).Format("15:04")
+		b.EndTime = b.EndsAt.In(loc).Format("15:04")
+	}
+
+	return sessions
+}
+
+// CancelBooking cancels a pending or confirmed booking on behalf of either
+// the learner or the mentor and reports the refund the policy allows.
+func (s *BookingService) CancelBooking(
+	userID uuid.UUID,
+	bookingID uuid.UUID,
+	req *dtos.CancelBookingRequest,
+) (*dtos.CancelBookingResponse, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		booking *models.Booking
+		actor   string
+	)
+
+	err := s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+
+		booking, err = s.bookingRepo.GetByIDForUpdateTx(ctx, tx, bookingID)
+		if err != nil {
+			return err
+		}
+
+		actor, err = s.resolveActor(userID, booking)
+		if err != nil {
+			return err
+		}
+
+		if booking.Status != models.BookingStatusRequested &&
+			booking.Status != models.BookingStatusPending &&
+			booking.Status != models.BookingStatusConfirmed {
+			return errors.New("booking cannot be cancelled")
+		}
+
+		if !booking.StartsAt.After(time.Now()) {
+			return errors.New("session already started")
+		}
+
+		if err := s.bookingRepo.CancelTx(ctx, tx, booking.ID, actor, req.Reason); err != nil {
+			return err
+		}
+
+		if booking.PackagePurchaseID != nil {
+			if err := s.packageRepo.ReturnCreditTx(ctx, tx, *booking.PackagePurchaseID); err != nil {
+				return err
+			}
+		}
+
+		return s.promoService.ReleaseTx(ctx, tx, booking.ID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.waitlist.SlotOpened(booking.MentorID)
+
+	// Only paid (confirmed) bookings have anything to refund; package
+	// sessions get their credit back instead
+	decision := RefundDecision{Type: RefundTypeNone}
+	if booking.Status == models.BookingStatusConfirmed && booking.PackagePurchaseID == nil {
+		if actor == "mentor" {
+			decision = RefundInFull(booking.PriceCents)
+		} else {
+			decision = s.policy.Decide(booking.StartsAt, time.Now(), booking.PriceCents)
+		}
+	}
+
+	resp := &dtos.CancelBookingResponse{
+		ID:             booking.ID,
+		Status:         string(models.BookingStatusCancelled),
+		CancelledBy:    actor,
+		RefundType:     string(decision.Type),
+		RefundPercent:  decision.Percent,
+		RefundCents:    decision.Cents,
+		CreditReturned: booking.PackagePurchaseID != nil,
+		Currency:       booking.Currency,
+	}
+
+	// The cancellation stands even if the gateway refuses the refund;
+	// the failed refund record is left for finance to retry.
+	if decision.Cents > 0 {
+		refund, err := s.paymentService.RefundBooking(
+			context.Background(),
+			booking.ID,
+			int64(decision.Cents),
+			"cancelled_by_"+actor,
+		)
+		switch {
+		case refund != nil:
+			resp.RefundStatus = string(refund.Status)
+			resp.RefundCents = int(refund.Amount)
+		case err != nil:
+			resp.RefundStatus = string(models.RefundStatusFailed)
+		}
 	}
 
-	return sessions, nil
+	return resp, nil
+}
+
+// RescheduleBooking moves a booking to a new slot after re-checking the
+// mentor's availability rules and existing bookings.
+func (s *BookingService) RescheduleBooking(
+	userID uuid.UUID,
+	bookingID uuid.UUID,
+	req *dtos.RescheduleBookingRequest,
+) (*dtos.BookingResponse, error) {
+
+	current, err := s.bookingRepo.GetByID(context.Background(), bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := s.resolveActor(userID, current)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := s.serviceRepo.FindByID(current.ServiceID)
+	if err != nil {
+		return nil, errors.New("invalid service")
+	}
+
+	// A seat can't leave its group session on its own
+	if service.IsGroup() {
+		return nil, errors.New("group session seats cannot be rescheduled")
+	}
+
+	mentor, err := s.mentorRepo.FindByID(current.MentorID)
+	if err != nil {
+		return nil, errors.New("mentor not available")
+	}
+
+	loc, err := loadLocation(req.Timezone, mentorLocation(mentor))
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := parseLocalTime(req.BookingDate, req.StartTime, loc)
+	if err != nil {
+		return nil, err
+	}
+	end := start.Add(time.Duration(service.DurationMinutes) * time.Minute)
+
+	if !start.After(time.Now()) {
+		return nil, errors.New("new slot is in the past")
+	}
+
+	// Notice and horizon protect the mentor from learners; the mentor
+	// may move their own sessions anywhere they are available
+	if actor == "user" {
+		if err := checkLeadTime(mentor, start, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.validateAgainstRules(mentor, start, end); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var booking *models.Booking
+
+	err = s.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+
+		booking, err = s.bookingRepo.GetByIDForUpdateTx(ctx, tx, bookingID)
+		if err != nil {
+			return err
+		}
+
+		if booking.Status != models.BookingStatusRequested &&
+			booking.Status != models.BookingStatusPending &&
+			booking.Status != models.BookingStatusConfirmed {
+			return errors.New("booking cannot be rescheduled")
+		}
+
+		// Mentors may move a session up to its start, learners only
+		// while the policy still allows it
+		now := time.Now()
+		if !booking.StartsAt.After(now) {
+			return errors.New("session already started")
+		}
+		if actor == "user" && !s.policy.CanReschedule(booking.StartsAt, now) {
+			return errors.New("too late to reschedule this booking")
+		}
+
+		if err := s.mentorRepo.LockTx(ctx, tx, booking.MentorID); err != nil {
+			return err
+		}
+
+		if err := s.checkDailyCapTx(ctx, tx, mentor, booking.ID, start); err != nil {
+			return err
+		}
+
+		buffer := sessionBuffer(mentor)
+
+		conflict, err := s.bookingRepo.HasConflictTx(
+			ctx,
+			tx,
+			booking.MentorID,
+			booking.ID,
+			"",
+			start.Add(-buffer),
+			end.Add(buffer),
+		)
+		if err != nil {
+			return err
+		}
+
+		if conflict {
+			return ErrSlotTaken
+		}
+
+		if err := s.bookingRepo.RescheduleTx(ctx, tx, booking.ID, start, end); err != nil {
+			return err
+		}
+
+		booking.StartsAt = start.UTC()
+		booking.EndsAt = end.UTC()
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The old slot is free again
+	s.waitlist.SlotOpened(booking.MentorID)
+
+	return toBookingResponse(booking, loc), nil
+}
+
+// validateAgainstRules checks that [start, end) fits inside one of the
+// mentor's availability windows - weekly rules merged with date
+// overrides, read in the mentor's timezone.
+func (s *BookingService) validateAgainstRules(
+	mentor *models.MentorProfile,
+	start time.Time,
+	end time.Time,
+) error {
+
+	windows, err := loadWindows(context.Background(), s.availabilityRepo, mentor, start, end)
+	if err != nil {
+		return err
+	}
+
+	if !fitsWindows(windows, start, end) {
+		return errors.New("selected slot outside availability")
+	}
+
+	return nil
+}
+
+// checkDailyCapTx fails when the mentor's day containing `start` already
+// holds their daily session cap. The mentor's profile stays locked until
+// the transaction ends so concurrent bookings can't both take the last
+// session of the day.
+func (s *BookingService) checkDailyCapTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentor *models.MentorProfile,
+	excludeBookingID uuid.UUID,
+	start time.Time,
+) error {
+
+	if mentor.DailySessionCap == 0 {
+		return nil
+	}
+
+	if err := s.mentorRepo.LockTx(ctx, tx, mentor.ID); err != nil {
+		return err
+	}
+
+	dayStart, dayEnd := mentorDay(start, mentorLocation(mentor))
+
+	count, err := s.bookingRepo.CountForMentorBetweenTx(ctx, tx, mentor.ID, excludeBookingID, dayStart, dayEnd)
+	if err != nil {
+		return err
+	}
+
+	if count >= mentor.DailySessionCap {
+		return ErrDailyCapReached
+	}
+
+	return nil
+}
+
+// resolveActor tells whether userID is the learner ("user") or the
+// mentor ("mentor") of the booking. bookings.mentor_id holds the mentor
+// profile ID, so mentors are resolved through their profile.
+func (s *BookingService) resolveActor(
+	userID uuid.UUID,
+	booking *models.Booking,
+) (string, error) {
+
+	if booking.UserID == userID {
+		return "user", nil
+	}
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err == nil && mentor.ID == booking.MentorID {
+		return "mentor", nil
+	}
+
+	return "", errors.New("unauthorized")
+}
+
+// toBookingResponse shows the booking's times in loc
+func toBookingResponse(b *models.Booking, loc *time.Location) *dtos.BookingResponse {
+	return &dtos.BookingResponse{
+		ID:             b.ID,
+		Status:         string(b.Status),
+		StartsAt:       b.StartsAt,
+		EndsAt:         b.EndsAt,
+		Timezone:       loc.String(),
+		Date:           b.StartsAt.In(loc).Format("2006-01-02"),
+		StartTime:      b.StartsAt.In(loc).Format("15:04"),
+		EndTime:        b.EndsAt.In(loc).Format("15:04"),
+		Price:          b.PriceCents,
+		DiscountCents:  b.DiscountCents,
+		Currency:       b.Currency,
+		FormattedPrice: utils.FormatMoney(int64(b.PriceCents), b.Currency),
+	}
 }
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+)
+
+// calendarFeedHistory is how far back feeds keep past sessions
+const calendarFeedHistory = 30 * 24 * time.Hour
+
+// CalendarService renders bookings as iCalendar data: a secret feed URL
+// per user that calendar apps subscribe to, and a downloadable invite per
+// booking. Events use the booking ID as UID, so every rendering of a
+// booking updates the same event.
+type CalendarService struct {
+	calendarRepo *repositories.CalendarRepository
+}
+
+func NewCalendarService(calendarRepo *repositories.CalendarRepository) *CalendarService {
+	return &CalendarService{calendarRepo: calendarRepo}
+}
+
+// CreateFeedToken issues a new feed token for the user. Any previous
+// token stops working, which is how a leaked feed URL is revoked.
+func (s *CalendarService) CreateFeedToken(
+	ctx context.Context,
+	userID uuid.UUID,
+) (string, error) {
+
+	token, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.calendarRepo.SaveFeed(ctx, &models.CalendarFeed{
+		UserID:    userID,
+		TokenHash: utils.HashRefreshToken(token),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *CalendarService) DeleteFeed(ctx context.Context, userID uuid.UUID) error {
+	return s.calendarRepo.DeleteFeed(ctx, userID)
+}
+
+// Feed renders the confirmed sessions of the user owning `token`, both
+// the ones they attend and the ones they mentor. Past sessions stay in
+// the feed after they are settled.
+func (s *CalendarService) Feed(ctx context.Context, token string) ([]byte, error) {
+	userID, err := s.calendarRepo.FindFeedUser(ctx, utils.HashRefreshToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("calendar feed not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	entries, err := s.calendarRepo.FindEntriesForUser(ctx, userID, now.Add(-calendarFeedHistory))
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &utils.ICalendar{Name: "OpenCall sessions"}
+	for _, e := range entries {
+		cal.Events = append(cal.Events, calendarEvent(e, userID, now))
+	}
+
+	return cal.Bytes(), nil
+}
+
+// BookingInvite renders a single booking for its learner or mentor. A
+// cancelled booking comes out as METHOD:CANCEL so importing it removes
+// the event added earlier.
+func (s *CalendarService) BookingInvite(
+	ctx context.Context,
+	userID uuid.UUID,
+	bookingID uuid.UUID,
+) ([]byte, error) {
+
+	e, err := s.calendarRepo.GetEntry(ctx, bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("booking not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != e.LearnerUserID && userID != e.MentorUserID {
+		return nil, errors.New("unauthorized")
+	}
+
+	cal := &utils.ICalendar{}
+
+	switch e.Status {
+	case models.BookingStatusConfirmed,
+		models.BookingStatusCompleted,
+		models.BookingStatusNoShowMentor,
+		models.BookingStatusNoShowUser,
+		models.BookingStatusIncomplete:
+		cal.Method = utils.ICalMethodRequest
+	case models.BookingStatusCancelled:
+		cal.Method = utils.ICalMethodCancel
+	default:
+		return nil, errors.New("booking is not confirmed")
+	}
+
+	event := calendarEvent(e, userID, time.Now())
+	event.OrganizerName = e.MentorName
+	event.OrganizerEmail = e.MentorEmail
+	event.AttendeeName = e.LearnerName
+	event.AttendeeEmail = e.LearnerEmail
+
+	cal.Events = []utils.ICalEvent{event}
+
+	return cal.Bytes(), nil
+}
+
+// calendarEvent describes the booking from the point of view of `viewer`
+func calendarEvent(
+	e *models.BookingCalendarEntry,
+	viewer uuid.UUID,
+	now time.Time,
+) utils.ICalEvent {
+
+	other := e.MentorName
+	if viewer == e.MentorUserID {
+		other = e.LearnerName
+	}
+
+	status := utils.ICalStatusConfirmed
+	if e.Status == models.BookingStatusCancelled {
+		status = utils.ICalStatusCancelled
+	}
+
+	return utils.ICalEvent{
+		UID: e.BookingID.String(),
+		// Every change to a booking bumps updated_at, so seconds since
+		// creation only ever grow
+		Sequence:    int(e.UpdatedAt.Sub(e.CreatedAt) / time.Second),
+		Stamp:       now,
+		Start:       e.StartsAt,
+		End:         e.EndsAt,
+		Summary:     e.ServiceTitle + " with " + other,
+		Description: "OpenCall session. Booking " + e.BookingID.String(),
+		Status:      status,
+	}
+}
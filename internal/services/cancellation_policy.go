@@ -0,0 +1,78 @@
+package services
+
+import (
+	"time"
+)
+
+type RefundType string
+
+const (
+	RefundTypeFull    RefundType = "full"
+	RefundTypePartial RefundType = "partial"
+	RefundTypeNone    RefundType = "none"
+)
+
+// CancellationPolicy decides how much of a booking is refunded depending on
+// how much notice the learner gives before the session starts.
+type CancellationPolicy struct {
+	// Cancelling at least this long before the start refunds everything
+	FullRefundNotice time.Duration
+
+	// Cancelling at least this long before the start refunds PartialRefundPercent
+	PartialRefundNotice  time.Duration
+	PartialRefundPercent int
+
+	// Learners can't move a session that starts sooner than this
+	RescheduleNotice time.Duration
+}
+
+// DefaultCancellationPolicy: 24h+ full refund, 6h+ half refund, otherwise nothing
+var DefaultCancellationPolicy = CancellationPolicy{
+	FullRefundNotice:     24 * time.Hour,
+	PartialRefundNotice:  6 * time.Hour,
+	PartialRefundPercent: 50,
+	RescheduleNotice:     6 * time.Hour,
+}
+
+type RefundDecision struct {
+	Type    RefundType
+	Percent int
+	Cents   int
+}
+
+// Decide returns the refund for a learner cancellation at `now`.
+// Mentor cancellations always refund in full, see RefundInFull.
+func (p CancellationPolicy) Decide(
+	sessionStart time.Time,
+	now time.Time,
+	priceCents int,
+) RefundDecision {
+
+	notice := sessionStart.Sub(now)
+
+	switch {
+	case notice >= p.FullRefundNotice:
+		return RefundInFull(priceCents)
+	case notice >= p.PartialRefundNotice:
+		return RefundDecision{
+			Type:    RefundTypePartial,
+			Percent: p.PartialRefundPercent,
+			Cents:   priceCents * p.PartialRefundPercent / 100,
+		}
+	default:
+		return RefundDecision{Type: RefundTypeNone}
+	}
+}
+
+// CanReschedule reports whether a learner may still move the session
+func (p CancellationPolicy) CanReschedule(sessionStart time.Time, now time.Time) bool {
+	return sessionStart.Sub(now) >= p.RescheduleNotice
+}
+
+func RefundInFull(priceCents int) RefundDecision {
+	return RefundDecision{
+		Type:    RefundTypeFull,
+		Percent: 100,
+		Cents:   priceCents,
+	}
+}
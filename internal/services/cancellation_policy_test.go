@@ -0,0 +1,96 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancellationPolicyDecide(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		notice time.Duration
+		price  int
+		want   RefundDecision
+	}{
+		{
+			name:   "days ahead",
+			notice: 72 * time.Hour,
+			price:  10000,
+			want:   RefundDecision{Type: RefundTypeFull, Percent: 100, Cents: 10000},
+		},
+		{
+			name:   "exactly the full refund notice",
+			notice: 24 * time.Hour,
+			price:  10000,
+			want:   RefundDecision{Type: RefundTypeFull, Percent: 100, Cents: 10000},
+		},
+		{
+			name:   "just under the full refund notice",
+			notice: 24*time.Hour - time.Second,
+			price:  10000,
+			want:   RefundDecision{Type: RefundTypePartial, Percent: 50, Cents: 5000},
+		},
+		{
+			name:   "exactly the partial refund notice",
+			notice: 6 * time.Hour,
+			price:  10000,
+			want:   RefundDecision{Type: RefundTypePartial, Percent: 50, Cents: 5000},
+		},
+		{
+			name:   "partial refunds round down",
+			notice: 12 * time.Hour,
+			price:  999,
+			want:   RefundDecision{Type: RefundTypePartial, Percent: 50, Cents: 499},
+		},
+		{
+			name:   "just under the partial refund notice",
+			notice: 6*time.Hour - time.Second,
+			price:  10000,
+			want:   RefundDecision{Type: RefundTypeNone},
+		},
+		{
+			name:   "after the start",
+			notice: -time.Hour,
+			price:  10000,
+			want:   RefundDecision{Type: RefundTypeNone},
+		},
+		{
+			name:   "free session",
+			notice: 48 * time.Hour,
+			price:  0,
+			want:   RefundDecision{Type: RefundTypeFull, Percent: 100, Cents: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultCancellationPolicy.Decide(start, start.Add(-tt.notice), tt.price)
+			if got != tt.want {
+				t.Errorf("Decide = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancellationPolicyCanReschedule(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		notice time.Duration
+		want   bool
+	}{
+		{notice: 24 * time.Hour, want: true},
+		{notice: 6 * time.Hour, want: true},
+		{notice: 6*time.Hour - time.Second, want: false},
+		{notice: -time.Minute, want: false},
+	}
+
+	for _, tt := range tests {
+		got := DefaultCancellationPolicy.CanReschedule(start, start.Add(-tt.notice))
+		if got != tt.want {
+			t.Errorf("CanReschedule with %s notice = %v, want %v", tt.notice, got, tt.want)
+		}
+	}
+}
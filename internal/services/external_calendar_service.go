@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// How often every external calendar is read again
+	externalSyncEvery = 15 * time.Minute
+
+	// Busy blocks are kept from a day back to a year ahead
+	externalSyncHorizon = 366 * 24 * time.Hour
+
+	// externalSyncBatchSize caps how many calendars one claim hands out
+	externalSyncBatchSize = 20
+
+	// MaxExternalCalendarBytes caps feeds and uploads
+	MaxExternalCalendarBytes = 5 << 20
+)
+
+// ExternalCalendarService imports mentors' other calendars so their
+// events block OpenCall availability. Subscribed URLs and uploaded files
+// are both re-read in the background - uploads too, so their recurring
+// events keep being expanded as time moves on.
+type ExternalCalendarService struct {
+	externalRepo *repositories.ExternalCalendarRepository
+	mentorRepo   *repositories.MentorRepository
+
+	client   *http.Client
+	interval time.Duration
+}
+
+func NewExternalCalendarService(
+	externalRepo *repositories.ExternalCalendarRepository,
+	mentorRepo *repositories.MentorRepository,
+	client *http.Client,
+	interval time.Duration,
+) *ExternalCalendarService {
+	return &ExternalCalendarService{
+		externalRepo: externalRepo,
+		mentorRepo:   mentorRepo,
+		client:       client,
+		interval:     interval,
+	}
+}
+
+// NewExternalCalendarHTTPClient is the client for fetching mentors'
+// calendar URLs. Mentors choose the URL, so it refuses to connect to
+// loopback, private and link-local addresses - redirects included.
+func NewExternalCalendarHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+				return fmt.Errorf("address %s is not allowed", host)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: 20 * time.Second,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// AddURL subscribes the mentor to a calendar URL. The calendar is read
+// once up front so a wrong URL is reported straight away.
+func (s *ExternalCalendarService) AddURL(
+	ctx context.Context,
+	userID uuid.UUID,
+	req *dtos.ExternalCalendarRequest,
+) (*dtos.ExternalCalendarResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := normalizeCalendarURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.fetch(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &models.ExternalCalendar{
+		ID:        uuid.New(),
+		MentorID:  mentor.ID,
+		Name:      strings.TrimSpace(req.Name),
+		SourceURL: &source,
+	}
+
+	return s.create(ctx, mentor, cal, data)
+}
+
+// Upload imports an .ics file
+func (s *ExternalCalendarService) Upload(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	data []byte,
+) (*dtos.ExternalCalendarResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, errors.New("name must be 1 to 100 characters")
+	}
+
+	content := string(data)
+	cal := &models.ExternalCalendar{
+		ID:       uuid.New(),
+		MentorID: mentor.ID,
+		Name:     name,
+		ICSData:  &content,
+	}
+
+	return s.create(ctx, mentor, cal, data)
+}
+
+func (s *ExternalCalendarService) create(
+	ctx context.Context,
+	mentor *models.MentorProfile,
+	cal *models.ExternalCalendar,
+	data []byte,
+) (*dtos.ExternalCalendarResponse, error) {
+
+	blocks, err := busyBlocks(cal, mentor, data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.externalRepo.Create(ctx, cal); err != nil {
+		return nil, err
+	}
+
+	if err := s.externalRepo.ReplaceBlocks(ctx, cal, blocks); err != nil {
+		return nil, err
+	}
+
+	return toExternalCalendarResponse(cal), nil
+}
+
+func (s *ExternalCalendarService) List(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]dtos.ExternalCalendarResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars, err := s.externalRepo.ListByMentor(ctx, mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dtos.ExternalCalendarResponse, 0, len(calendars))
+	for _, cal := range calendars {
+		resp = append(resp, *toExternalCalendarResponse(cal))
+	}
+
+	return resp, nil
+}
+
+func (s *ExternalCalendarService) Delete(
+	ctx context.Context,
+	userID uuid.UUID,
+	id uuid.UUID,
+) error {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.externalRepo.Delete(ctx, mentor.ID, id)
+}
+
+// SyncNow reads a calendar again without waiting for the worker
+func (s *ExternalCalendarService) SyncNow(
+	ctx context.Context,
+	userID uuid.UUID,
+	id uuid.UUID,
+) (*dtos.ExternalCalendarResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cal, err := s.externalRepo.GetByID(ctx, mentor.ID, id)
+	if err != nil {
+		return nil, errors.New("calendar not found")
+	}
+
+	if err := s.sync(ctx, cal, mentor); err != nil {
+		return nil, err
+	}
+
+	return toExternalCalendarResponse(cal), nil
+}
+
+// Run syncs due calendars on every tick until ctx is cancelled
+func (s *ExternalCalendarService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.SyncOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("external calendar sync failed")
+			}
+		}
+	}
+}
+
+// SyncOnce syncs every due calendar and returns how many it tried. A
+// calendar that fails keeps its previous busy blocks.
+func (s *ExternalCalendarService) SyncOnce(ctx context.Context) (int, error) {
+	total := 0
+
+	for {
+		calendars, err := s.externalRepo.ClaimDue(ctx, externalSyncEvery, externalSyncBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for _, cal := range calendars {
+			mentor, err := s.mentorRepo.FindByID(cal.MentorID)
+			if err == nil {
+				err = s.sync(ctx, cal, mentor)
+			}
+
+			if err != nil {
+				log.Warn().
+					Err(err).
+					Str("calendar_id", cal.ID.String()).
+					Msg("external calendar not synced")
+			}
+		}
+
+		total += len(calendars)
+
+		if len(calendars) < externalSyncBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// sync reads the calendar and replaces its busy blocks, recording the
+// error on the calendar when that fails
+func (s *ExternalCalendarService) sync(
+	ctx context.Context,
+	cal *models.ExternalCalendar,
+	mentor *models.MentorProfile,
+) error {
+
+	var (
+		data []byte
+		err  error
+	)
+
+	if cal.SourceURL != nil {
+		data, err = s.fetch(ctx, *cal.SourceURL)
+	} else if cal.ICSData != nil {
+		data = []byte(*cal.ICSData)
+	}
+
+	var blocks []models.ExternalBusyBlock
+	if err == nil {
+		blocks, err = busyBlocks(cal, mentor, data, time.Now())
+	}
+
+	if err == nil {
+		err = s.externalRepo.ReplaceBlocks(ctx, cal, blocks)
+	}
+
+	if err != nil {
+		reason := err.Error()
+		cal.LastError = &reason
+
+		if markErr := s.externalRepo.MarkSyncFailed(ctx, cal.ID, reason); markErr != nil {
+			return markErr
+		}
+	}
+
+	return err
+}
+
+func (s *ExternalCalendarService) fetch(ctx context.Context, source string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.New("cannot fetch calendar: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot fetch calendar: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxExternalCalendarBytes+1))
+	if err != nil {
+		return nil, errors.New("cannot fetch calendar: " + err.Error())
+	}
+
+	if len(data) > MaxExternalCalendarBytes {
+		return nil, errors.New("calendar is too large")
+	}
+
+	return data, nil
+}
+
+// busyBlocks expands the calendar's events from a day ago up to the
+// sync horizon. Floating times are read in the mentor's timezone.
+func busyBlocks(
+	cal *models.ExternalCalendar,
+	mentor *models.MentorProfile,
+	data []byte,
+	now time.Time,
+) ([]models.ExternalBusyBlock, error) {
+
+	busy, err := utils.ParseICalBusy(
+		data,
+		mentorLocation(mentor),
+		now.Add(-24*time.Hour),
+		now.Add(externalSyncHorizon),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]models.ExternalBusyBlock, 0, len(busy))
+	for _, b := range busy {
+		blocks = append(blocks, models.ExternalBusyBlock{
+			CalendarID: cal.ID,
+			MentorID:   cal.MentorID,
+			StartsAt:   b.Start.UTC(),
+			EndsAt:     b.End.UTC(),
+		})
+	}
+
+	return blocks, nil
+}
+
+// normalizeCalendarURL accepts http(s) URLs and turns webcal:// links,
+// which calendar apps hand out for subscriptions, into https
+func normalizeCalendarURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return "", errors.New("invalid calendar url")
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", errors.New("calendar url must be http, https or webcal")
+	}
+
+	return u.String(), nil
+}
+
+func toExternalCalendarResponse(cal *models.ExternalCalendar) *dtos.ExternalCalendarResponse {
+	resp := &dtos.ExternalCalendarResponse{
+		ID:           cal.ID,
+		Name:         cal.Name,
+		Source:       "upload",
+		LastSyncedAt: cal.LastSyncedAt,
+		LastError:    cal.LastError,
+		CreatedAt:    cal.CreatedAt,
+	}
+
+	if cal.SourceURL != nil {
+		resp.Source = "url"
+		resp.URL = *cal.SourceURL
+	}
+
+	return resp
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+)
+
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeGateway is an in-process PaymentGateway for offline end-to-end runs.
+// It keeps orders in memory and signs its own webhooks with `secret`, so
+// the booking -> payment -> confirmation flow can be driven without
+// talking to a real provider.
+type FakeGateway struct {
+	secret string
+
+	mu     sync.Mutex
+	seq    int
+	orders map[string]*fakeOrder
+}
+
+type fakeOrder struct {
+	ID        string
+	Amount    int64
+	Currency  string
+	Receipt   string
+	PaymentID string
+	Status    string // created | paid | failed
+}
+
+// fakeWebhookEvent is the wire format of FakeGateway webhooks
+type fakeWebhookEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	OrderID   string `json:"order_id,omitempty"`
+	PaymentID string `json:"payment_id,omitempty"`
+	RefundID  string `json:"refund_id,omitempty"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Receipt   string `json:"receipt,omitempty"`
+}
+
+// FakeWebhook is a signed webhook ready to be POSTed to /api/webhooks/fake
+type FakeWebhook struct {
+	Payload []byte
+	Header  http.Header
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{
+		secret: secret,
+		orders: make(map[string]*fakeOrder),
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+// ValidateAmount accepts any positive amount in a known ISO-4217 currency
+func (g *FakeGateway) ValidateAmount(amount int64, currency string) error {
+	if err := utils.ValidateCurrency(currency); err != nil {
+		return err
+	}
+
+	if amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+
+	return nil
+}
+
+func (g *FakeGateway) CreateOrder(amount int64, currency string, receipt string) (string, error) {
+	if err := g.ValidateAmount(amount, currency); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := g.nextID("order_fake")
+	g.orders[id] = &fakeOrder{
+		ID:       id,
+		Amount:   amount,
+		Currency: currency,
+		Receipt:  receipt,
+		Status:   "created",
+	}
+
+	return id, nil
+}
+
+func (g *FakeGateway) VerifyPaymentSignature(
+	orderID string,
+	paymentID string,
+	signature string,
+) error {
+
+	if !validHMAC(g.secret, []byte(orderID+"|"+paymentID), signature) {
+		return errors.New("invalid payment signature")
+	}
+	return nil
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, header http.Header) error {
+	if !validHMAC(g.secret, payload, header.Get(FakeSignatureHeader)) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
+
+func (g *FakeGateway) ParseWebhook(
+	payload []byte,
+	header http.Header,
+) (*GatewayEvent, error) {
+
+	var event fakeWebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	return &GatewayEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		OrderID:   event.OrderID,
+		PaymentID: event.PaymentID,
+		RefundID:  event.RefundID,
+		Amount:    event.Amount,
+		Currency:  event.Currency,
+		Receipt:   event.Receipt,
+	}, nil
+}
+
+// CreateRefund always succeeds immediately
+func (g *FakeGateway) CreateRefund(
+	paymentID string,
+	amount int64,
+	receipt string,
+) (*GatewayRefund, error) {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.orderByPayment(paymentID) == nil {
+		return nil, errors.New("unknown payment")
+	}
+
+	return &GatewayRefund{
+		ID:     g.nextID("rfnd_fake"),
+		Status: "processed",
+	}, nil
+}
+
+func (g *FakeGateway) FetchOrder(orderID string) (*GatewayOrder, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[orderID]
+	if !ok {
+		return nil, errors.New("unknown order")
+	}
+
+	status := GatewayOrderOpen
+	switch order.Status {
+	case "paid":
+		status = GatewayOrderPaid
+	case "failed":
+		status = GatewayOrderFailed
+	}
+
+	return &GatewayOrder{
+		ID:        order.ID,
+		Status:    status,
+		PaymentID: order.PaymentID,
+		Amount:    order.Amount,
+		Currency:  order.Currency,
+	}, nil
+}
+
+// CapturePayment simulates the learner paying for an order. It returns
+// the payment ID and checkout signature (what the client would send to
+// /api/payments/verify) plus the signed payment.captured webhook.
+func (g *FakeGateway) CapturePayment(orderID string) (string, string, *FakeWebhook, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[orderID]
+	if !ok {
+		return "", "", nil, errors.New("unknown order")
+	}
+
+	if order.PaymentID == "" {
+		order.PaymentID = g.nextID("pay_fake")
+	}
+	order.Status = "paid"
+
+	signature := signHMAC(g.secret, []byte(order.ID+"|"+order.PaymentID))
+
+	webhook, err := g.signedWebhook(fakeWebhookEvent{
+		ID:        g.nextID("evt_fake"),
+		Type:      GatewayEventPaymentCaptured,
+		OrderID:   order.ID,
+		PaymentID: order.PaymentID,
+		Amount:    order.Amount,
+		Currency:  order.Currency,
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return order.PaymentID, signature, webhook, nil
+}
+
+// FailPayment simulates a declined payment and returns the signed payment.failed webhook
+func (g *FakeGateway) FailPayment(orderID string) (*FakeWebhook, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[orderID]
+	if !ok {
+		return nil, errors.New("unknown order")
+	}
+
+	if order.PaymentID == "" {
+		order.PaymentID = g.nextID("pay_fake")
+	}
+	order.Status = "failed"
+
+	return g.signedWebhook(fakeWebhookEvent{
+		ID:        g.nextID("evt_fake"),
+		Type:      GatewayEventPaymentFailed,
+		OrderID:   order.ID,
+		PaymentID: order.PaymentID,
+		Amount:    order.Amount,
+		Currency:  order.Currency,
+	})
+}
+
+func (g *FakeGateway) signedWebhook(event fakeWebhookEvent) (*FakeWebhook, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, signHMAC(g.secret, payload))
+
+	return &FakeWebhook{Payload: payload, Header: header}, nil
+}
+
+// caller must hold g.mu
+func (g *FakeGateway) orderByPayment(paymentID string) *fakeOrder {
+	for _, o := range g.orders {
+		if o.PaymentID == paymentID {
+			return o
+		}
+	}
+	return nil
+}
+
+// caller must hold g.mu
+func (g *FakeGateway) nextID(prefix string) string {
+	g.seq++
+	return fmt.Sprintf("%s_%06d", prefix, g.seq)
+}
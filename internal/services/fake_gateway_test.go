@@ -0,0 +1,167 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Unit tests of FakeGateway on its own. They check what the fake hands
+// back at each step of an order, not PaymentService or the webhook inbox,
+// which need a database.
+
+// The fake must stay usable wherever a real gateway is expected
+var _ PaymentGateway = (*FakeGateway)(nil)
+
+// TestFakeGatewayOrderLifecycle covers a single order: the signature the
+// client would verify, the captured webhook, the order as reconciliation
+// fetches it, and a refund.
+func TestFakeGatewayOrderLifecycle(t *testing.T) {
+	var gateway PaymentGateway = NewFakeGateway("test-secret")
+	fake := gateway.(*FakeGateway)
+
+	orderID, err := gateway.CreateOrder(150000, "INR", "receipt-1")
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	order, err := gateway.FetchOrder(orderID)
+	if err != nil {
+		t.Fatalf("FetchOrder: %v", err)
+	}
+	if order.Status != GatewayOrderOpen {
+		t.Fatalf("new order status = %s, want %s", order.Status, GatewayOrderOpen)
+	}
+
+	paymentID, signature, webhook, err := fake.CapturePayment(orderID)
+	if err != nil {
+		t.Fatalf("CapturePayment: %v", err)
+	}
+
+	if err := gateway.VerifyPaymentSignature(orderID, paymentID, signature); err != nil {
+		t.Fatalf("VerifyPaymentSignature: %v", err)
+	}
+
+	if err := gateway.VerifyWebhook(webhook.Payload, webhook.Header); err != nil {
+		t.Fatalf("VerifyWebhook: %v", err)
+	}
+
+	event, err := gateway.ParseWebhook(webhook.Payload, webhook.Header)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+
+	want := GatewayEvent{
+		ID:        event.ID,
+		Type:      GatewayEventPaymentCaptured,
+		OrderID:   orderID,
+		PaymentID: paymentID,
+		Amount:    150000,
+		Currency:  "INR",
+	}
+	if event.ID == "" || *event != want {
+		t.Fatalf("captured event = %+v, want %+v", *event, want)
+	}
+
+	order, err = gateway.FetchOrder(orderID)
+	if err != nil {
+		t.Fatalf("FetchOrder: %v", err)
+	}
+	if order.Status != GatewayOrderPaid || order.PaymentID != paymentID || order.Amount != 150000 {
+		t.Fatalf("paid order = %+v", *order)
+	}
+
+	refund, err := gateway.CreateRefund(paymentID, 75000, "refund-1")
+	if err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+	if refund.ID == "" || refund.Status != "processed" {
+		t.Fatalf("refund = %+v", *refund)
+	}
+}
+
+func TestFakeGatewayDeclinedPayment(t *testing.T) {
+	fake := NewFakeGateway("test-secret")
+
+	orderID, err := fake.CreateOrder(5000, "USD", "receipt-1")
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	webhook, err := fake.FailPayment(orderID)
+	if err != nil {
+		t.Fatalf("FailPayment: %v", err)
+	}
+
+	if err := fake.VerifyWebhook(webhook.Payload, webhook.Header); err != nil {
+		t.Fatalf("VerifyWebhook: %v", err)
+	}
+
+	event, err := fake.ParseWebhook(webhook.Payload, webhook.Header)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != GatewayEventPaymentFailed || event.OrderID != orderID {
+		t.Fatalf("failed event = %+v", *event)
+	}
+
+	order, err := fake.FetchOrder(orderID)
+	if err != nil {
+		t.Fatalf("FetchOrder: %v", err)
+	}
+	if order.Status != GatewayOrderFailed {
+		t.Fatalf("declined order status = %s, want %s", order.Status, GatewayOrderFailed)
+	}
+}
+
+func TestFakeGatewayRejectsForgeries(t *testing.T) {
+	fake := NewFakeGateway("test-secret")
+	other := NewFakeGateway("other-secret")
+
+	orderID, err := fake.CreateOrder(5000, "USD", "receipt-1")
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	paymentID, signature, webhook, err := fake.CapturePayment(orderID)
+	if err != nil {
+		t.Fatalf("CapturePayment: %v", err)
+	}
+
+	if err := fake.VerifyPaymentSignature(orderID, "pay_other", signature); err == nil {
+		t.Error("signature accepted for another payment")
+	}
+
+	if err := other.VerifyPaymentSignature(orderID, paymentID, signature); err == nil {
+		t.Error("signature accepted under another secret")
+	}
+
+	tampered := append([]byte{}, webhook.Payload...)
+	tampered[len(tampered)-2] = 'X'
+	if err := fake.VerifyWebhook(tampered, webhook.Header); err == nil {
+		t.Error("tampered webhook accepted")
+	}
+
+	if err := fake.VerifyWebhook(webhook.Payload, http.Header{}); err == nil {
+		t.Error("unsigned webhook accepted")
+	}
+}
+
+func TestFakeGatewayValidation(t *testing.T) {
+	fake := NewFakeGateway("test-secret")
+
+	if _, err := fake.CreateOrder(0, "INR", "receipt-1"); err == nil {
+		t.Error("zero amount accepted")
+	}
+
+	if _, err := fake.CreateOrder(100, "XYZ", "receipt-1"); err == nil {
+		t.Error("unknown currency accepted")
+	}
+
+	if _, err := fake.CreateRefund("pay_unknown", 100, "refund-1"); err == nil {
+		t.Error("refund of an unknown payment accepted")
+	}
+
+	if _, err := fake.FetchOrder("order_unknown"); err == nil {
+		t.Error("unknown order fetched")
+	}
+}
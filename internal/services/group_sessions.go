@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+// ErrSessionFull is returned when every seat of a group session is taken
+var ErrSessionFull = errors.New("group session is full")
+
+// checkSeatTx makes sure the learner can take a seat in the group session
+// of `service` starting at `start`. The first seat opens the session, so
+// only it is checked against the daily cap; later seats just need the
+// session not to be full. The mentor's profile stays locked until the
+// transaction ends so two learners can't take the last seat.
+func (s *BookingService) checkSeatTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	mentor *models.MentorProfile,
+	service *models.MentorService,
+	userID uuid.UUID,
+	start time.Time,
+	end time.Time,
+) error {
+
+	if err := s.mentorRepo.LockTx(ctx, tx, mentor.ID); err != nil {
+		return err
+	}
+
+	taken, mine, err := s.bookingRepo.GroupSeatsTx(ctx, tx, service.ID, start, userID)
+	if err != nil {
+		return err
+	}
+
+	if mine {
+		return errors.New("you already have a seat in this session")
+	}
+
+	if taken >= service.Capacity {
+		return ErrSessionFull
+	}
+
+	if taken == 0 {
+		if err := s.checkDailyCapTx(ctx, tx, mentor, uuid.Nil, start); err != nil {
+			return err
+		}
+	}
+
+	buffer := sessionBuffer(mentor)
+
+	conflict, err := s.bookingRepo.HasGroupConflictTx(
+		ctx,
+		tx,
+		mentor.ID,
+		service.ID,
+		start,
+		start.Add(-buffer),
+		end.Add(buffer),
+	)
+	if err != nil {
+		return err
+	}
+
+	if conflict {
+		return ErrSlotTaken
+	}
+
+	return nil
+}
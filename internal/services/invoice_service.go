@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+)
+
+const invoiceSeller = "OpenCall"
+
+// InvoiceService issues a receipt for every captured payment and renders
+// it as JSON or PDF. Prices are tax-inclusive; the tax line shows the
+// tax contained in the total.
+type InvoiceService struct {
+	db          *sql.DB
+	invoiceRepo *repositories.InvoiceRepository
+	paymentRepo *repositories.PaymentRepository
+	mentorRepo  *repositories.MentorRepository
+
+	taxLabel   string
+	taxRateBps int
+}
+
+func NewInvoiceService(
+	db *sql.DB,
+	invoiceRepo *repositories.InvoiceRepository,
+	paymentRepo *repositories.PaymentRepository,
+	mentorRepo *repositories.MentorRepository,
+	taxLabel string,
+	taxRatePercent int,
+) *InvoiceService {
+	return &InvoiceService{
+		db:          db,
+		invoiceRepo: invoiceRepo,
+		paymentRepo: paymentRepo,
+		mentorRepo:  mentorRepo,
+		taxLabel:    taxLabel,
+		taxRateBps:  taxRatePercent * 100,
+	}
+}
+
+// IssueTx issues the invoice of a captured payment. Issuing twice is a no-op.
+func (s *InvoiceService) IssueTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	paymentID uuid.UUID,
+) error {
+
+	inv, err := s.invoiceRepo.DraftTx(ctx, tx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	inv.ID = uuid.New()
+	inv.TaxLabel = s.taxLabel
+	inv.TaxRateBps = s.taxRateBps
+	inv.TaxCents = includedTax(inv.TotalCents, s.taxRateBps)
+	inv.SubtotalCents = inv.TotalCents - inv.TaxCents
+
+	_, err = s.invoiceRepo.CreateTx(ctx, tx, inv)
+	return err
+}
+
+// GetReceipt returns the invoice of a payment to its learner or mentor.
+// Payments captured before invoicing existed get their invoice on first
+// request.
+func (s *InvoiceService) GetReceipt(
+	ctx context.Context,
+	userID uuid.UUID,
+	paymentID uuid.UUID,
+) (*models.Invoice, error) {
+
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, errors.New("payment not found")
+	}
+
+	// Checked before anything is issued, so nobody else's payment uses
+	// up an invoice number
+	if payment.UserID != userID {
+		mentor, err := s.mentorRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, errors.New("unauthorized")
+		}
+
+		mentorID, err := s.paymentRepo.GetMentorID(ctx, paymentID)
+		if err != nil || mentorID != mentor.ID {
+			return nil, errors.New("unauthorized")
+		}
+	}
+
+	inv, err := s.invoiceRepo.GetByPaymentID(ctx, paymentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.issueLate(ctx, payment)
+	}
+
+	return inv, err
+}
+
+func (s *InvoiceService) issueLate(
+	ctx context.Context,
+	payment *models.Payment,
+) (*models.Invoice, error) {
+
+	switch payment.Status {
+	case models.PaymentStatusPaid,
+		models.PaymentStatusPartiallyRefunded,
+		models.PaymentStatusRefunded:
+	default:
+		return nil, errors.New("payment has not been captured")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.IssueTx(ctx, tx, payment.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRepo.GetByPaymentID(ctx, payment.ID)
+}
+
+func (s *InvoiceService) ToResponse(inv *models.Invoice) *dtos.ReceiptResponse {
+	line := func(label string, amount int64) dtos.ReceiptLine {
+		return dtos.ReceiptLine{
+			Label:           label,
+			AmountCents:     amount,
+			FormattedAmount: utils.FormatMoney(amount, inv.Currency),
+		}
+	}
+
+	var lines []dtos.ReceiptLine
+	if inv.DiscountCents > 0 {
+		lines = append(lines, line("Discount", -inv.DiscountCents))
+	}
+	lines = append(lines,
+		line("Subtotal", inv.SubtotalCents),
+		line(taxLineLabel(inv), inv.TaxCents),
+		line("Total", inv.TotalCents),
+	)
+
+	return &dtos.ReceiptResponse{
+		InvoiceNumber:     inv.Number,
+		IssuedAt:          inv.IssuedAt,
+		PaymentID:         inv.PaymentID,
+		BookingID:         inv.BookingID,
+		PackagePurchaseID: inv.PackagePurchaseID,
+		Seller:            invoiceSeller,
+		LearnerName:       inv.LearnerName,
+		LearnerEmail:      inv.LearnerEmail,
+		MentorName:        inv.MentorName,
+		MentorUsername:    inv.MentorUsername,
+		Description:       inv.Description,
+		Currency:          inv.Currency,
+		Lines:             lines,
+		TotalCents:        inv.TotalCents,
+		FormattedTotal:    utils.FormatMoney(inv.TotalCents, inv.Currency),
+	}
+}
+
+// RenderPDF lays the invoice out on a single A4 page
+func (s *InvoiceService) RenderPDF(inv *models.Invoice) []byte {
+	const (
+		left  = 56.0
+		right = utils.PDFPageWidth - 56.0
+	)
+
+	page := utils.NewPDFPage()
+	y := utils.PDFPageHeight - 72
+
+	page.Text(left, y, 22, true, invoiceSeller)
+	page.TextRight(right, y, 16, true, "RECEIPT")
+
+	y -= 28
+	page.Text(left, y, 10, false, "Invoice number: "+inv.Number)
+	page.TextRight(right, y, 10, false, "Issued: "+inv.IssuedAt.UTC().Format("2006-01-02"))
+	y -= 14
+	page.Text(left, y, 10, false, "Payment: "+inv.PaymentID.String())
+
+	y -= 36
+	page.Text(left, y, 11, true, "Billed to")
+	page.Text(left+260, y, 11, true, "Mentor")
+	y -= 16
+	page.Text(left, y, 10, false, inv.LearnerName)
+	page.Text(left+260, y, 10, false, inv.MentorName)
+	y -= 14
+	page.Text(left, y, 10, false, inv.LearnerEmail)
+	page.Text(left+260, y, 10, false, "@"+inv.MentorUsername)
+
+	y -= 40
+	page.Text(left, y, 11, true, "Description")
+	page.TextRight(right, y, 11, true, "Amount")
+	y -= 8
+	page.Line(left, y, right, y)
+
+	y -= 18
+	page.Text(left, y, 10, false, inv.Description)
+	page.TextRight(right, y, 10, false, utils.FormatMoneyCode(inv.TotalCents+inv.DiscountCents, inv.Currency))
+
+	if inv.DiscountCents > 0 {
+		y -= 16
+		page.Text(left, y, 10, false, "Discount")
+		page.TextRight(right, y, 10, false, utils.FormatMoneyCode(-inv.DiscountCents, inv.Currency))
+	}
+
+	y -= 12
+	page.Line(left+260, y, right, y)
+
+	y -= 18
+	page.Text(left+260, y, 10, false, "Subtotal")
+	page.TextRight(right, y, 10, false, utils.FormatMoneyCode(inv.SubtotalCents, inv.Currency))
+	y -= 16
+	page.Text(left+260, y, 10, false, taxLineLabel(inv))
+	page.TextRight(right, y, 10, false, utils.FormatMoneyCode(inv.TaxCents, inv.Currency))
+	y -= 18
+	page.Text(left+260, y, 12, true, "Total paid")
+	page.TextRight(right, y, 12, true, utils.FormatMoneyCode(inv.TotalCents, inv.Currency))
+
+	page.Text(left, 56, 8, false, "All prices include "+inv.TaxLabel+". Thank you for learning with "+invoiceSeller+".")
+
+	return page.Bytes()
+}
+
+// includedTax is the tax contained in a tax-inclusive total, rounded half up
+func includedTax(total int64, rateBps int) int64 {
+	if rateBps <= 0 || total <= 0 {
+		return 0
+	}
+
+	denominator := int64(10000 + rateBps)
+	return (2*total*int64(rateBps) + denominator) / (2 * denominator)
+}
+
+func taxLineLabel(inv *models.Invoice) string {
+	return fmt.Sprintf("%s (%d%%, included)", inv.TaxLabel, inv.TaxRateBps/100)
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+)
+
+// LedgerService posts booking revenue and refunds to the double-entry
+// ledger and builds mentor earnings statements from it.
+//
+// A confirmed booking posts:
+//
+//	debit  gateway_clearing     gross
+//	credit platform_commission  commission
+//	credit mentor_payable       gross - commission
+//
+// and a refund reverses it proportionally.
+type LedgerService struct {
+	ledgerRepo *repositories.LedgerRepository
+	mentorRepo *repositories.MentorRepository
+
+	// commission in basis points (1000 = 10%)
+	commissionBps int64
+}
+
+func NewLedgerService(
+	ledgerRepo *repositories.LedgerRepository,
+	mentorRepo *repositories.MentorRepository,
+	commissionPercent int,
+) *LedgerService {
+	return &LedgerService{
+		ledgerRepo:    ledgerRepo,
+		mentorRepo:    mentorRepo,
+		commissionBps: int64(commissionPercent) * 100,
+	}
+}
+
+// RecordBookingRevenueTx posts the revenue of a confirmed booking. Posting
+// the same booking twice is a no-op. Bookings paid with package credits
+// are free at this point; their revenue was posted with the package.
+func (s *LedgerService) RecordBookingRevenueTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	booking *models.Booking,
+) error {
+
+	return s.recordRevenueTx(
+		ctx,
+		tx,
+		models.LedgerKindBookingRevenue,
+		"booking",
+		booking.ID,
+		booking.MentorID,
+		int64(booking.PriceCents),
+		booking.Currency,
+	)
+}
+
+// RecordPackageRevenueTx posts the revenue of a paid package purchase
+func (s *LedgerService) RecordPackageRevenueTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	purchase *models.PackagePurchase,
+) error {
+
+	return s.recordRevenueTx(
+		ctx,
+		tx,
+		models.LedgerKindPackageRevenue,
+		"package_purchase",
+		purchase.ID,
+		purchase.MentorID,
+		int64(purchase.PriceCents),
+		purchase.Currency,
+	)
+}
+
+func (s *LedgerService) recordRevenueTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	kind string,
+	referenceType string,
+	referenceID uuid.UUID,
+	mentorID uuid.UUID,
+	gross int64,
+	currency string,
+) error {
+
+	if gross <= 0 {
+		return nil
+	}
+
+	commission := gross * s.commissionBps / 10000
+
+	t := &models.LedgerTransaction{
+		ID:            uuid.New(),
+		Kind:          kind,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		MentorID:      mentorID,
+		Currency:      currency,
+		Entries: []models.LedgerEntry{
+			{Account: models.LedgerAccountGatewayClearing, Direction: models.LedgerDebit, Amount: gross},
+			{Account: models.LedgerAccountPlatformCommission, Direction: models.LedgerCredit, Amount: commission},
+			{Account: models.LedgerAccountMentorPayable, Direction: models.LedgerCredit, Amount: gross - commission},
+		},
+	}
+
+	_, err := s.ledgerRepo.CreateTx(ctx, tx, t)
+	return err
+}
+
+// RecordRefundTx reverses the commission and mentor share of the revenue
+// posted for revenueReferenceID (a booking or package purchase) in
+// proportion to the refunded amount. Refunds of things that never posted
+// revenue (e.g. refunded before confirmation) have nothing to reverse.
+func (s *LedgerService) RecordRefundTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	refund *models.Refund,
+	revenueReferenceID uuid.UUID,
+) error {
+
+	revenue, err := s.ledgerRepo.FindRevenueByReferenceTx(ctx, tx, revenueReferenceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var gross, commission int64
+	for _, e := range revenue.Entries {
+		switch e.Account {
+		case models.LedgerAccountGatewayClearing:
+			gross = e.Amount
+		case models.LedgerAccountPlatformCommission:
+			commission = e.Amount
+		}
+	}
+
+	if gross <= 0 || refund.Amount <= 0 {
+		return nil
+	}
+
+	amount := refund.Amount
+	if amount > gross {
+		amount = gross
+	}
+
+	commissionShare := commission * amount / gross
+
+	t := &models.LedgerTransaction{
+		ID:            uuid.New(),
+		Kind:          models.LedgerKindRefund,
+		ReferenceType: "refund",
+		ReferenceID:   refund.ID,
+		MentorID:      revenue.MentorID,
+		Currency:      revenue.Currency,
+		Entries: []models.LedgerEntry{
+			{Account: models.LedgerAccountPlatformCommission, Direction: models.LedgerDebit, Amount: commissionShare},
+			{Account: models.LedgerAccountMentorPayable, Direction: models.LedgerDebit, Amount: amount - commissionShare},
+			{Account: models.LedgerAccountGatewayClearing, Direction: models.LedgerCredit, Amount: amount},
+		},
+	}
+
+	_, err = s.ledgerRepo.CreateTx(ctx, tx, t)
+	return err
+}
+
+// GetEarnings returns the mentor's current balances and per-period
+// statements for [from, to). period is day, week or month.
+func (s *LedgerService) GetEarnings(
+	ctx context.Context,
+	userID uuid.UUID,
+	period string,
+	from time.Time,
+	to time.Time,
+) (*dtos.EarningsResponse, error) {
+
+	switch period {
+	case "day", "week", "month":
+	default:
+		return nil, errors.New("period must be day, week or month")
+	}
+
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("mentor profile not found")
+	}
+
+	balances, err := s.ledgerRepo.MentorBalances(ctx, mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range balances {
+		balances[i].FormattedBalance = utils.FormatMoney(balances[i].BalanceCents, balances[i].Currency)
+	}
+
+	statements, err := s.ledgerRepo.MentorStatements(ctx, mentor.ID, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.EarningsResponse{
+		Period:     period,
+		From:       from.Format("2006-01-02"),
+		To:         to.AddDate(0, 0, -1).Format("2006-01-02"), // to is exclusive
+		Balances:   balances,
+		Statements: statements,
+	}, nil
+}
+
+// WriteStatementsCSV writes statements as CSV, one row per period and currency
+func (s *LedgerService) WriteStatementsCSV(
+	w io.Writer,
+	statements []dtos.EarningsStatement,
+) error {
+
+	out := csv.NewWriter(w)
+
+	if err := out.Write([]string{
+		"period_start",
+		"currency",
+		"bookings",
+		"gross_cents",
+		"commission_cents",
+		"refunded_cents",
+		"net_cents",
+	}); err != nil {
+		return err
+	}
+
+	for _, st := range statements {
+		if err := out.Write([]string{
+			st.PeriodStart,
+			st.Currency,
+			strconv.Itoa(st.Bookings),
+			strconv.FormatInt(st.GrossCents, 10),
+			strconv.FormatInt(st.CommissionCents, 10),
+			strconv.FormatInt(st.RefundedCents, 10),
+			strconv.FormatInt(st.NetCents, 10),
+		}); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
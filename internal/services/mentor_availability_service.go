@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,15 +15,21 @@ import (
 type MentorAvailabilityService struct {
 	availabilityRepo *repositories.MentorAvailabilityRepository
 	mentorRepo       *repositories.MentorRepository
+	bookingRepo      *repositories.BookingRepository
+	waitlist         *WaitlistService
 }
 
 func NewMentorAvailabilityService(
 	availabilityRepo *repositories.MentorAvailabilityRepository,
 	mentorRepo *repositories.MentorRepository,
+	bookingRepo *repositories.BookingRepository,
+	waitlist *WaitlistService,
 ) *MentorAvailabilityService {
 	return &MentorAvailabilityService{
 		availabilityRepo: availabilityRepo,
 		mentorRepo:       mentorRepo,
+		bookingRepo:      bookingRepo,
+		waitlist:         waitlist,
 	}
 }
 
@@ -34,22 +43,21 @@ func (s *MentorAvailabilityService) CreateRule(
 		return nil, err
 	}
 
-	start, err := time.Parse("15:04", req.StartTime)
+	rule, err := buildRule(req)
 	if err != nil {
 		return nil, err
 	}
 
-	end, err := time.Parse("15:04", req.EndTime)
+	rule.ID = uuid.New()
+	rule.MentorID = mentor.ID
+
+	existing, err := s.availabilityRepo.FindByMentor(mentor.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	rule := &models.MentorAvailabilityRule{
-		ID:        uuid.New(),
-		MentorID:  mentor.ID,
-		DayOfWeek: req.DayOfWeek,
-		StartTime: start,
-		EndTime:   end,
+	if err := validateRules(append(existing, rule)); err != nil {
+		return nil, err
 	}
 
 	createdRule, err := s.availabilityRepo.Create(rule)
@@ -57,12 +65,118 @@ func (s *MentorAvailabilityService) CreateRule(
 		return nil, err
 	}
 
-	return &dtos.MentorAvailabilityResponse{
-		ID:        createdRule.ID,
-		DayOfWeek: createdRule.DayOfWeek,
-		StartTime: createdRule.StartTime.Format("15:04"),
-		EndTime:   createdRule.EndTime.Format("15:04"),
-	}, nil
+	s.waitlist.SlotOpened(mentor.ID)
+
+	return toRuleResponse(createdRule), nil
+}
+
+// UpdateRule changes one of the mentor's weekly rules and reports the
+// upcoming bookings that no longer fit the mentor's availability
+func (s *MentorAvailabilityService) UpdateRule(
+	userID uuid.UUID,
+	ruleID uuid.UUID,
+	req *dtos.CreateMentorAvailabilityRequest,
+) (*dtos.MentorAvailabilityChangeResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := buildRule(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ID = ruleID
+	rule.MentorID = mentor.ID
+
+	existing, err := s.availabilityRepo.FindByMentor(mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []*models.MentorAvailabilityRule{rule}
+	found := false
+	for _, r := range existing {
+		if r.ID == ruleID {
+			found = true
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	if !found {
+		return nil, errors.New("availability rule not found")
+	}
+
+	if err := validateRules(rules); err != nil {
+		return nil, err
+	}
+
+	if err := s.availabilityRepo.Update(context.Background(), rule); err != nil {
+		return nil, err
+	}
+
+	s.waitlist.SlotOpened(mentor.ID)
+
+	return s.changeResponse(mentor)
+}
+
+// DeleteRule removes one of the mentor's weekly rules and reports the
+// upcoming bookings that no longer fit the mentor's availability
+func (s *MentorAvailabilityService) DeleteRule(
+	userID uuid.UUID,
+	ruleID uuid.UUID,
+) (*dtos.MentorAvailabilityChangeResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.availabilityRepo.Delete(context.Background(), mentor.ID, ruleID); err != nil {
+		return nil, err
+	}
+
+	return s.changeResponse(mentor)
+}
+
+// ReplaceWeek swaps all of the mentor's weekly rules for `req.Rules` at
+// once and reports the upcoming bookings that no longer fit
+func (s *MentorAvailabilityService) ReplaceWeek(
+	userID uuid.UUID,
+	req *dtos.ReplaceMentorAvailabilityRequest,
+) (*dtos.MentorAvailabilityChangeResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*models.MentorAvailabilityRule, 0, len(req.Rules))
+	for i := range req.Rules {
+		rule, err := buildRule(&req.Rules[i])
+		if err != nil {
+			return nil, err
+		}
+
+		rule.ID = uuid.New()
+		rule.MentorID = mentor.ID
+		rules = append(rules, rule)
+	}
+
+	if err := validateRules(rules); err != nil {
+		return nil, err
+	}
+
+	if err := s.availabilityRepo.ReplaceAll(context.Background(), mentor.ID, rules); err != nil {
+		return nil, err
+	}
+
+	s.waitlist.SlotOpened(mentor.ID)
+
+	return s.changeResponse(mentor)
 }
 
 func (s *MentorAvailabilityService) GetByUsername(
@@ -77,13 +191,339 @@ func (s *MentorAvailabilityService) GetByUsername(
 	resp := make([]dtos.MentorAvailabilityResponse, 0, len(rules))
 
 	for _, r := range rules {
-		resp = append(resp, dtos.MentorAvailabilityResponse{
-			ID:        r.ID,
-			DayOfWeek: r.DayOfWeek,
-			StartTime: r.StartTime.Format("15:04"),
-			EndTime:   r.EndTime.Format("15:04"),
+		resp = append(resp, *toRuleResponse(r))
+	}
+
+	return resp, nil
+}
+
+// changeResponse lists the mentor's rules after a change together with
+// the pending and confirmed upcoming bookings left outside availability.
+// Those bookings are not touched; the mentor decides whether to keep,
+// move or cancel them.
+func (s *MentorAvailabilityService) changeResponse(
+	mentor *models.MentorProfile,
+) (*dtos.MentorAvailabilityChangeResponse, error) {
+
+	ctx := context.Background()
+
+	rules, err := s.availabilityRepo.FindByMentor(mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dtos.MentorAvailabilityChangeResponse{
+		Rules:            make([]dtos.MentorAvailabilityResponse, 0, len(rules)),
+		AffectedBookings: []dtos.AffectedBooking{},
+	}
+
+	for _, r := range rules {
+		resp.Rules = append(resp.Rules, *toRuleResponse(r))
+	}
+
+	now := time.Now()
+
+	bookings, err := s.bookingRepo.FindForMentorBetween(mentor.ID, now, now.AddDate(2, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bookings) == 0 {
+		return resp, nil
+	}
+
+	windows, err := loadWindows(ctx, s.availabilityRepo, mentor, bookings[0].StartsAt, bookings[len(bookings)-1].EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range bookings {
+		if !b.StartsAt.After(now) || fitsWindows(windows, b.StartsAt, b.EndsAt) {
+			continue
+		}
+
+		resp.AffectedBookings = append(resp.AffectedBookings, dtos.AffectedBooking{
+			ID:       b.ID,
+			Status:   string(b.Status),
+			StartsAt: b.StartsAt,
+			EndsAt:   b.EndsAt,
 		})
 	}
 
 	return resp, nil
 }
+
+// buildRule parses a weekly rule. The start must be before the end; an
+// end of 00:00 means midnight at the end of the day, so evening hours can
+// run right up to the next day's rules.
+func buildRule(
+	req *dtos.CreateMentorAvailabilityRequest,
+) (*models.MentorAvailabilityRule, error) {
+
+	start, err := time.Parse("15:04", req.StartTime)
+	if err != nil {
+		return nil, errors.New("invalid start time")
+	}
+
+	end, err := time.Parse("15:04", req.EndTime)
+	if err != nil {
+		return nil, errors.New("invalid end time")
+	}
+
+	if ruleMinutes(end, true) <= ruleMinutes(start, false) {
+		return nil, errors.New("start time must be before end time")
+	}
+
+	return &models.MentorAvailabilityRule{
+		DayOfWeek: req.DayOfWeek,
+		StartTime: start,
+		EndTime:   end,
+	}, nil
+}
+
+// validateRules rejects rules that overlap another rule on the same day.
+// Touching rules (10:00-12:00 and 12:00-14:00) are fine.
+func validateRules(rules []*models.MentorAvailabilityRule) error {
+	for i, a := range rules {
+		for _, b := range rules[i+1:] {
+			if a.DayOfWeek != b.DayOfWeek {
+				continue
+			}
+
+			if ruleMinutes(a.StartTime, false) < ruleMinutes(b.EndTime, true) &&
+				ruleMinutes(b.StartTime, false) < ruleMinutes(a.EndTime, true) {
+				return fmt.Errorf(
+					"rules %s-%s and %s-%s overlap on %s",
+					a.StartTime.Format("15:04"), a.EndTime.Format("15:04"),
+					b.StartTime.Format("15:04"), b.EndTime.Format("15:04"),
+					time.Weekday(a.DayOfWeek),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ruleMinutes is a rule time in minutes since midnight, with an end of
+// 00:00 counted as 24:00
+func ruleMinutes(t time.Time, isEnd bool) int {
+	m := t.Hour()*60 + t.Minute()
+	if isEnd && m == 0 {
+		return 24 * 60
+	}
+
+	return m
+}
+
+func toRuleResponse(r *models.MentorAvailabilityRule) *dtos.MentorAvailabilityResponse {
+	return &dtos.MentorAvailabilityResponse{
+		ID:        r.ID,
+		DayOfWeek: r.DayOfWeek,
+		StartTime: r.StartTime.Format("15:04"),
+		EndTime:   r.EndTime.Format("15:04"),
+	}
+}
+
+// maxOverrideDays caps a single override, e.g. a long sabbatical
+const maxOverrideDays = 366
+
+func (s *MentorAvailabilityService) CreateOverride(
+	userID uuid.UUID,
+	req *dtos.MentorAvailabilityOverrideRequest,
+) (*dtos.MentorAvailabilityOverrideResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := buildOverride(req)
+	if err != nil {
+		return nil, err
+	}
+
+	override.ID = uuid.New()
+	override.MentorID = mentor.ID
+
+	if err := s.availabilityRepo.CreateOverride(context.Background(), override); err != nil {
+		return nil, err
+	}
+
+	s.waitlist.SlotOpened(mentor.ID)
+
+	return toOverrideResponse(override), nil
+}
+
+func (s *MentorAvailabilityService) UpdateOverride(
+	userID uuid.UUID,
+	id uuid.UUID,
+	req *dtos.MentorAvailabilityOverrideRequest,
+) (*dtos.MentorAvailabilityOverrideResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := buildOverride(req)
+	if err != nil {
+		return nil, err
+	}
+
+	override.ID = id
+	override.MentorID = mentor.ID
+
+	if err := s.availabilityRepo.UpdateOverride(context.Background(), override); err != nil {
+		return nil, err
+	}
+
+	s.waitlist.SlotOpened(mentor.ID)
+
+	return toOverrideResponse(override), nil
+}
+
+func (s *MentorAvailabilityService) DeleteOverride(
+	userID uuid.UUID,
+	id uuid.UUID,
+) error {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.availabilityRepo.DeleteOverride(context.Background(), mentor.ID, id); err != nil {
+		return err
+	}
+
+	// Removing a blocked-out day frees its slots
+	s.waitlist.SlotOpened(mentor.ID)
+	return nil
+}
+
+// ListOverrides returns the calling mentor's overrides touching the dates
+// from..to. Both default to a window from today to 90 days ahead in the
+// mentor's timezone.
+func (s *MentorAvailabilityService) ListOverrides(
+	userID uuid.UUID,
+	fromStr string,
+	toStr string,
+) ([]dtos.MentorAvailabilityOverrideResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := dateOf(time.Now().In(mentorLocation(mentor)))
+	to := from.AddDate(0, 0, 90)
+
+	if fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			return nil, errors.New("invalid from date")
+		}
+	}
+
+	if toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			return nil, errors.New("invalid to date")
+		}
+	}
+
+	overrides, err := s.availabilityRepo.FindOverridesBetween(context.Background(), mentor.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dtos.MentorAvailabilityOverrideResponse, 0, len(overrides))
+	for _, o := range overrides {
+		resp = append(resp, *toOverrideResponse(o))
+	}
+
+	return resp, nil
+}
+
+func buildOverride(
+	req *dtos.MentorAvailabilityOverrideRequest,
+) (*models.MentorAvailabilityOverride, error) {
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, errors.New("invalid start date")
+	}
+
+	endDate := startDate
+	if req.EndDate != "" {
+		if endDate, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+			return nil, errors.New("invalid end date")
+		}
+	}
+
+	if endDate.Before(startDate) {
+		return nil, errors.New("end date is before start date")
+	}
+
+	if endDate.Sub(startDate) >= maxOverrideDays*24*time.Hour {
+		return nil, errors.New("an override can span at most 366 days")
+	}
+
+	o := &models.MentorAvailabilityOverride{
+		Kind:      models.AvailabilityOverrideKind(req.Kind),
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	if req.Reason != "" {
+		o.Reason = &req.Reason
+	}
+
+	switch {
+	case req.StartTime == "" && req.EndTime == "":
+		if o.Kind == models.AvailabilityOverrideExtra {
+			return nil, errors.New("extra hours need a start and end time")
+		}
+		return o, nil
+	case req.StartTime == "" || req.EndTime == "":
+		return nil, errors.New("give both start and end time, or neither")
+	}
+
+	start, err := time.Parse("15:04", req.StartTime)
+	if err != nil {
+		return nil, errors.New("invalid start time")
+	}
+
+	end, err := time.Parse("15:04", req.EndTime)
+	if err != nil {
+		return nil, errors.New("invalid end time")
+	}
+
+	if start.Equal(end) {
+		return nil, errors.New("availability window cannot be empty")
+	}
+
+	o.StartTime = &start
+	o.EndTime = &end
+
+	return o, nil
+}
+
+func toOverrideResponse(o *models.MentorAvailabilityOverride) *dtos.MentorAvailabilityOverrideResponse {
+	resp := &dtos.MentorAvailabilityOverrideResponse{
+		ID:        o.ID,
+		Kind:      string(o.Kind),
+		StartDate: o.StartDate.Format("2006-01-02"),
+		EndDate:   o.EndDate.Format("2006-01-02"),
+	}
+
+	if o.StartTime != nil && o.EndTime != nil {
+		resp.StartTime = o.StartTime.Format("15:04")
+		resp.EndTime = o.EndTime.Format("15:04")
+	}
+
+	if o.Reason != nil {
+		resp.Reason = *o.Reason
+	}
+
+	return resp
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
@@ -9,6 +11,13 @@ import (
 	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
 )
 
+// Booking settings of new profiles: learners can't grab a slot starting
+// within the hour or book more than three months out
+const (
+	defaultMinNoticeMinutes = 60
+	defaultMaxAdvanceDays   = 90
+)
+
 type MentorProfileService struct {
 	mentorRepo *repositories.MentorRepository
 }
@@ -26,6 +35,11 @@ func (s *MentorProfileService) CreateProfile(
 	req *dtos.CreateMentorProfileRequest,
 ) (*models.MentorProfile, error) {
 
+	// Availability rules are read in this zone, so it must be a real one
+	if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "" {
+		return nil, ErrInvalidTimezone
+	}
+
 	profile := &models.MentorProfile{
 		ID:       uuid.New(),
 		UserID:   userID,
@@ -33,6 +47,9 @@ func (s *MentorProfileService) CreateProfile(
 		Bio:      strings.TrimSpace(req.Bio),
 		Timezone: req.Timezone,
 		IsActive: true,
+
+		MinNoticeMinutes: defaultMinNoticeMinutes,
+		MaxAdvanceDays:   defaultMaxAdvanceDays,
 	}
 
 	if err := s.mentorRepo.CreateProfile(profile); err != nil {
@@ -47,3 +64,48 @@ func (s *MentorProfileService) GetMentorProfile(
 ) (*dtos.MentorProfileResponse, error) {
 	return s.mentorRepo.FindByUsername(username)
 }
+
+func (s *MentorProfileService) GetBookingSettings(
+	userID uuid.UUID,
+) (*dtos.MentorBookingSettings, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBookingSettings(mentor), nil
+}
+
+// UpdateBookingSettings replaces the mentor's booking settings. They apply
+// to new bookings only; existing ones are left as they are.
+func (s *MentorProfileService) UpdateBookingSettings(
+	userID uuid.UUID,
+	req *dtos.MentorBookingSettings,
+) (*dtos.MentorBookingSettings, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentor.BufferMinutes = req.BufferMinutes
+	mentor.MinNoticeMinutes = req.MinNoticeMinutes
+	mentor.MaxAdvanceDays = req.MaxAdvanceDays
+	mentor.DailySessionCap = req.DailySessionCap
+
+	if err := s.mentorRepo.UpdateBookingSettings(context.Background(), mentor); err != nil {
+		return nil, err
+	}
+
+	return toBookingSettings(mentor), nil
+}
+
+func toBookingSettings(mentor *models.MentorProfile) *dtos.MentorBookingSettings {
+	return &dtos.MentorBookingSettings{
+		BufferMinutes:    mentor.BufferMinutes,
+		MinNoticeMinutes: mentor.MinNoticeMinutes,
+		MaxAdvanceDays:   mentor.MaxAdvanceDays,
+		DailySessionCap:  mentor.DailySessionCap,
+	}
+}
@@ -1,26 +1,31 @@
 package services
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
 	"github.com/preetsinghmakkar/OpenCall/internal/models"
 	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
 )
 
 type MentorOfferingService struct {
 	serviceRepo *repositories.MentorServiceRepository
 	mentorRepo  *repositories.MentorRepository
+	gateway     PaymentGateway
 }
 
 func NewMentorOfferingService(
 	serviceRepo *repositories.MentorServiceRepository,
 	mentorRepo *repositories.MentorRepository,
+	gateway PaymentGateway,
 ) *MentorOfferingService {
 	return &MentorOfferingService{
 		serviceRepo: serviceRepo,
 		mentorRepo:  mentorRepo,
+		gateway:     gateway,
 	}
 }
 
@@ -31,18 +36,50 @@ func (s *MentorOfferingService) CreateService(
 
 	mentor, err := s.mentorRepo.FindByUserID(userID)
 	if err != nil {
-		return nil, err // mentor profile not found
+		return nil, errors.New("mentor profile not found")
+	}
+
+	// Prices are in the currency's minor units and must be chargeable
+	// through the configured gateway, unless the service is free
+	currency := strings.ToUpper(req.Currency)
+	if err := utils.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	if req.PriceCents > 0 {
+		if err := s.gateway.ValidateAmount(int64(req.PriceCents), currency); err != nil {
+			return nil, err
+		}
+	}
+
+	// One-on-one sessions have a single seat; group sessions say how
+	// many learners they take
+	serviceType := req.Type
+	if serviceType == "" {
+		serviceType = models.ServiceTypeOneOnOne
+	}
+
+	capacity := 1
+	if serviceType == models.ServiceTypeGroup {
+		if req.Capacity == 0 {
+			return nil, errors.New("group services need a capacity")
+		}
+		capacity = req.Capacity
+	} else if req.Capacity > 1 {
+		return nil, errors.New("only group services can seat more than one learner")
 	}
 
 	service := &models.MentorService{
-		ID:              uuid.New(),
-		MentorID:        mentor.ID,
-		Title:           strings.TrimSpace(req.Title),
-		Description:     strings.TrimSpace(req.Description),
-		DurationMinutes: req.DurationMinutes,
-		PriceCents:      req.PriceCents,
-		Currency:        strings.ToUpper(req.Currency),
-		IsActive:        true,
+		ID:               uuid.New(),
+		MentorID:         mentor.ID,
+		Title:            strings.TrimSpace(req.Title),
+		Description:      strings.TrimSpace(req.Description),
+		DurationMinutes:  req.DurationMinutes,
+		PriceCents:       req.PriceCents,
+		Currency:         currency,
+		Type:             serviceType,
+		Capacity:         capacity,
+		RequiresApproval: req.RequiresApproval,
+		IsActive:         true,
 	}
 	if err := s.serviceRepo.Create(service); err != nil {
 		return nil, err
@@ -64,13 +101,17 @@ func (s *MentorOfferingService) GetServicesByUsername(
 
 	for _, svc := range services {
 		resp = append(resp, dtos.MentorServiceResponse{
-			ID:              svc.ID,
-			Title:           svc.Title,
-			Description:     svc.Description,
-			DurationMinutes: svc.DurationMinutes,
-			PriceCents:      svc.PriceCents,
-			Currency:        svc.Currency,
-			IsActive:        svc.IsActive,
+			ID:               svc.ID,
+			Title:            svc.Title,
+			Description:      svc.Description,
+			DurationMinutes:  svc.DurationMinutes,
+			PriceCents:       svc.PriceCents,
+			Currency:         svc.Currency,
+			FormattedPrice:   utils.FormatMoney(int64(svc.PriceCents), svc.Currency),
+			Type:             svc.Type,
+			Capacity:         svc.Capacity,
+			RequiresApproval: svc.RequiresApproval,
+			IsActive:         svc.IsActive,
 		})
 	}
 
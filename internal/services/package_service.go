@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+)
+
+// PackageService sells prepaid session packages. A purchase is paid
+// through the normal payment flow (POST /api/payments with
+// package_purchase_id); PaymentService activates it once paid, and
+// BookingService spends its credits.
+type PackageService struct {
+	packageRepo    *repositories.PackageRepository
+	serviceRepo    *repositories.MentorServiceRepository
+	mentorRepo     *repositories.MentorRepository
+	paymentService *PaymentService
+}
+
+func NewPackageService(
+	packageRepo *repositories.PackageRepository,
+	serviceRepo *repositories.MentorServiceRepository,
+	mentorRepo *repositories.MentorRepository,
+	paymentService *PaymentService,
+) *PackageService {
+	return &PackageService{
+		packageRepo:    packageRepo,
+		serviceRepo:    serviceRepo,
+		mentorRepo:     mentorRepo,
+		paymentService: paymentService,
+	}
+}
+
+func (s *PackageService) CreatePackage(
+	userID uuid.UUID,
+	req *dtos.CreateServicePackageRequest,
+) (*dtos.ServicePackageResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("mentor profile not found")
+	}
+
+	service, err := s.serviceRepo.FindByID(req.ServiceID)
+	if err != nil || service.MentorID != mentor.ID {
+		return nil, errors.New("invalid service")
+	}
+
+	// Packages are sold in the service's currency
+	if err := s.paymentService.ValidateAmount(int64(req.PriceCents), service.Currency); err != nil {
+		return nil, err
+	}
+
+	pkg := &models.ServicePackage{
+		ID:           uuid.New(),
+		MentorID:     mentor.ID,
+		ServiceID:    service.ID,
+		Title:        strings.TrimSpace(req.Title),
+		SessionCount: req.SessionCount,
+		PriceCents:   req.PriceCents,
+		Currency:     service.Currency,
+		ValidityDays: req.ValidityDays,
+		IsActive:     true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.packageRepo.Create(ctx, pkg); err != nil {
+		return nil, err
+	}
+
+	return toServicePackageResponse(pkg), nil
+}
+
+func (s *PackageService) GetPackagesByUsername(
+	username string,
+) ([]*dtos.ServicePackageResponse, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	packages, err := s.packageRepo.FindActiveByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dtos.ServicePackageResponse, 0, len(packages))
+	for _, p := range packages {
+		resp = append(resp, toServicePackageResponse(p))
+	}
+
+	return resp, nil
+}
+
+// Purchase opens a pending purchase of a package. Credits are granted when
+// its payment is captured.
+func (s *PackageService) Purchase(
+	userID uuid.UUID,
+	packageID uuid.UUID,
+) (*dtos.PackagePurchaseResponse, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pkg, err := s.packageRepo.FindByID(ctx, packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pkg.IsActive {
+		return nil, errors.New("package not available")
+	}
+
+	if _, err := s.serviceRepo.FindByID(pkg.ServiceID); err != nil {
+		return nil, errors.New("package not available")
+	}
+
+	purchase := &models.PackagePurchase{
+		ID:           uuid.New(),
+		PackageID:    pkg.ID,
+		UserID:       userID,
+		MentorID:     pkg.MentorID,
+		ServiceID:    pkg.ServiceID,
+		CreditsTotal: pkg.SessionCount,
+		PriceCents:   pkg.PriceCents,
+		Currency:     pkg.Currency,
+		Status:       models.PackagePurchasePending,
+	}
+
+	if err := s.packageRepo.CreatePurchase(ctx, purchase); err != nil {
+		return nil, err
+	}
+
+	return toPackagePurchaseResponse(purchase), nil
+}
+
+func (s *PackageService) GetMyPurchases(
+	userID uuid.UUID,
+) ([]*dtos.PackagePurchaseResponse, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	purchases, err := s.packageRepo.ListPurchasesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dtos.PackagePurchaseResponse, 0, len(purchases))
+	for _, p := range purchases {
+		resp = append(resp, toPackagePurchaseResponse(p))
+	}
+
+	return resp, nil
+}
+
+func toServicePackageResponse(p *models.ServicePackage) *dtos.ServicePackageResponse {
+	return &dtos.ServicePackageResponse{
+		ID:             p.ID,
+		ServiceID:      p.ServiceID,
+		Title:          p.Title,
+		SessionCount:   p.SessionCount,
+		PriceCents:     p.PriceCents,
+		Currency:       p.Currency,
+		FormattedPrice: utils.FormatMoney(int64(p.PriceCents), p.Currency),
+		ValidityDays:   p.ValidityDays,
+	}
+}
+
+func toPackagePurchaseResponse(p *models.PackagePurchase) *dtos.PackagePurchaseResponse {
+	return &dtos.PackagePurchaseResponse{
+		ID:               p.ID,
+		PackageID:        p.PackageID,
+		ServiceID:        p.ServiceID,
+		Status:           string(p.Status),
+		CreditsTotal:     p.CreditsTotal,
+		CreditsRemaining: p.CreditsRemaining,
+		PriceCents:       p.PriceCents,
+		Currency:         p.Currency,
+		FormattedPrice:   utils.FormatMoney(int64(p.PriceCents), p.Currency),
+		ExpiresAt:        p.ExpiresAt,
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import "net/http"
+
+// PaymentGateway is everything PaymentService needs from a payment provider.
+// Razorpay is the production implementation; FakeGateway runs in-process
+// for offline end-to-end runs.
+type PaymentGateway interface {
+	// Name is stored on payments.gateway and used in the webhook route
+	Name() string
+
+	// ValidateAmount rejects currencies the gateway can't charge in and
+	// amounts it can't represent in that currency's minor units
+	ValidateAmount(amount int64, currency string) error
+
+	// CreateOrder opens an order for `amount` minor units of `currency`
+	// and returns its ID
+	CreateOrder(amount int64, currency string, receipt string) (string, error)
+
+	// VerifyPaymentSignature checks the signature the checkout returned to the client
+	VerifyPaymentSignature(orderID, paymentID, signature string) error
+
+	// VerifyWebhook authenticates a raw webhook body using its headers
+	VerifyWebhook(payload []byte, header http.Header) error
+
+	// ParseWebhook turns a verified webhook body into a gateway-neutral event
+	ParseWebhook(payload []byte, header http.Header) (*GatewayEvent, error)
+
+	// CreateRefund refunds `amount` minor units of a captured payment
+	CreateRefund(paymentID string, amount int64, receipt string) (*GatewayRefund, error)
+
+	// FetchOrder reports the gateway's view of an order, for reconciling
+	// payments whose webhook never arrived
+	FetchOrder(orderID string) (*GatewayOrder, error)
+}
+
+const (
+	GatewayEventPaymentCaptured = "payment.captured"
+	GatewayEventPaymentFailed   = "payment.failed"
+	GatewayEventRefundProcessed = "refund.processed"
+	GatewayEventRefundFailed    = "refund.failed"
+)
+
+// GatewayEvent is a webhook event normalised across gateways
+type GatewayEvent struct {
+	ID   string `json:"id"`   // gateway's event ID, empty if the gateway doesn't send one
+	Type string `json:"type"` // one of the GatewayEvent* constants, or the raw gateway type
+
+	OrderID   string `json:"order_id,omitempty"`
+	PaymentID string `json:"payment_id,omitempty"`
+	RefundID  string `json:"refund_id,omitempty"`
+
+	Amount   int64  `json:"amount,omitempty"`
+	Currency string `json:"currency,omitempty"`
+
+	// Refund events echo the receipt CreateRefund was given: our refund ID
+	Receipt string `json:"receipt,omitempty"`
+}
+
+const (
+	GatewayOrderOpen   = "open"   // nothing captured and not given up on yet
+	GatewayOrderPaid   = "paid"   // a payment was captured
+	GatewayOrderFailed = "failed" // every attempt failed
+)
+
+// GatewayOrder is an order's state as reported by the gateway
+type GatewayOrder struct {
+	ID     string
+	Status string // one of the GatewayOrder* constants
+
+	// The captured payment when paid, otherwise the last failed attempt
+	PaymentID string
+
+	Amount   int64
+	Currency string
+}
+
+type GatewayRefund struct {
+	ID     string
+	Status string // pending | processed | failed
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// reconcilerBatchSize caps how many payments one claim hands out
+	reconcilerBatchSize = 50
+
+	// How long to wait before asking the gateway about the same order again
+	reconcilerRecheckAfter = time.Hour
+)
+
+const (
+	ResolutionMarkedPaid   = "marked_paid"
+	ResolutionRefunded     = "refunded"
+	ResolutionMarkedFailed = "marked_failed"
+	ResolutionNeedsReview  = "needs_review"
+	ResolutionError        = "error"
+)
+
+// PaymentReconciler settles payments whose webhook never arrived. It asks
+// the gateway what happened to every payment still created after
+// staleAfter and applies the outcome through the same transitions the
+// webhook uses. Payments that can't be settled automatically are
+// reported for review.
+type PaymentReconciler struct {
+	paymentRepo    *repositories.PaymentRepository
+	paymentService *PaymentService
+	gateway        PaymentGateway
+
+	staleAfter time.Duration
+	interval   time.Duration
+}
+
+func NewPaymentReconciler(
+	paymentRepo *repositories.PaymentRepository,
+	paymentService *PaymentService,
+	gateway PaymentGateway,
+	staleAfter time.Duration,
+	interval time.Duration,
+) *PaymentReconciler {
+	return &PaymentReconciler{
+		paymentRepo:    paymentRepo,
+		paymentService: paymentService,
+		gateway:        gateway,
+		staleAfter:     staleAfter,
+		interval:       interval,
+	}
+}
+
+// Run reconciles on every tick until ctx is cancelled
+func (r *PaymentReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.ReconcileOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("payment reconciler failed")
+			}
+		}
+	}
+}
+
+// ReconcileOnce checks every due payment with the gateway and returns
+// the mismatches it found
+func (r *PaymentReconciler) ReconcileOnce(ctx context.Context) (*dtos.ReconciliationReport, error) {
+	report := &dtos.ReconciliationReport{
+		StartedAt:  time.Now().UTC(),
+		Mismatches: []dtos.ReconciliationMismatch{},
+	}
+
+	for {
+		payments, err := r.paymentRepo.ClaimForReconciliation(
+			ctx,
+			r.gateway.Name(),
+			r.staleAfter,
+			reconcilerRecheckAfter,
+			reconcilerBatchSize,
+		)
+		if err != nil {
+			return report, err
+		}
+
+		for _, p := range payments {
+			if m := r.reconcile(p); m != nil {
+				report.Mismatches = append(report.Mismatches, *m)
+			}
+		}
+
+		report.Checked += len(payments)
+
+		if len(payments) < reconcilerBatchSize {
+			break
+		}
+	}
+
+	for _, m := range report.Mismatches {
+		log.Warn().
+			Str("payment_id", m.PaymentID.String()).
+			Str("gateway_order_id", m.GatewayOrderID).
+			Str("local_status", m.LocalStatus).
+			Str("gateway_status", m.GatewayStatus).
+			Str("resolution", m.Resolution).
+			Str("detail", m.Detail).
+			Msg("payment reconciliation mismatch")
+	}
+
+	return report, nil
+}
+
+// reconcile settles one payment and describes the mismatch, or returns
+// nil when the gateway agrees with us
+func (r *PaymentReconciler) reconcile(p *models.Payment) *dtos.ReconciliationMismatch {
+	mismatch := &dtos.ReconciliationMismatch{
+		PaymentID:      p.ID,
+		GatewayOrderID: p.GatewayOrderID,
+		LocalStatus:    p.Status,
+	}
+
+	order, err := r.gateway.FetchOrder(p.GatewayOrderID)
+	if err != nil {
+		mismatch.Resolution = ResolutionError
+		mismatch.Detail = "fetching order: " + err.Error()
+		return mismatch
+	}
+
+	mismatch.GatewayStatus = order.Status
+
+	switch order.Status {
+	case GatewayOrderPaid:
+		if order.Amount != p.Amount || !strings.EqualFold(order.Currency, p.Currency) {
+			mismatch.Resolution = ResolutionNeedsReview
+			mismatch.Detail = fmt.Sprintf(
+				"captured %d %s, expected %d %s",
+				order.Amount, order.Currency, p.Amount, p.Currency,
+			)
+			return mismatch
+		}
+
+		// A capture after the payment failed or expired goes through the
+		// same path as a late webhook: the booking is confirmed if its
+		// slot is still free, otherwise the payment is refunded
+		var delivered bool
+		delivered, err = r.paymentService.applyCapture(&GatewayEvent{
+			Type:      GatewayEventPaymentCaptured,
+			OrderID:   order.ID,
+			PaymentID: order.PaymentID,
+			Amount:    order.Amount,
+			Currency:  order.Currency,
+		})
+
+		mismatch.Resolution = ResolutionMarkedPaid
+		if !delivered {
+			mismatch.Resolution = ResolutionRefunded
+			mismatch.Detail = "captured after what it paid for was gone"
+		}
+
+	case GatewayOrderFailed:
+		if p.Status != models.PaymentStatusCreated {
+			return nil
+		}
+
+		err = r.paymentService.HandlePaymentFailed(&GatewayEvent{
+			Type:      GatewayEventPaymentFailed,
+			OrderID:   order.ID,
+			PaymentID: order.PaymentID,
+		})
+		mismatch.Resolution = ResolutionMarkedFailed
+
+	default:
+		// Still open at the gateway: nothing to settle yet
+		return nil
+	}
+
+	if err != nil {
+		mismatch.Resolution = ResolutionError
+		mismatch.Detail = err.Error()
+	}
+
+	return mismatch
+}
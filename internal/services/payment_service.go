@@ -2,42 +2,74 @@ package services
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
 	"database/sql"
-	"encoding/hex"
 	"errors"
+	"net/http"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
 	"github.com/preetsinghmakkar/OpenCall/internal/models"
 	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/rs/zerolog/log"
 )
 
+// errUndeliverable means a captured payment paid for something that is
+// gone - a lapsed booking whose slot was taken since, or one the learner
+// cancelled - so the payment has to be refunded instead
+var errUndeliverable = errors.New("nothing left to deliver for the payment")
+
 type PaymentService struct {
-	db             *sql.DB
-	paymentRepo    *repositories.PaymentRepository
-	bookingRepo    *repositories.BookingRepository
-	razorpay       *RazorpayClient
-	razorpaySecret string
+	db          *sql.DB
+	paymentRepo *repositories.PaymentRepository
+	bookingRepo *repositories.BookingRepository
+	refundRepo  *repositories.RefundRepository
+	packageRepo *repositories.PackageRepository
+	seriesRepo  *repositories.BookingSeriesRepository
+	mentorRepo  *repositories.MentorRepository
+	serviceRepo *repositories.MentorServiceRepository
+	ledger      *LedgerService
+	invoices    *InvoiceService
+	gateway     PaymentGateway
 }
 
 func NewPaymentService(
 	db *sql.DB,
 	paymentRepo *repositories.PaymentRepository,
 	bookingRepo *repositories.BookingRepository,
-	razorpay *RazorpayClient,
-	secret string,
+	refundRepo *repositories.RefundRepository,
+	packageRepo *repositories.PackageRepository,
+	seriesRepo *repositories.BookingSeriesRepository,
+	mentorRepo *repositories.MentorRepository,
+	serviceRepo *repositories.MentorServiceRepository,
+	ledger *LedgerService,
+	invoices *InvoiceService,
+	gateway PaymentGateway,
 ) *PaymentService {
 	return &PaymentService{
-		db:             db,
-		paymentRepo:    paymentRepo,
-		bookingRepo:    bookingRepo,
-		razorpay:       razorpay,
-		razorpaySecret: secret,
+		db:          db,
+		paymentRepo: paymentRepo,
+		bookingRepo: bookingRepo,
+		refundRepo:  refundRepo,
+		packageRepo: packageRepo,
+		seriesRepo:  seriesRepo,
+		mentorRepo:  mentorRepo,
+		serviceRepo: serviceRepo,
+		ledger:      ledger,
+		invoices:    invoices,
+		gateway:     gateway,
 	}
 }
 
+// ValidateAmount checks the configured gateway can charge `amount` minor units of `currency`
+func (s *PaymentService) ValidateAmount(amount int64, currency string) error {
+	return s.gateway.ValidateAmount(amount, currency)
+}
+
+// GatewayName is the name of the configured payment gateway
+func (s *PaymentService) GatewayName() string {
+	return s.gateway.Name()
+}
+
 func (s *PaymentService) CreatePayment(
 	ctx context.Context,
 	bookingID uuid.UUID,
@@ -53,31 +85,134 @@ func (s *PaymentService) CreatePayment(
 		return nil, errors.New("unauthorized")
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	// Requests are only paid for once the mentor approves them
+	switch booking.Status {
+	case models.BookingStatusRequested:
+		return nil, errors.New("booking is awaiting the mentor's approval")
+	case models.BookingStatusDeclined:
+		return nil, errors.New("booking request was declined")
+	}
+
+	// Free and package sessions are confirmed without a gateway order
+	if booking.PriceCents == 0 {
+		return nil, errors.New("booking has nothing to pay")
+	}
+
+	// Orders are always created in the booking's own currency, for the
+	// price after any promo discount
+	return s.createOrder(ctx, &models.Payment{
+		ID:            uuid.New(),
+		BookingID:     &booking.ID,
+		UserID:        userID,
+		Amount:        int64(booking.PriceCents),
+		Currency:      booking.Currency,
+		DiscountCents: int64(booking.DiscountCents),
+	}, booking.ID.String())
+}
+
+// CreatePackagePayment opens a gateway order for a pending package purchase.
+// Once paid, the purchase is activated and its credits can be booked.
+func (s *PaymentService) CreatePackagePayment(
+	ctx context.Context,
+	purchaseID uuid.UUID,
+	userID uuid.UUID,
+) (*models.Payment, error) {
+
+	purchase, err := s.packageRepo.GetPurchaseByID(ctx, purchaseID)
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback()
 
-	orderID, err := s.razorpay.CreateOrder(
-		int64(booking.PriceCents),
-		booking.ID.String(),
-	)
+	if purchase.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	if purchase.Status != models.PackagePurchasePending {
+		return nil, errors.New("package purchase is not awaiting payment")
+	}
+
+	return s.createOrder(ctx, &models.Payment{
+		ID:                uuid.New(),
+		PackagePurchaseID: &purchase.ID,
+		UserID:            userID,
+		Amount:            int64(purchase.PriceCents),
+		Currency:          purchase.Currency,
+	}, purchase.ID.String())
+}
+
+// CreateSeriesPayment opens one gateway order for every occurrence of a
+// recurring series. Once paid, all of them are confirmed together.
+func (s *PaymentService) CreateSeriesPayment(
+	ctx context.Context,
+	seriesID uuid.UUID,
+	userID uuid.UUID,
+) (*models.Payment, error) {
+
+	series, err := s.seriesRepo.GetByID(ctx, seriesID)
 	if err != nil {
 		return nil, err
 	}
 
-	payment := &models.Payment{
-		ID:             uuid.New(),
-		BookingID:      booking.ID,
-		UserID:         userID,
-		Gateway:        "razorpay",
-		GatewayOrderID: orderID,
-		Amount:         int64(booking.PriceCents),
-		Currency:       "INR",
-		Status:         "created",
+	if series.UserID != userID {
+		return nil, errors.New("unauthorized")
 	}
 
+	bookings, err := s.seriesRepo.ListBookings(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Occurrences cancelled before paying are left out of the order
+	var amount int64
+	for _, b := range bookings {
+		switch b.Status {
+		case models.BookingStatusPending:
+			amount += int64(b.PriceCents)
+		case models.BookingStatusCancelled:
+		default:
+			return nil, errors.New("booking series is not awaiting payment")
+		}
+	}
+
+	if amount == 0 {
+		return nil, errors.New("booking series is not awaiting payment")
+	}
+
+	return s.createOrder(ctx, &models.Payment{
+		ID:       uuid.New(),
+		SeriesID: &series.ID,
+		UserID:   userID,
+		Amount:   amount,
+		Currency: series.Currency,
+	}, series.ID.String())
+}
+
+// createOrder opens a gateway order for payment.Amount and stores the payment
+func (s *PaymentService) createOrder(
+	ctx context.Context,
+	payment *models.Payment,
+	receipt string,
+) (*models.Payment, error) {
+
+	if err := s.gateway.ValidateAmount(payment.Amount, payment.Currency); err != nil {
+		return nil, err
+	}
+
+	orderID, err := s.gateway.CreateOrder(payment.Amount, payment.Currency, receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	payment.Gateway = s.gateway.Name()
+	payment.GatewayOrderID = orderID
+	payment.Status = models.PaymentStatusCreated
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	if err := s.paymentRepo.Create(ctx, tx, payment); err != nil {
 		return nil, err
 	}
@@ -92,7 +227,7 @@ func (s *PaymentService) CreatePayment(
 func (s *PaymentService) VerifyPayment(
 	ctx context.Context,
 	paymentID uuid.UUID,
-	razorpayPaymentID string,
+	gatewayPaymentID string,
 	signature string,
 ) error {
 
@@ -101,99 +236,346 @@ func (s *PaymentService) VerifyPayment(
 		return err
 	}
 
-	data := payment.GatewayOrderID + "|" + razorpayPaymentID
+	// The captured webhook may have confirmed the booking already
+	if isCaptured(payment) {
+		return nil
+	}
+
+	if err := s.gateway.VerifyPaymentSignature(
+		payment.GatewayOrderID,
+		gatewayPaymentID,
+		signature,
+	); err != nil {
+		return err
+	}
+
+	_, err = s.settleCapture(ctx, payment, gatewayPaymentID, func(tx *sql.Tx) error {
+		return s.paymentRepo.MarkPaid(
+			ctx,
+			tx,
+			paymentID,
+			gatewayPaymentID,
+			signature,
+		)
+	})
+
+	return err
+}
+
+// ParseWebhook authenticates a raw webhook and normalises it
+func (s *PaymentService) ParseWebhook(
+	payload []byte,
+	header http.Header,
+) (*GatewayEvent, error) {
+
+	if err := s.gateway.VerifyWebhook(payload, header); err != nil {
+		return nil, err
+	}
+
+	return s.gateway.ParseWebhook(payload, header)
+}
+
+// HandleEvent applies a verified gateway event. Event types we don't
+// act on are ignored.
+func (s *PaymentService) HandleEvent(event *GatewayEvent) error {
+	switch event.Type {
+	case GatewayEventPaymentCaptured:
+		return s.HandlePaymentCaptured(event)
+	case GatewayEventPaymentFailed:
+		return s.HandlePaymentFailed(event)
+	case GatewayEventRefundProcessed:
+		return s.HandleRefundProcessed(event)
+	case GatewayEventRefundFailed:
+		return s.HandleRefundFailed(event)
+	}
+
+	return nil
+}
+
+func (s *PaymentService) HandlePaymentCaptured(
+	event *GatewayEvent,
+) error {
+
+	_, err := s.applyCapture(event)
+	return err
+}
+
+// applyCapture settles a capture reported by the gateway and reports
+// whether what the payment paid for was delivered
+func (s *PaymentService) applyCapture(
+	event *GatewayEvent,
+) (bool, error) {
+
+	ctx := context.Background()
 
-	h := hmac.New(sha256.New, []byte(s.razorpaySecret))
-	h.Write([]byte(data))
-	expected := hex.EncodeToString(h.Sum(nil))
+	payment, err := s.paymentRepo.GetByGatewayOrderID(ctx, event.OrderID)
+	if err != nil {
+		return false, err
+	}
 
-	if expected != signature {
-		return errors.New("invalid payment signature")
+	if isCaptured(payment) {
+		return true, nil // idempotent
 	}
 
+	return s.settleCapture(ctx, payment, event.PaymentID, func(tx *sql.Tx) error {
+		return s.paymentRepo.MarkPaidByGateway(tx, payment.ID, event.PaymentID)
+	})
+}
+
+// settleCapture records a captured payment with markPaid and delivers
+// what it paid for. A capture that arrives after its payment failed or
+// expired still confirms the booking while its slot is free. When
+// nothing can be delivered any more the payment is kept as paid and
+// refunded in full, so the learner is never charged for nothing. It
+// reports whether the payment was delivered.
+func (s *PaymentService) settleCapture(
+	ctx context.Context,
+	payment *models.Payment,
+	gatewayPaymentID string,
+	markPaid func(tx *sql.Tx) error,
+) (bool, error) {
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer tx.Rollback()
 
-	if err := s.paymentRepo.MarkPaid(
-		ctx,
-		tx,
-		paymentID,
-		razorpayPaymentID,
-		signature,
-	); err != nil {
-		return err
+	// A concurrent verify or webhook captured it first
+	err = markPaid(tx)
+	if errors.Is(err, repositories.ErrPaymentSettled) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
 	}
 
-	if err := s.bookingRepo.MarkConfirmed(ctx, tx, payment.BookingID); err != nil {
-		return err
+	err = s.deliverTx(ctx, tx, payment)
+	if errors.Is(err, errUndeliverable) {
+		if err := tx.Commit(); err != nil {
+			return false, err
+		}
+
+		log.Warn().
+			Str("payment_id", payment.ID.String()).
+			Str("status", payment.Status).
+			Msg("captured payment can't be delivered, refunding it")
+
+		payment.Status = models.PaymentStatusPaid
+		payment.GatewayPaymentID = &gatewayPaymentID
+
+		_, err := s.refundPayment(ctx, payment, payment.BookingID, payment.Amount, "undeliverable_capture")
+		return false, err
+	}
+	if err != nil {
+		return false, err
 	}
 
-	return tx.Commit()
+	if err := s.invoices.IssueTx(ctx, tx, payment.ID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
 }
 
-func (s *PaymentService) VerifyWebhookSignature(
-	payload []byte,
-	signature string,
+// deliverTx delivers what a captured payment paid for - confirms the
+// booking or series or activates the package purchase - and posts the
+// revenue to the ledger. It returns errUndeliverable when that is no
+// longer possible.
+func (s *PaymentService) deliverTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	payment *models.Payment,
 ) error {
-	h := hmac.New(sha256.New, []byte(s.razorpaySecret))
-	h.Write(payload)
 
-	expected := hex.EncodeToString(h.Sum(nil))
-	if expected != signature {
-		return errors.New("invalid webhook signature")
+	if payment.PackagePurchaseID != nil {
+		purchase, err := s.packageRepo.ActivatePurchaseTx(ctx, tx, *payment.PackagePurchaseID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errUndeliverable
+		}
+		if err != nil {
+			return err
+		}
+
+		return s.ledger.RecordPackageRevenueTx(ctx, tx, purchase)
 	}
-	return nil
-}
 
-func (s *PaymentService) HandlePaymentCaptured(
-	event dtos.RazorpayWebhookEvent,
-) error {
+	// Each occurrence is posted as its own booking revenue so a
+	// cancelled occurrence can be refunded against it
+	if payment.SeriesID != nil {
+		bookings, err := s.confirmSeriesTx(ctx, tx, *payment.SeriesID)
+		if err != nil {
+			return err
+		}
 
-	orderID := event.Payload.Payment.Entity.OrderID
-	paymentID := event.Payload.Payment.Entity.ID
+		for _, b := range bookings {
+			if err := s.ledger.RecordBookingRevenueTx(ctx, tx, b); err != nil {
+				return err
+			}
+		}
 
-	payment, err := s.paymentRepo.GetByGatewayOrderID(context.Background(), orderID)
+		return nil
+	}
+
+	booking, err := s.bookingRepo.GetByIDForUpdateTx(ctx, tx, *payment.BookingID)
 	if err != nil {
 		return err
 	}
 
-	if payment.Status == "paid" {
-		return nil // idempotent
+	switch {
+	case booking.Status == models.BookingStatusPending:
+		if err := s.bookingRepo.MarkConfirmed(ctx, tx, booking.ID); err != nil {
+			return err
+		}
+
+	case lostForPayment(booking):
+		free, err := s.slotStillFreeTx(ctx, tx, booking)
+		if err != nil {
+			return err
+		}
+		if !free {
+			return errUndeliverable
+		}
+
+		if err := s.bookingRepo.ReviveTx(ctx, tx, booking.ID); err != nil {
+			return err
+		}
+
+	default:
+		return errUndeliverable
 	}
 
-	tx, err := s.db.Begin()
+	booking.Status = models.BookingStatusConfirmed
+
+	return s.ledger.RecordBookingRevenueTx(ctx, tx, booking)
+}
+
+// confirmSeriesTx confirms the occurrences a series payment paid for:
+// the pending ones, or - for a late capture - the ones that lapsed
+// unpaid, as long as every one of their slots is still free
+func (s *PaymentService) confirmSeriesTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	seriesID uuid.UUID,
+) ([]*models.Booking, error) {
+
+	occurrences, err := s.seriesRepo.ListBookings(ctx, seriesID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	if err := s.paymentRepo.MarkPaidByGateway(
-		tx,
-		payment.ID,
-		paymentID,
-	); err != nil {
-		return err
+	var lost []*models.Booking
+	for _, b := range occurrences {
+		if b.Status == models.BookingStatusPending {
+			return s.seriesRepo.ConfirmBookingsTx(ctx, tx, seriesID)
+		}
+		if lostForPayment(b) {
+			lost = append(lost, b)
+		}
 	}
 
-	if err := s.bookingRepo.MarkConfirmed(
-		context.Background(),
+	if len(lost) == 0 {
+		return nil, errUndeliverable
+	}
+
+	for _, b := range lost {
+		free, err := s.slotStillFreeTx(ctx, tx, b)
+		if err != nil {
+			return nil, err
+		}
+		if !free {
+			return nil, errUndeliverable
+		}
+	}
+
+	return s.seriesRepo.ReviveBookingsTx(ctx, tx, seriesID)
+}
+
+// slotStillFreeTx reports whether a booking that lapsed unpaid can have
+// its slot back: it hasn't started, nothing booked or blocked since
+// overlaps it, and a group seat still has room
+func (s *PaymentService) slotStillFreeTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	b *models.Booking,
+) (bool, error) {
+
+	if !b.StartsAt.After(time.Now()) {
+		return false, nil
+	}
+
+	mentor, err := s.mentorRepo.FindByID(b.MentorID)
+	if err != nil {
+		return false, err
+	}
+
+	service, err := s.serviceRepo.FindByIDIncludingInactive(ctx, b.ServiceID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.mentorRepo.LockTx(ctx, tx, mentor.ID); err != nil {
+		return false, err
+	}
+
+	buffer := sessionBuffer(mentor)
+
+	if !service.IsGroup() {
+		conflict, err := s.bookingRepo.HasConflictTx(
+			ctx,
+			tx,
+			mentor.ID,
+			b.ID,
+			"",
+			b.StartsAt.Add(-buffer),
+			b.EndsAt.Add(buffer),
+		)
+		return !conflict, err
+	}
+
+	taken, mine, err := s.bookingRepo.GroupSeatsTx(ctx, tx, service.ID, b.StartsAt, b.UserID)
+	if err != nil || mine || taken >= service.Capacity {
+		return false, err
+	}
+
+	conflict, err := s.bookingRepo.HasGroupConflictTx(
+		ctx,
 		tx,
-		payment.BookingID,
-	); err != nil {
-		return err
+		mentor.ID,
+		service.ID,
+		b.StartsAt,
+		b.StartsAt.Add(-buffer),
+		b.EndsAt.Add(buffer),
+	)
+
+	return !conflict, err
+}
+
+// lostForPayment reports whether a booking gave up its slot because it
+// wasn't paid for: its payment failed or it expired unpaid
+func lostForPayment(b *models.Booking) bool {
+	switch b.Status {
+	case models.BookingStatusPaymentFailed:
+		return true
+	case models.BookingStatusCancelled:
+		return b.CancellationReason != nil && *b.CancellationReason == "payment_timeout"
 	}
 
-	return tx.Commit()
+	return false
+}
+
+// isCaptured reports whether a payment's capture was already recorded
+func isCaptured(p *models.Payment) bool {
+	return p.Status == models.PaymentStatusPaid ||
+		p.Status == models.PaymentStatusRefunded ||
+		p.Status == models.PaymentStatusPartiallyRefunded
 }
 
 func (s *PaymentService) HandlePaymentFailed(
-	event dtos.RazorpayWebhookEvent,
+	event *GatewayEvent,
 ) error {
 
-	orderID := event.Payload.Payment.Entity.OrderID
+	orderID := event.OrderID
 
 	payment, err := s.paymentRepo.GetByGatewayOrderID(
 		context.Background(),
@@ -204,7 +586,7 @@ func (s *PaymentService) HandlePaymentFailed(
 	}
 
 	// Idempotency guard
-	if payment.Status == "failed" || payment.Status == "paid" {
+	if payment.Status != models.PaymentStatusCreated {
 		return nil
 	}
 
@@ -214,20 +596,320 @@ func (s *PaymentService) HandlePaymentFailed(
 	}
 	defer tx.Rollback()
 
-	if err := s.paymentRepo.MarkFailedByGateway(
-		tx,
-		payment.ID,
-	); err != nil {
+	err = s.paymentRepo.MarkFailedByGateway(tx, payment.ID)
+	if errors.Is(err, repositories.ErrPaymentSettled) {
+		return nil // captured or failed concurrently
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case payment.PackagePurchaseID != nil:
+		err = s.packageRepo.MarkPurchaseFailedTx(
+			context.Background(),
+			tx,
+			*payment.PackagePurchaseID,
+		)
+	case payment.SeriesID != nil:
+		err = s.seriesRepo.MarkPaymentFailedTx(
+			context.Background(),
+			tx,
+			*payment.SeriesID,
+		)
+	default:
+		err = s.bookingRepo.MarkPaymentFailed(
+			context.Background(),
+			tx,
+			*payment.BookingID,
+		)
+	}
+	if err != nil {
 		return err
 	}
 
-	if err := s.bookingRepo.MarkPaymentFailed(
+	return tx.Commit()
+}
+
+// RefundBooking refunds up to `amount` of the captured payment of a booking
+// through the payment gateway. The refund is recorded before the gateway call so a
+// crash in between still leaves a trace for reconciliation. When the
+// gateway rejects the refund the returned record is marked failed.
+func (s *PaymentService) RefundBooking(
+	ctx context.Context,
+	bookingID uuid.UUID,
+	amount int64,
+	reason string,
+) (*models.Refund, error) {
+
+	payment, err := s.paymentRepo.GetRefundableByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.refundPayment(ctx, payment, &bookingID, amount, reason)
+}
+
+// refundPayment refunds up to `amount` of a captured payment, for
+// bookingID when the refund is for one booking
+func (s *PaymentService) refundPayment(
+	ctx context.Context,
+	payment *models.Payment,
+	bookingID *uuid.UUID,
+	amount int64,
+	reason string,
+) (*models.Refund, error) {
+
+	if payment.GatewayPaymentID == nil {
+		return nil, errors.New("payment has no gateway payment id")
+	}
+
+	refund := &models.Refund{
+		ID:        uuid.New(),
+		PaymentID: payment.ID,
+		BookingID: bookingID,
+		Amount:    amount,
+		Currency:  payment.Currency,
+		Status:    models.RefundStatusPending,
+		Reason:    reason,
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.paymentRepo.LockTx(ctx, tx, payment.ID); err != nil {
+		return nil, err
+	}
+
+	alreadyRefunded, err := s.refundRepo.SumOutstandingTx(ctx, tx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if remaining := payment.Amount - alreadyRefunded; refund.Amount > remaining {
+		refund.Amount = remaining
+	}
+
+	if refund.Amount <= 0 {
+		return nil, errors.New("payment already fully refunded")
+	}
+
+	if err := s.refundRepo.CreateTx(ctx, tx, refund); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	gatewayRefund, err := s.gateway.CreateRefund(
+		*payment.GatewayPaymentID,
+		refund.Amount,
+		refund.ID.String(),
+	)
+	if err != nil {
+		refund.Status = models.RefundStatusFailed
+		s.refundRepo.MarkFailed(ctx, refund.ID, err.Error())
+		return refund, err
+	}
+
+	refund.GatewayRefundID = &gatewayRefund.ID
+	if err := s.refundRepo.MarkSubmitted(ctx, refund.ID, gatewayRefund.ID); err != nil {
+		return refund, err
+	}
+
+	// Instant refunds may already be processed; otherwise the
+	// refund.processed webhook finishes the job.
+	if gatewayRefund.Status == string(models.RefundStatusProcessed) {
+		if err := s.applyRefundProcessed(ctx, gatewayRefund.ID, refund.ID.String(), payment, refund.Amount); err != nil {
+			return refund, err
+		}
+		refund.Status = models.RefundStatusProcessed
+	}
+
+	return refund, nil
+}
+
+func (s *PaymentService) HandleRefundProcessed(
+	event *GatewayEvent,
+) error {
+
+	payment, err := s.paymentRepo.GetByGatewayPaymentID(
 		context.Background(),
-		tx,
-		payment.BookingID,
-	); err != nil {
+		event.PaymentID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.applyRefundProcessed(
+		context.Background(),
+		event.RefundID,
+		event.Receipt,
+		payment,
+		event.Amount,
+	)
+}
+
+func (s *PaymentService) HandleRefundFailed(
+	event *GatewayEvent,
+) error {
+
+	ctx := context.Background()
+
+	refund, err := s.refundRepo.GetByGatewayRefundID(ctx, event.RefundID)
+
+	// The failure can beat RefundBooking to recording the gateway's
+	// refund ID; the receipt still names our refund
+	if errors.Is(err, sql.ErrNoRows) {
+		if id, parseErr := uuid.Parse(event.Receipt); parseErr == nil {
+			refund, err = s.refundRepo.GetUnsubmitted(ctx, id)
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // not one of ours, nothing to update
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.refundRepo.MarkFailed(ctx, refund.ID, "refund failed at gateway")
+}
+
+// applyRefundProcessed marks a refund processed and moves the payment to
+// refunded / partially_refunded based on everything refunded so far.
+func (s *PaymentService) applyRefundProcessed(
+	ctx context.Context,
+	gatewayRefundID string,
+	receipt string,
+	payment *models.Payment,
+	amount int64,
+) error {
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.paymentRepo.LockTx(ctx, tx, payment.ID); err != nil {
+		return err
+	}
+
+	refund, err := s.refundRepo.GetByGatewayRefundIDTx(ctx, tx, gatewayRefundID)
+	if errors.Is(err, sql.ErrNoRows) {
+		refund, err = s.claimRefundTx(ctx, tx, payment, gatewayRefundID, receipt, amount)
+	}
+	if err != nil {
+		return err
+	}
+
+	if refund.Status == models.RefundStatusProcessed {
+		return nil // idempotent
+	}
+
+	if err := s.refundRepo.MarkProcessedTx(ctx, tx, refund.ID); err != nil {
+		return err
+	}
+
+	// Series revenue is posted per occurrence, so a series refund is
+	// taken from the occurrence it was made for
+	reference := paymentReference(payment)
+	if payment.SeriesID != nil && refund.BookingID != nil {
+		reference = *refund.BookingID
+	}
+
+	if err := s.ledger.RecordRefundTx(ctx, tx, refund, reference); err != nil {
+		return err
+	}
+
+	refunded, err := s.refundRepo.SumProcessedTx(ctx, tx, payment.ID)
+	if err != nil {
+		return err
+	}
+
+	status := models.PaymentStatusPartiallyRefunded
+	if refunded >= payment.Amount {
+		status = models.PaymentStatusRefunded
+	}
+
+	if err := s.paymentRepo.UpdateStatusTx(ctx, tx, payment.ID, status); err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
+
+// claimRefundTx records which refund a gateway refund ID we haven't seen
+// belongs to. The webhook can beat RefundBooking to recording the ID, so
+// one of the payment's unacknowledged refunds is claimed first - the one
+// named by the receipt, else the oldest for the same amount. Refunds
+// created outside OpenCall (e.g. from the gateway dashboard) are recorded
+// on the fly so finance sees every refund in one place.
+func (s *PaymentService) claimRefundTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	payment *models.Payment,
+	gatewayRefundID string,
+	receipt string,
+	amount int64,
+) (*models.Refund, error) {
+
+	unsubmitted, err := s.refundRepo.ListUnsubmittedTx(ctx, tx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if refund := matchRefund(unsubmitted, receipt, amount); refund != nil {
+		refund.GatewayRefundID = &gatewayRefundID
+		return refund, s.refundRepo.AttachGatewayRefundIDTx(ctx, tx, refund.ID, gatewayRefundID)
+	}
+
+	refund := &models.Refund{
+		ID:              uuid.New(),
+		PaymentID:       payment.ID,
+		BookingID:       payment.BookingID,
+		GatewayRefundID: &gatewayRefundID,
+		Amount:          amount,
+		Currency:        payment.Currency,
+		Status:          models.RefundStatusPending,
+		Reason:          "external",
+	}
+
+	return refund, s.refundRepo.CreateTx(ctx, tx, refund)
+}
+
+// matchRefund picks the refund the receipt names, else the oldest one
+// for `amount`
+func matchRefund(refunds []*models.Refund, receipt string, amount int64) *models.Refund {
+	for _, r := range refunds {
+		if r.ID.String() == receipt {
+			return r
+		}
+	}
+
+	for _, r := range refunds {
+		if r.Amount == amount {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// paymentReference is the booking, package purchase or series a payment is for
+func paymentReference(p *models.Payment) uuid.UUID {
+	if p.PackagePurchaseID != nil {
+		return *p.PackagePurchaseID
+	}
+	if p.SeriesID != nil {
+		return *p.SeriesID
+	}
+	return *p.BookingID
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
+)
+
+type PromoService struct {
+	promoRepo  *repositories.PromoCodeRepository
+	mentorRepo *repositories.MentorRepository
+}
+
+func NewPromoService(
+	promoRepo *repositories.PromoCodeRepository,
+	mentorRepo *repositories.MentorRepository,
+) *PromoService {
+	return &PromoService{
+		promoRepo:  promoRepo,
+		mentorRepo: mentorRepo,
+	}
+}
+
+// CreateMentorCode creates a code that only applies to the calling mentor's services
+func (s *PromoService) CreateMentorCode(
+	userID uuid.UUID,
+	req *dtos.CreatePromoCodeRequest,
+) (*dtos.PromoCodeResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("mentor profile not found")
+	}
+
+	return s.create(userID, &mentor.ID, req)
+}
+
+// CreatePlatformCode creates a code that applies to every mentor's services
+func (s *PromoService) CreatePlatformCode(
+	adminID uuid.UUID,
+	req *dtos.CreatePromoCodeRequest,
+) (*dtos.PromoCodeResponse, error) {
+
+	return s.create(adminID, nil, req)
+}
+
+func (s *PromoService) ListMentorCodes(
+	userID uuid.UUID,
+) ([]*dtos.PromoCodeResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("mentor profile not found")
+	}
+
+	return s.list(&mentor.ID)
+}
+
+func (s *PromoService) ListPlatformCodes() ([]*dtos.PromoCodeResponse, error) {
+	return s.list(nil)
+}
+
+func (s *PromoService) create(
+	createdBy uuid.UUID,
+	mentorID *uuid.UUID,
+	req *dtos.CreatePromoCodeRequest,
+) (*dtos.PromoCodeResponse, error) {
+
+	code := &models.PromoCode{
+		ID:             uuid.New(),
+		Code:           strings.ToUpper(req.Code),
+		MentorID:       mentorID,
+		DiscountType:   models.PromoDiscountType(req.DiscountType),
+		MaxRedemptions: req.MaxRedemptions,
+		MaxPerUser:     req.MaxPerUser,
+		StartsAt:       req.StartsAt,
+		ExpiresAt:      req.ExpiresAt,
+		IsActive:       true,
+		CreatedBy:      createdBy,
+	}
+
+	switch code.DiscountType {
+	case models.PromoDiscountPercent:
+		if req.PercentOff == 0 {
+			return nil, errors.New("percent_off is required for percent codes")
+		}
+		code.PercentOff = req.PercentOff
+
+	case models.PromoDiscountFixed:
+		if req.AmountOffCents == 0 || req.Currency == "" {
+			return nil, errors.New("amount_off_cents and currency are required for fixed codes")
+		}
+		currency := strings.ToUpper(req.Currency)
+		if err := utils.ValidateCurrency(currency); err != nil {
+			return nil, err
+		}
+		code.AmountOffCents = req.AmountOffCents
+		code.Currency = &currency
+	}
+
+	if code.StartsAt != nil && code.ExpiresAt != nil && !code.StartsAt.Before(*code.ExpiresAt) {
+		return nil, errors.New("starts_at must be before expires_at")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.promoRepo.Create(ctx, code); err != nil {
+		return nil, err
+	}
+
+	return toPromoCodeResponse(code), nil
+}
+
+func (s *PromoService) list(mentorID *uuid.UUID) ([]*dtos.PromoCodeResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	codes, err := s.promoRepo.ListByMentor(ctx, mentorID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dtos.PromoCodeResponse, 0, len(codes))
+	for _, c := range codes {
+		resp = append(resp, toPromoCodeResponse(c))
+	}
+
+	return resp, nil
+}
+
+// ReserveTx locks a code and checks it can be used by userID on a booking
+// of `service` right now. It returns the code and the discount in minor
+// units. The code stays locked until tx ends, so the RedeemTx that
+// follows can't push it past its limits.
+func (s *PromoService) ReserveTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	codeText string,
+	userID uuid.UUID,
+	service *models.MentorService,
+) (*models.PromoCode, int, error) {
+
+	code, err := s.promoRepo.GetByCodeForUpdateTx(ctx, tx, strings.TrimSpace(codeText))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+
+	switch {
+	case !code.IsActive:
+		return nil, 0, errors.New("invalid promo code")
+	case code.StartsAt != nil && now.Before(*code.StartsAt):
+		return nil, 0, errors.New("promo code is not active yet")
+	case code.ExpiresAt != nil && !now.Before(*code.ExpiresAt):
+		return nil, 0, errors.New("promo code has expired")
+	case code.MentorID != nil && *code.MentorID != service.MentorID:
+		return nil, 0, errors.New("promo code does not apply to this mentor")
+	case code.MaxRedemptions != nil && code.RedemptionCount >= *code.MaxRedemptions:
+		return nil, 0, errors.New("promo code usage limit reached")
+	}
+
+	if code.MaxPerUser != nil {
+		used, err := s.promoRepo.CountUserRedemptionsTx(ctx, tx, code.ID, userID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if used >= *code.MaxPerUser {
+			return nil, 0, errors.New("promo code already used")
+		}
+	}
+
+	var discount int
+	switch code.DiscountType {
+	case models.PromoDiscountPercent:
+		discount = service.PriceCents * code.PercentOff / 100
+	case models.PromoDiscountFixed:
+		if code.Currency == nil || *code.Currency != service.Currency {
+			return nil, 0, errors.New("promo code does not apply to this currency")
+		}
+		discount = int(code.AmountOffCents)
+	}
+
+	if discount > service.PriceCents {
+		discount = service.PriceCents
+	}
+
+	return code, discount, nil
+}
+
+// RedeemTx records that code was used on a booking. Call it in the same
+// transaction as ReserveTx, after the booking row exists.
+func (s *PromoService) RedeemTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	code *models.PromoCode,
+	bookingID uuid.UUID,
+	userID uuid.UUID,
+	discount int,
+) error {
+
+	return s.promoRepo.RedeemTx(ctx, tx, &models.PromoRedemption{
+		ID:            uuid.New(),
+		PromoCodeID:   code.ID,
+		BookingID:     bookingID,
+		UserID:        userID,
+		DiscountCents: int64(discount),
+	})
+}
+
+// ReleaseTx gives a cancelled booking's redemption back to its code
+func (s *PromoService) ReleaseTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	bookingID uuid.UUID,
+) error {
+
+	return s.promoRepo.ReleaseByBookingTx(ctx, tx, bookingID)
+}
+
+func toPromoCodeResponse(c *models.PromoCode) *dtos.PromoCodeResponse {
+	return &dtos.PromoCodeResponse{
+		ID:              c.ID,
+		Code:            c.Code,
+		MentorID:        c.MentorID,
+		DiscountType:    string(c.DiscountType),
+		PercentOff:      c.PercentOff,
+		AmountOffCents:  c.AmountOffCents,
+		Currency:        c.Currency,
+		MaxRedemptions:  c.MaxRedemptions,
+		MaxPerUser:      c.MaxPerUser,
+		RedemptionCount: c.RedemptionCount,
+		StartsAt:        c.StartsAt,
+		ExpiresAt:       c.ExpiresAt,
+		IsActive:        c.IsActive,
+	}
+}
@@ -1,25 +1,87 @@
 package services
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"strings"
 
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/utils"
 	"github.com/razorpay/razorpay-go"
 )
 
+const (
+	razorpaySignatureHeader = "X-Razorpay-Signature"
+	razorpayEventIDHeader   = "X-Razorpay-Event-Id"
+)
+
 type RazorpayClient struct {
-	client *razorpay.Client
+	client        *razorpay.Client
+	keySecret     string
+	webhookSecret string
 }
 
-func NewRazorpayClient(key, secret string) *RazorpayClient {
+func NewRazorpayClient(key, secret, webhookSecret string) *RazorpayClient {
 	return &RazorpayClient{
-		client: razorpay.NewClient(key, secret),
+		client:        razorpay.NewClient(key, secret),
+		keySecret:     secret,
+		webhookSecret: webhookSecret,
 	}
 }
 
-func (r *RazorpayClient) CreateOrder(amount int64, receipt string) (string, error) {
+func (r *RazorpayClient) Name() string {
+	return "razorpay"
+}
+
+// razorpayCurrencies are the currencies Razorpay accepts for international payments
+var razorpayCurrencies = map[string]bool{
+	"AED": true, "ALL": true, "AMD": true, "ARS": true, "AUD": true, "AWG": true,
+	"BBD": true, "BDT": true, "BHD": true, "BMD": true, "BND": true, "BOB": true,
+	"BSD": true, "BWP": true, "BZD": true, "CAD": true, "CHF": true, "CLP": true,
+	"CNY": true, "COP": true, "CRC": true, "CUP": true, "CZK": true, "DKK": true,
+	"DOP": true, "DZD": true, "EGP": true, "ETB": true, "EUR": true, "FJD": true,
+	"GBP": true, "GHS": true, "GIP": true, "GMD": true, "GTQ": true, "GYD": true,
+	"HKD": true, "HNL": true, "HTG": true, "HUF": true, "IDR": true, "ILS": true,
+	"INR": true, "JMD": true, "JOD": true, "JPY": true, "KES": true, "KGS": true,
+	"KHR": true, "KRW": true, "KWD": true, "KYD": true, "KZT": true, "LAK": true,
+	"LKR": true, "LRD": true, "LSL": true, "MAD": true, "MDL": true, "MKD": true,
+	"MMK": true, "MNT": true, "MOP": true, "MUR": true, "MVR": true, "MWK": true,
+	"MXN": true, "MYR": true, "NAD": true, "NGN": true, "NIO": true, "NOK": true,
+	"NPR": true, "NZD": true, "OMR": true, "PEN": true, "PGK": true, "PHP": true,
+	"PKR": true, "PYG": true, "QAR": true, "RUB": true, "SAR": true, "SCR": true,
+	"SEK": true, "SGD": true, "SOS": true, "SSP": true, "SVC": true, "SZL": true,
+	"THB": true, "TTD": true, "TZS": true, "UGX": true, "USD": true, "UYU": true,
+	"UZS": true, "VND": true, "XAF": true, "XOF": true, "YER": true, "ZAR": true,
+}
+
+// ValidateAmount applies Razorpay's rules: the currency must be enabled,
+// the amount must be at least 100 minor units (1 major unit for 2-decimal
+// currencies) and three-decimal currencies must end in 0.
+func (r *RazorpayClient) ValidateAmount(amount int64, currency string) error {
+	exp, ok := utils.CurrencyExponent(currency)
+	if !ok || !razorpayCurrencies[strings.ToUpper(currency)] {
+		return errors.New("currency not supported by razorpay: " + currency)
+	}
+
+	if amount < 100 {
+		return errors.New("amount below razorpay minimum")
+	}
+
+	if exp == 3 && amount%10 != 0 {
+		return errors.New("razorpay requires the last decimal of " + currency + " amounts to be 0")
+	}
+
+	return nil
+}
+
+func (r *RazorpayClient) CreateOrder(amount int64, currency string, receipt string) (string, error) {
 	data := map[string]interface{}{
 		"amount":   amount,
-		"currency": "INR",
+		"currency": strings.ToUpper(currency),
 		"receipt":  receipt,
 	}
 
@@ -35,3 +97,147 @@ func (r *RazorpayClient) CreateOrder(amount int64, receipt string) (string, erro
 
 	return orderID, nil
 }
+
+// VerifyPaymentSignature checks the checkout signature, which Razorpay
+// computes over "order_id|payment_id" with the API key secret
+func (r *RazorpayClient) VerifyPaymentSignature(
+	orderID string,
+	paymentID string,
+	signature string,
+) error {
+
+	if !validHMAC(r.keySecret, []byte(orderID+"|"+paymentID), signature) {
+		return errors.New("invalid payment signature")
+	}
+	return nil
+}
+
+// VerifyWebhook checks X-Razorpay-Signature, which is signed with the
+// webhook secret configured in the Razorpay dashboard
+func (r *RazorpayClient) VerifyWebhook(payload []byte, header http.Header) error {
+	if !validHMAC(r.webhookSecret, payload, header.Get(razorpaySignatureHeader)) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
+
+func (r *RazorpayClient) ParseWebhook(
+	payload []byte,
+	header http.Header,
+) (*GatewayEvent, error) {
+
+	var event dtos.RazorpayWebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	out := &GatewayEvent{
+		ID:   header.Get(razorpayEventIDHeader),
+		Type: event.Event,
+	}
+
+	switch event.Event {
+	case GatewayEventRefundProcessed, GatewayEventRefundFailed:
+		refund := event.Payload.Refund.Entity
+		out.RefundID = refund.ID
+		out.PaymentID = refund.PaymentID
+		out.Amount = refund.Amount
+		out.Currency = refund.Currency
+		out.Receipt = refund.Receipt
+	default:
+		payment := event.Payload.Payment.Entity
+		out.OrderID = payment.OrderID
+		out.PaymentID = payment.ID
+		out.Amount = payment.Amount
+		out.Currency = payment.Currency
+	}
+
+	return out, nil
+}
+
+// CreateRefund refunds `amount` minor units of a captured Razorpay payment
+func (r *RazorpayClient) CreateRefund(
+	paymentID string,
+	amount int64,
+	receipt string,
+) (*GatewayRefund, error) {
+
+	data := map[string]interface{}{
+		"receipt": receipt,
+		"speed":   "normal",
+	}
+
+	body, err := r.client.Payment.Refund(paymentID, int(amount), data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	refundID, ok := body["id"].(string)
+	if !ok {
+		return nil, errors.New("invalid razorpay refund response")
+	}
+
+	status, _ := body["status"].(string)
+
+	return &GatewayRefund{ID: refundID, Status: status}, nil
+}
+
+// FetchOrder derives the order state from its payments: any captured
+// payment means paid, and an order whose every attempt failed is failed.
+// Authorized payments are still being captured, so they leave it open.
+func (r *RazorpayClient) FetchOrder(orderID string) (*GatewayOrder, error) {
+	body, err := r.client.Order.Payments(orderID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _ := body["items"].([]interface{})
+
+	order := &GatewayOrder{ID: orderID, Status: GatewayOrderOpen}
+	failed := 0
+
+	for _, item := range items {
+		payment, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid razorpay payments response")
+		}
+
+		id, _ := payment["id"].(string)
+		status, _ := payment["status"].(string)
+		amount, _ := payment["amount"].(float64)
+		currency, _ := payment["currency"].(string)
+
+		switch status {
+		case "captured", "refunded":
+			return &GatewayOrder{
+				ID:        orderID,
+				Status:    GatewayOrderPaid,
+				PaymentID: id,
+				Amount:    int64(amount),
+				Currency:  currency,
+			}, nil
+		case "failed":
+			failed++
+			order.PaymentID = id
+			order.Amount = int64(amount)
+			order.Currency = currency
+		}
+	}
+
+	if len(items) > 0 && failed == len(items) {
+		order.Status = GatewayOrderFailed
+	}
+
+	return order, nil
+}
+
+func validHMAC(secret string, payload []byte, signature string) bool {
+	expected := signHMAC(secret, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func signHMAC(secret string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+)
+
+// mentorReviewsShown caps the reviews listed on a mentor's page
+const mentorReviewsShown = 50
+
+type ReviewService struct {
+	reviewRepo  *repositories.ReviewRepository
+	bookingRepo *repositories.BookingRepository
+	mentorRepo  *repositories.MentorRepository
+}
+
+func NewReviewService(
+	reviewRepo *repositories.ReviewRepository,
+	bookingRepo *repositories.BookingRepository,
+	mentorRepo *repositories.MentorRepository,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:  reviewRepo,
+		bookingRepo: bookingRepo,
+		mentorRepo:  mentorRepo,
+	}
+}
+
+// CreateReview lets the learner rate their session once it is completed
+func (s *ReviewService) CreateReview(
+	userID uuid.UUID,
+	bookingID uuid.UUID,
+	req *dtos.CreateReviewRequest,
+) (*dtos.ReviewResponse, error) {
+
+	ctx := context.Background()
+
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	if booking.Status != models.BookingStatusCompleted {
+		return nil, errors.New("only completed sessions can be reviewed")
+	}
+
+	review := &models.Review{
+		ID:        uuid.New(),
+		BookingID: booking.ID,
+		MentorID:  booking.MentorID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Comment:   strings.TrimSpace(req.Comment),
+	}
+
+	if err := s.reviewRepo.Create(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return toReviewResponse(review), nil
+}
+
+// GetMentorReviews shows a mentor's rating and latest reviews
+func (s *ReviewService) GetMentorReviews(
+	username string,
+) (*dtos.MentorReviewsResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUsernameRaw(username)
+	if err != nil || !mentor.IsActive {
+		return nil, errors.New("mentor not found")
+	}
+
+	ctx := context.Background()
+
+	average, count, err := s.reviewRepo.SummaryForMentor(ctx, mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.reviewRepo.ListByMentor(ctx, mentor.ID, mentorReviewsShown)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dtos.MentorReviewsResponse{
+		AverageRating: math.Round(average*10) / 10,
+		ReviewCount:   count,
+		Reviews:       make([]dtos.ReviewResponse, 0, len(reviews)),
+	}
+
+	for _, rv := range reviews {
+		resp.Reviews = append(resp.Reviews, *toReviewResponse(rv))
+	}
+
+	return resp, nil
+}
+
+func toReviewResponse(rv *models.Review) *dtos.ReviewResponse {
+	return &dtos.ReviewResponse{
+		ID:        rv.ID,
+		BookingID: rv.BookingID,
+		Reviewer:  rv.Username,
+		Rating:    rv.Rating,
+		Comment:   rv.Comment,
+		CreatedAt: rv.CreatedAt,
+	}
+}
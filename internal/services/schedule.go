@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+)
+
+// Availability rules are wall-clock times in the mentor's timezone and
+// bookings are absolute instants. The helpers here turn one into the
+// other so every caller agrees on what a rule means on a given day,
+// including across DST changes.
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// availabilityWindow is one occurrence of a weekly rule
+type availabilityWindow struct {
+	Start time.Time
+	End   time.Time
+
+	// Free seats, for slots of group services
+	SeatsLeft int
+}
+
+// mentorLocation is the mentor's timezone. Profiles created before the
+// zone was validated fall back to UTC.
+func mentorLocation(mentor *models.MentorProfile) *time.Location {
+	loc, err := time.LoadLocation(mentor.Timezone)
+	if err != nil || mentor.Timezone == "" {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// loadLocation resolves an IANA zone name, using fallback when it is empty
+func loadLocation(name string, fallback *time.Location) (*time.Location, error) {
+	if name == "" {
+		return fallback, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	return loc, nil
+}
+
+// parseLocalTime reads a YYYY-MM-DD date and HH:MM clock time in loc.
+// Wall-clock times skipped by a DST change don't exist and are rejected.
+func parseLocalTime(dateStr, clockStr string, loc *time.Location) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return time.Time{}, errors.New("invalid date format")
+	}
+
+	clock, err := time.Parse("15:04", clockStr)
+	if err != nil {
+		return time.Time{}, errors.New("invalid start time")
+	}
+
+	t := wallClock(date, clock, loc)
+	if t.Hour() != clock.Hour() || t.Minute() != clock.Minute() {
+		return time.Time{}, errors.New("start time does not exist in this timezone")
+	}
+
+	return t, nil
+}
+
+// wallClock is the instant at which the clock in loc shows `clock` on
+// `day`. A time skipped by a DST change maps to the end of the gap, so
+// windows touching the gap keep their real length.
+func wallClock(day time.Time, clock time.Time, loc *time.Location) time.Time {
+	t := time.Date(
+		day.Year(), day.Month(), day.Day(),
+		clock.Hour(), clock.Minute(), 0, 0,
+		loc,
+	)
+
+	if t.Hour() != clock.Hour() || t.Minute() != clock.Minute() {
+		_, before := t.Zone()
+		_, after := t.Add(12 * time.Hour).Zone()
+		if after > before {
+			t = t.Add(time.Duration(after-before) * time.Second)
+		}
+	}
+
+	return t
+}
+
+// ruleWindows expands weekly rules into the windows that overlap
+// [from, to). A rule whose end is not after its start runs past midnight
+// into the next day, so 22:00-02:00 and 18:00-00:00 both work.
+func ruleWindows(
+	rules []*models.MentorAvailabilityRule,
+	loc *time.Location,
+	from time.Time,
+	to time.Time,
+) []availabilityWindow {
+
+	var windows []availabilityWindow
+
+	// Start a day early to catch windows running over midnight into `from`
+	first := from.In(loc).AddDate(0, 0, -1)
+	last := to.In(loc)
+
+	for day := dateOf(first); !day.After(dateOf(last)); day = day.AddDate(0, 0, 1) {
+		for _, r := range rules {
+			if r.DayOfWeek != int(day.Weekday()) {
+				continue
+			}
+
+			start := wallClock(day, r.StartTime, loc)
+			end := wallClock(day, r.EndTime, loc)
+			if !end.After(start) {
+				end = wallClock(day.AddDate(0, 0, 1), r.EndTime, loc)
+			}
+
+			if start.Before(to) && end.After(from) {
+				windows = append(windows, availabilityWindow{Start: start, End: end})
+			}
+		}
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	return windows
+}
+
+// overrideWindows expands date overrides into the extra and blocked
+// windows overlapping [from, to)
+func overrideWindows(
+	overrides []*models.MentorAvailabilityOverride,
+	loc *time.Location,
+	from time.Time,
+	to time.Time,
+) (extra []availabilityWindow, blocked []availabilityWindow) {
+
+	first := dateOf(from.In(loc).AddDate(0, 0, -1))
+	last := dateOf(to.In(loc))
+
+	for _, o := range overrides {
+		for day := dateOf(o.StartDate); !day.After(dateOf(o.EndDate)); day = day.AddDate(0, 0, 1) {
+			if day.Before(first) || day.After(last) {
+				continue
+			}
+
+			var w availabilityWindow
+			if o.StartTime == nil || o.EndTime == nil {
+				w.Start = wallClock(day, time.Time{}, loc)
+				w.End = wallClock(day.AddDate(0, 0, 1), time.Time{}, loc)
+			} else {
+				w.Start = wallClock(day, *o.StartTime, loc)
+				w.End = wallClock(day, *o.EndTime, loc)
+				if !w.End.After(w.Start) {
+					w.End = wallClock(day.AddDate(0, 0, 1), *o.EndTime, loc)
+				}
+			}
+
+			if !w.Start.Before(to) || !w.End.After(from) {
+				continue
+			}
+
+			if o.Kind == models.AvailabilityOverrideBlock {
+				blocked = append(blocked, w)
+			} else {
+				extra = append(extra, w)
+			}
+		}
+	}
+
+	return extra, blocked
+}
+
+// mergeWindows combines weekly and extra windows into non-overlapping
+// ones and cuts the blocked time out of them
+func mergeWindows(windows, extra, blocked []availabilityWindow) []availabilityWindow {
+	all := append(append([]availabilityWindow{}, windows...), extra...)
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Start.Before(all[j].Start)
+	})
+
+	var merged []availabilityWindow
+	for _, w := range all {
+		if n := len(merged); n > 0 && !w.Start.After(merged[n-1].End) {
+			if w.End.After(merged[n-1].End) {
+				merged[n-1].End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+
+	for _, b := range blocked {
+		var cut []availabilityWindow
+
+		for _, w := range merged {
+			if !b.Start.Before(w.End) || !b.End.After(w.Start) {
+				cut = append(cut, w)
+				continue
+			}
+			if w.Start.Before(b.Start) {
+				cut = append(cut, availabilityWindow{Start: w.Start, End: b.Start})
+			}
+			if w.End.After(b.End) {
+				cut = append(cut, availabilityWindow{Start: b.End, End: w.End})
+			}
+		}
+
+		merged = cut
+	}
+
+	return merged
+}
+
+// loadWindows returns the mentor's bookable windows overlapping
+// [from, to): the weekly rules plus extra hours, minus blocked time
+func loadWindows(
+	ctx context.Context,
+	availabilityRepo *repositories.MentorAvailabilityRepository,
+	mentor *models.MentorProfile,
+	from time.Time,
+	to time.Time,
+) ([]availabilityWindow, error) {
+
+	loc := mentorLocation(mentor)
+
+	rules, err := availabilityRepo.FindByMentor(mentor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := availabilityRepo.FindOverridesBetween(
+		ctx,
+		mentor.ID,
+		dateOf(from.In(loc).AddDate(0, 0, -1)),
+		dateOf(to.In(loc)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	extra, blocked := overrideWindows(overrides, loc, from, to)
+
+	return mergeWindows(ruleWindows(rules, loc, from, to), extra, blocked), nil
+}
+
+// fitsWindows reports whether [start, end) lies inside a single window
+func fitsWindows(windows []availabilityWindow, start, end time.Time) bool {
+	for _, w := range windows {
+		if !start.Before(w.Start) && !end.After(w.End) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkLeadTime enforces the mentor's minimum notice and booking horizon
+// for a session starting at `start`
+func checkLeadTime(mentor *models.MentorProfile, start time.Time, now time.Time) error {
+	if !start.After(now) {
+		return errors.New("slot is in the past")
+	}
+
+	if start.Before(now.Add(time.Duration(mentor.MinNoticeMinutes) * time.Minute)) {
+		return fmt.Errorf("this mentor needs at least %d minutes notice", mentor.MinNoticeMinutes)
+	}
+
+	if mentor.MaxAdvanceDays > 0 && start.After(now.AddDate(0, 0, mentor.MaxAdvanceDays)) {
+		return fmt.Errorf("this mentor can only be booked %d days ahead", mentor.MaxAdvanceDays)
+	}
+
+	return nil
+}
+
+// sessionBuffer is the free time the mentor keeps around every session
+func sessionBuffer(mentor *models.MentorProfile) time.Duration {
+	return time.Duration(mentor.BufferMinutes) * time.Minute
+}
+
+// mentorDay is the mentor's calendar day containing t, as [start, end)
+func mentorDay(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	day := dateOf(t.In(loc))
+	return wallClock(day, time.Time{}, loc), wallClock(day.AddDate(0, 0, 1), time.Time{}, loc)
+}
+
+// dateOf drops the clock time, keeping the calendar date as shown in t's zone
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
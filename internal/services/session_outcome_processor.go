@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// settleDelay leaves the join window (which closes a few minutes
+	// after the end) and the last leave events time to land before a
+	// session is judged
+	settleDelay = 15 * time.Minute
+
+	// settleLookback bounds how far back ended sessions are judged.
+	// Bookings that ended earlier - e.g. before attendance was tracked -
+	// are left as they are.
+	settleLookback = 7 * 24 * time.Hour
+)
+
+// SessionOutcomeProcessor settles confirmed bookings once their session
+// is over, from the video session's attendance:
+//
+//   - completed when both parties were together for at least `minimum`
+//   - no_show_mentor when the mentor didn't join in time, including when
+//     nobody joined; the learner is refunded in full (or gets their
+//     package credit back)
+//   - no_show_user when the learner didn't join in time
+//   - incomplete when both joined in time but the session was cut short;
+//     nothing is refunded automatically
+//
+// Only completed bookings can be reviewed.
+type SessionOutcomeProcessor struct {
+	bookingRepo      *repositories.BookingRepository
+	videoSessionRepo *repositories.VideoSessionRepository
+	serviceRepo      *repositories.MentorServiceRepository
+	packageRepo      *repositories.PackageRepository
+	promoService     *PromoService
+	paymentService   *PaymentService
+	grace            time.Duration
+	minimum          time.Duration
+	interval         time.Duration
+}
+
+func NewSessionOutcomeProcessor(
+	bookingRepo *repositories.BookingRepository,
+	videoSessionRepo *repositories.VideoSessionRepository,
+	serviceRepo *repositories.MentorServiceRepository,
+	packageRepo *repositories.PackageRepository,
+	promoService *PromoService,
+	paymentService *PaymentService,
+	grace time.Duration,
+	minimum time.Duration,
+	interval time.Duration,
+) *SessionOutcomeProcessor {
+	return &SessionOutcomeProcessor{
+		bookingRepo:      bookingRepo,
+		videoSessionRepo: videoSessionRepo,
+		serviceRepo:      serviceRepo,
+		packageRepo:      packageRepo,
+		promoService:     promoService,
+		paymentService:   paymentService,
+		grace:            grace,
+		minimum:          minimum,
+		interval:         interval,
+	}
+}
+
+// Run settles ended sessions on every tick until ctx is cancelled
+func (p *SessionOutcomeProcessor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.ProcessOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("session outcome processing failed")
+			}
+		}
+	}
+}
+
+// ProcessOnce settles one batch of ended sessions and returns how many
+// it settled
+func (p *SessionOutcomeProcessor) ProcessOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	bookings, err := p.bookingRepo.FindUnsettled(
+		ctx,
+		now.Add(-settleLookback),
+		now.Add(-settleDelay),
+		reaperBatchSize,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+
+	for _, b := range bookings {
+		outcome, err := p.settle(ctx, b)
+		if err != nil {
+			log.Warn().Err(err).Str("booking_id", b.ID.String()).Msg("session not settled")
+			continue
+		}
+
+		log.Info().
+			Str("booking_id", b.ID.String()).
+			Str("outcome", string(outcome)).
+			Msg("session settled")
+
+		settled++
+	}
+
+	return settled, nil
+}
+
+// settle judges one booking's session and records the outcome
+func (p *SessionOutcomeProcessor) settle(
+	ctx context.Context,
+	b *models.Booking,
+) (models.BookingStatus, error) {
+
+	var (
+		mentorJoined *time.Time
+		userJoined   *time.Time
+		together     time.Duration
+	)
+
+	// No video session means nobody ever opened the call. Any other
+	// error leaves the booking for the next tick rather than judging
+	// it on missing data.
+	vs, err := p.videoSessionRepo.GetByBookingID(ctx, b.ID)
+	switch {
+	case errors.Is(err, repositories.ErrVideoSessionNotFound):
+	case err != nil:
+		return "", err
+	default:
+		mentorJoined = vs.MentorJoinedAt
+		userJoined = vs.UserJoinedAt
+		together = attendedTogether(b, vs)
+	}
+
+	service, err := p.serviceRepo.FindByIDIncludingInactive(ctx, b.ServiceID)
+	if err != nil {
+		return "", err
+	}
+
+	// The mentor joins a group session through one of its seats, so the
+	// learner's time together is measured against the mentor's
+	// attendance across all of them
+	if service.IsGroup() {
+		var mentorLeft *time.Time
+
+		mentorJoined, mentorLeft, err = p.videoSessionRepo.MentorAttendanceForGroup(ctx, b.ServiceID, b.StartsAt)
+		if err != nil {
+			return "", err
+		}
+
+		if vs != nil {
+			seat := *vs
+			seat.MentorJoinedAt = mentorJoined
+			seat.MentorLeftAt = mentorLeft
+			together = attendedTogether(b, &seat)
+		}
+	}
+
+	outcome := decideOutcome(b, mentorJoined, userJoined, together, p.grace, p.minimum)
+
+	err = p.bookingRepo.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := p.bookingRepo.SettleTx(ctx, tx, b.ID, outcome); err != nil {
+			return err
+		}
+
+		if outcome != models.BookingStatusNoShowMentor {
+			return nil
+		}
+
+		// A session the mentor missed is treated like one they cancelled
+		if b.PackagePurchaseID != nil {
+			if err := p.packageRepo.ReturnCreditTx(ctx, tx, *b.PackagePurchaseID); err != nil {
+				return err
+			}
+		}
+
+		return p.promoService.ReleaseTx(ctx, tx, b.ID)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The outcome stands even if the gateway refuses the refund; the
+	// failed refund record is left for finance to retry
+	if outcome == models.BookingStatusNoShowMentor && b.PackagePurchaseID == nil && b.PriceCents > 0 {
+		if _, err := p.paymentService.RefundBooking(
+			ctx,
+			b.ID,
+			int64(b.PriceCents),
+			"mentor_no_show",
+		); err != nil {
+			log.Error().Err(err).Str("booking_id", b.ID.String()).Msg("mentor no-show refund failed")
+		}
+	}
+
+	return outcome, nil
+}
+
+// decideOutcome tells how a session went. A session the parties spent
+// `minimum` together in happened, however late it started. Otherwise
+// whoever didn't join within `grace` of the start missed it, the mentor
+// first: when nobody joined the mentor is held responsible and the
+// learner is refunded. A session both joined in time but that fell short
+// of `minimum` is incomplete.
+func decideOutcome(
+	b *models.Booking,
+	mentorJoined *time.Time,
+	userJoined *time.Time,
+	together time.Duration,
+	grace time.Duration,
+	minimum time.Duration,
+) models.BookingStatus {
+
+	if together >= minimum {
+		return models.BookingStatusCompleted
+	}
+
+	deadline := b.StartsAt.Add(grace)
+	onTime := func(joined *time.Time) bool {
+		return joined != nil && !joined.After(deadline)
+	}
+
+	switch {
+	case !onTime(mentorJoined):
+		return models.BookingStatusNoShowMentor
+	case !onTime(userJoined):
+		return models.BookingStatusNoShowUser
+	}
+
+	return models.BookingStatusIncomplete
+}
+
+// attendedTogether is how long both parties were in the session: its
+// recorded duration, or - for sessions that never ended cleanly - the
+// overlap of the two parties' first join and last leave, capped at the
+// booking's end. It is zero unless both parties joined.
+func attendedTogether(b *models.Booking, vs *models.VideoSession) time.Duration {
+	if vs.MentorJoinedAt == nil || vs.UserJoinedAt == nil {
+		return 0
+	}
+
+	recorded := time.Duration(vs.DurationSeconds) * time.Second
+
+	from := *vs.MentorJoinedAt
+	if vs.UserJoinedAt.After(from) {
+		from = *vs.UserJoinedAt
+	}
+
+	to := b.EndsAt
+	for _, left := range []*time.Time{vs.MentorLeftAt, vs.UserLeftAt} {
+		if left != nil && left.Before(to) {
+			to = *left
+		}
+	}
+
+	if overlap := to.Sub(from); overlap > recorded {
+		return overlap
+	}
+
+	return recorded
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+func TestDecideOutcome(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	b := &models.Booking{StartsAt: start, EndsAt: start.Add(time.Hour)}
+
+	const (
+		grace   = 10 * time.Minute
+		minimum = 15 * time.Minute
+	)
+
+	at := func(d time.Duration) *time.Time {
+		t := start.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		mentor   *time.Time
+		user     *time.Time
+		together time.Duration
+		want     models.BookingStatus
+	}{
+		{
+			name:     "both on time",
+			mentor:   at(0),
+			user:     at(2 * time.Minute),
+			together: 50 * time.Minute,
+			want:     models.BookingStatusCompleted,
+		},
+		{
+			name:     "both late but long enough together",
+			mentor:   at(20 * time.Minute),
+			user:     at(25 * time.Minute),
+			together: 30 * time.Minute,
+			want:     models.BookingStatusCompleted,
+		},
+		{
+			name: "only the learner joined",
+			user: at(0),
+			want: models.BookingStatusNoShowMentor,
+		},
+		{
+			name:   "only the mentor joined",
+			mentor: at(0),
+			want:   models.BookingStatusNoShowUser,
+		},
+		{
+			name:     "mentor past the grace period",
+			mentor:   at(grace + time.Second),
+			user:     at(0),
+			together: 5 * time.Minute,
+			want:     models.BookingStatusNoShowMentor,
+		},
+		{
+			name:     "learner past the grace period",
+			mentor:   at(0),
+			user:     at(grace + time.Minute),
+			together: 5 * time.Minute,
+			want:     models.BookingStatusNoShowUser,
+		},
+		{
+			name:     "joining right at the deadline is on time",
+			mentor:   at(grace),
+			user:     at(grace),
+			together: minimum,
+			want:     models.BookingStatusCompleted,
+		},
+		{
+			name:     "both on time but cut short",
+			mentor:   at(0),
+			user:     at(0),
+			together: time.Minute,
+			want:     models.BookingStatusIncomplete,
+		},
+		{
+			name:     "both on time but just short of the minimum",
+			mentor:   at(0),
+			user:     at(0),
+			together: minimum - time.Second,
+			want:     models.BookingStatusIncomplete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decideOutcome(b, tt.mentor, tt.user, tt.together, grace, minimum)
+			if got != tt.want {
+				t.Errorf("decideOutcome = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Nobody joining is put on the mentor, so the learner gets their money back
+func TestDecideOutcomeNobodyJoinedIsMentorNoShow(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	b := &models.Booking{StartsAt: start, EndsAt: start.Add(time.Hour)}
+
+	got := decideOutcome(b, nil, nil, 0, 10*time.Minute, 15*time.Minute)
+	if got != models.BookingStatusNoShowMentor {
+		t.Errorf("decideOutcome = %s, want %s", got, models.BookingStatusNoShowMentor)
+	}
+}
+
+func TestAttendedTogether(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	b := &models.Booking{StartsAt: start, EndsAt: start.Add(time.Hour)}
+
+	at := func(d time.Duration) *time.Time {
+		t := start.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name string
+		vs   *models.VideoSession
+		want time.Duration
+	}{
+		{
+			name: "nothing when the learner never joined",
+			vs: &models.VideoSession{
+				MentorJoinedAt:  at(0),
+				DurationSeconds: 120,
+			},
+			want: 0,
+		},
+		{
+			name: "nothing when the mentor never joined",
+			vs: &models.VideoSession{
+				UserJoinedAt:    at(0),
+				DurationSeconds: 120,
+			},
+			want: 0,
+		},
+		{
+			name: "overlap from the later join to the earlier leave",
+			vs: &models.VideoSession{
+				MentorJoinedAt: at(0),
+				UserJoinedAt:   at(5 * time.Minute),
+				MentorLeftAt:   at(40 * time.Minute),
+				UserLeftAt:     at(45 * time.Minute),
+			},
+			want: 35 * time.Minute,
+		},
+		{
+			name: "open sessions are capped at the booking's end",
+			vs: &models.VideoSession{
+				MentorJoinedAt: at(0),
+				UserJoinedAt:   at(10 * time.Minute),
+			},
+			want: 50 * time.Minute,
+		},
+		{
+			name: "recorded duration wins when longer",
+			vs: &models.VideoSession{
+				MentorJoinedAt:  at(0),
+				UserJoinedAt:    at(0),
+				MentorLeftAt:    at(10 * time.Minute),
+				DurationSeconds: 30 * 60,
+			},
+			want: 30 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attendedTogether(b, tt.vs); got != tt.want {
+				t.Errorf("attendedTogether = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 type VideoSessionService struct {
 	videoSessionRepo *repositories.VideoSessionRepository
 	bookingRepo      *repositories.BookingRepository
+	serviceRepo      *repositories.MentorServiceRepository
 	mentorRepo       *repositories.MentorRepository
 	userRepo         *repositories.UserRepository
 	hub              *websocket.Hub
@@ -25,6 +26,7 @@ type VideoSessionService struct {
 func NewVideoSessionService(
 	videoSessionRepo *repositories.VideoSessionRepository,
 	bookingRepo *repositories.BookingRepository,
+	serviceRepo *repositories.MentorServiceRepository,
 	mentorRepo *repositories.MentorRepository,
 	userRepo *repositories.UserRepository,
 	hub *websocket.Hub,
@@ -32,6 +34,7 @@ func NewVideoSessionService(
 	return &VideoSessionService{
 		videoSessionRepo: videoSessionRepo,
 		bookingRepo:      bookingRepo,
+		serviceRepo:      serviceRepo,
 		mentorRepo:       mentorRepo,
 		userRepo:         userRepo,
 		hub:              hub,
@@ -73,30 +76,10 @@ func (s *VideoSessionService) ValidateAndStartSession(
 		return nil, errors.New("booking must be confirmed")
 	}
 
-	// Combine booking date with start/end times to get full datetimes
-	// BookingDate stores the calendar date, StartTime/EndTime store the time of day.
-	date := booking.BookingDate
-	start := booking.StartTime
-	end := booking.EndTime
-
-	// Interpret stored times as being in the user's timezone (e.g. IST),
-	// not in UTC. If we used date.Location() here (often UTC), then later
-	// converting to the user timezone would incorrectly shift the clock
-	// (e.g. 15:47 local becoming 21:17 IST).
-	loc, err := time.LoadLocation(timezone)
-	if err != nil {
-		return nil, errors.New("invalid timezone")
-	}
-	bookingStart := time.Date(
-		date.Year(), date.Month(), date.Day(),
-		start.Hour(), start.Minute(), start.Second(), start.Nanosecond(),
-		loc,
-	)
-	bookingEnd := time.Date(
-		date.Year(), date.Month(), date.Day(),
-		end.Hour(), end.Minute(), end.Second(), end.Nanosecond(),
-		loc,
-	)
+	// Bookings are absolute instants; the caller's timezone only
+	// affects how they are displayed
+	bookingStart := booking.StartsAt
+	bookingEnd := booking.EndsAt
 
 	// Validate timezone and time window using full datetimes
 	canJoin, msg, err := utils.ValidateSessionTime(bookingStart, bookingEnd, timezone)
@@ -115,23 +98,21 @@ func (s *VideoSessionService) ValidateAndStartSession(
 	startStr, _ := utils.FormatTimeInTimezone(bookingStart, timezone)
 	endStr, _ := utils.FormatTimeInTimezone(bookingEnd, timezone)
 
+	service, err := s.serviceRepo.FindByIDIncludingInactive(ctx, booking.ServiceID)
+	if err != nil {
+		return nil, errors.New("invalid service")
+	}
+
+	// Every seat of a group session joins the same room
+	roomID := bookingID
+	if service.IsGroup() {
+		roomID = booking.GroupRoomID()
+	}
+
 	// Check if video session already exists
 	existingSession, _ := s.videoSessionRepo.GetByBookingID(ctx, bookingID)
 
-	otherPartyJoined := false
-	otherPartyName := ""
-
-	if existingSession != nil {
-		// Session exists, check if other party joined
-		session := s.hub.GetSession(bookingID)
-		if session != nil {
-			otherParty := session.GetOtherClient(role)
-			if otherParty != nil {
-				otherPartyJoined = true
-				otherPartyName = otherParty.Username
-			}
-		}
-	} else {
+	if existingSession == nil {
 		// Create new video session
 		videoSession := &models.VideoSession{
 			ID:        uuid.New(),
@@ -146,6 +127,29 @@ func (s *VideoSessionService) ValidateAndStartSession(
 		}
 	}
 
+	otherPartyJoined := false
+	otherPartyName := ""
+	participants := 0
+
+	// Check if other party joined. Other attendees may already be in a
+	// group session's room before this seat's video session exists.
+	session := s.hub.GetSession(roomID)
+	if session != nil {
+		otherParty := session.GetOtherClient(role)
+		if otherParty != nil {
+			otherPartyJoined = true
+			otherPartyName = otherParty.Username
+		}
+
+		// For a group's mentor, any attendee counts
+		for _, c := range session.Participants() {
+			participants++
+			if session.Group && role == "mentor" && c.Role == "user" {
+				otherPartyJoined = true
+			}
+		}
+	}
+
 	return &dtos.SessionInfoResponse{
 		CanJoin:          true,
 		Message:          "",
@@ -153,6 +157,8 @@ func (s *VideoSessionService) ValidateAndStartSession(
 		EndTimeInTZ:      endStr,
 		OtherPartyJoined: otherPartyJoined,
 		OtherPartyName:   otherPartyName,
+		IsGroup:          service.IsGroup(),
+		Participants:     participants,
 	}, nil
 }
 
@@ -170,11 +176,30 @@ func (s *VideoSessionService) HandleClientJoined(
 		s.videoSessionRepo.RecordUserJoined(ctx, client.BookingID)
 	}
 
+	// A learner's session is under way once they and the mentor are both
+	// in the room; its duration is counted from then
+	session := s.hub.GetSession(client.RoomID)
+	if session == nil {
+		return nil
+	}
+
+	for _, c := range session.Participants() {
+		switch {
+		case client.Role == "mentor" && c.Role == "user":
+			s.videoSessionRepo.MarkActive(ctx, c.BookingID)
+		case client.Role == "user" && c.Role == "mentor":
+			s.videoSessionRepo.MarkActive(ctx, client.BookingID)
+		}
+	}
+
 	return nil
 }
 
 // HandleClientLeft handles when a client disconnects
-func (s *VideoSessionService) HandleClientLeft(ctx context.Context, bookingID uuid.UUID, role string) error {
+func (s *VideoSessionService) HandleClientLeft(ctx context.Context, client *websocket.Client) error {
+	bookingID := client.BookingID
+	role := client.Role
+
 	// Record in database
 	if role == "mentor" {
 		s.videoSessionRepo.RecordMentorLeft(ctx, bookingID)
@@ -183,7 +208,30 @@ func (s *VideoSessionService) HandleClientLeft(ctx context.Context, bookingID uu
 	}
 
 	// Get session
-	session := s.hub.GetSession(bookingID)
+	session := s.hub.GetSession(client.RoomID)
+
+	// A group session goes on without whoever left; an attendee's own
+	// video session ends with them unless they reconnected
+	if session != nil && session.Group {
+		if s.hub.RemoveClient(client) {
+			response := map[string]interface{}{
+				"type": "participant_left",
+				"payload": websocket.ParticipantPayload{
+					UserID:   client.UserID,
+					Username: client.Username,
+					Role:     role,
+				},
+			}
+			session.Broadcast(response)
+		}
+
+		if role != "mentor" && session.Participant(client.UserID) == nil {
+			s.EndSession(ctx, bookingID, "party_left")
+		}
+
+		return nil
+	}
+
 	if session != nil {
 		// Notify other party if present
 		otherParty := session.GetOtherClient(role)
@@ -209,7 +257,7 @@ func (s *VideoSessionService) HandleClientLeft(ctx context.Context, bookingID uu
 	}
 
 	// Remove from hub
-	s.hub.RemoveClient(bookingID, role)
+	s.hub.RemoveClient(client)
 
 	return nil
 }
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// waitlistHorizon is how far ahead opened slots are offered
+	waitlistHorizon = 14 * 24 * time.Hour
+
+	// maxOpenOffers caps the slots one entry is offered at a time
+	maxOpenOffers = 3
+
+	// waitlistReofferAfter is how long a slot a learner let their offer
+	// for expire is kept from them before it is offered again
+	waitlistReofferAfter = 24 * time.Hour
+)
+
+// ErrOfferClosed is returned for offers that were accepted, withdrawn,
+// taken by another learner or have expired
+var ErrOfferClosed = errors.New("waitlist offer is no longer open")
+
+// WaitlistService keeps learners in line for fully booked mentors. When
+// a slot opens - a booking is cancelled, rescheduled or expires unpaid,
+// a hold is released or the mentor adds availability - the mentor's line
+// is offered the matching free slots in order. The first learner to
+// accept an offer gets a slot hold and books it like any other hold.
+type WaitlistService struct {
+	waitlistRepo     *repositories.WaitlistRepository
+	mentorRepo       *repositories.MentorRepository
+	serviceRepo      *repositories.MentorServiceRepository
+	availabilityRepo *repositories.MentorAvailabilityRepository
+	bookingRepo      *repositories.BookingRepository
+	holds            repositories.SlotHoldStore
+	availability     *AvailabilityService
+
+	holdFor  time.Duration // how long an accepted offer holds its slot
+	offerFor time.Duration // how long an offer can be accepted
+	interval time.Duration
+
+	// Mentors with a slot that just opened
+	wake chan uuid.UUID
+}
+
+func NewWaitlistService(
+	waitlistRepo *repositories.WaitlistRepository,
+	mentorRepo *repositories.MentorRepository,
+	serviceRepo *repositories.MentorServiceRepository,
+	availabilityRepo *repositories.MentorAvailabilityRepository,
+	bookingRepo *repositories.BookingRepository,
+	holds repositories.SlotHoldStore,
+	availability *AvailabilityService,
+	holdFor time.Duration,
+	offerFor time.Duration,
+	interval time.Duration,
+) *WaitlistService {
+	return &WaitlistService{
+		waitlistRepo:     waitlistRepo,
+		mentorRepo:       mentorRepo,
+		serviceRepo:      serviceRepo,
+		availabilityRepo: availabilityRepo,
+		bookingRepo:      bookingRepo,
+		holds:            holds,
+		availability:     availability,
+		holdFor:          holdFor,
+		offerFor:         offerFor,
+		interval:         interval,
+		wake:             make(chan uuid.UUID, 64),
+	}
+}
+
+// Join puts the learner at the end of the mentor's line. Slots that are
+// already free and match are offered straight away.
+func (s *WaitlistService) Join(
+	userID uuid.UUID,
+	username string,
+	req *dtos.JoinWaitlistRequest,
+) (*dtos.WaitlistEntryResponse, error) {
+
+	mentor, err := s.mentorRepo.FindByUsernameRaw(username)
+	if err != nil || !mentor.IsActive {
+		return nil, errors.New("mentor not available")
+	}
+
+	if mentor.UserID == userID {
+		return nil, errors.New("mentors cannot join their own waitlist")
+	}
+
+	if req.ServiceID != nil {
+		service, err := s.serviceRepo.FindByID(*req.ServiceID)
+		if err != nil || service.MentorID != mentor.ID {
+			return nil, errors.New("invalid service")
+		}
+
+		// Seats of a group session can't be held, so they can't be offered
+		if service.IsGroup() {
+			return nil, errors.New("group sessions have no waitlist")
+		}
+	}
+
+	loc, err := loadLocation(req.Timezone, mentorLocation(mentor))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePreferredTimes(req.PreferredFrom, req.PreferredTo); err != nil {
+		return nil, err
+	}
+
+	days := req.PreferredDays
+	if days == nil {
+		days = []int64{}
+	}
+
+	entry := &models.WaitlistEntry{
+		ID:            uuid.New(),
+		MentorID:      mentor.ID,
+		UserID:        userID,
+		ServiceID:     req.ServiceID,
+		PreferredDays: days,
+		PreferredFrom: req.PreferredFrom,
+		PreferredTo:   req.PreferredTo,
+		Timezone:      loc.String(),
+		Status:        models.WaitlistStatusWaiting,
+	}
+
+	if err := s.waitlistRepo.Create(context.Background(), entry); err != nil {
+		return nil, err
+	}
+
+	s.SlotOpened(mentor.ID)
+
+	return toWaitlistEntryResponse(entry, nil, loc), nil
+}
+
+// ListMine shows the learner's entries with the offers they can accept
+func (s *WaitlistService) ListMine(
+	userID uuid.UUID,
+) ([]*dtos.WaitlistEntryResponse, error) {
+
+	ctx := context.Background()
+
+	entries, err := s.waitlistRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := s.waitlistRepo.ListOpenOffersByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byEntry := map[uuid.UUID][]*models.WaitlistOffer{}
+	for _, o := range offers {
+		byEntry[o.EntryID] = append(byEntry[o.EntryID], o)
+	}
+
+	resp := make([]*dtos.WaitlistEntryResponse, 0, len(entries))
+
+	for _, e := range entries {
+		loc, err := loadLocation(e.Timezone, time.UTC)
+		if err != nil {
+			loc = time.UTC
+		}
+
+		resp = append(resp, toWaitlistEntryResponse(e, byEntry[e.ID], loc))
+	}
+
+	return resp, nil
+}
+
+// Leave takes the learner's entry out of line
+func (s *WaitlistService) Leave(userID uuid.UUID, entryID uuid.UUID) error {
+	return s.waitlistRepo.Cancel(context.Background(), userID, entryID)
+}
+
+// AcceptOffer holds the offered slot for the learner, who then books it
+// with the hold token. Only the first learner to accept gets the slot.
+func (s *WaitlistService) AcceptOffer(
+	userID uuid.UUID,
+	offerID uuid.UUID,
+) (*dtos.SlotHoldResponse, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	offer, err := s.waitlistRepo.GetOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.waitlistRepo.GetByID(ctx, offer.EntryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	now := time.Now()
+	if offer.Status != models.WaitlistOfferOpen || !offer.ExpiresAt.After(now) {
+		return nil, ErrOfferClosed
+	}
+
+	mentor, err := s.mentorRepo.FindByID(entry.MentorID)
+	if err != nil || !mentor.IsActive {
+		return nil, errors.New("mentor not available")
+	}
+
+	// The mentor's settings and availability may have changed since the
+	// offer was made
+	if err := checkLeadTime(mentor, offer.StartsAt, now); err != nil {
+		s.waitlistRepo.MarkOfferTaken(ctx, offer.ID)
+		return nil, err
+	}
+
+	windows, err := loadWindows(ctx, s.availabilityRepo, mentor, offer.StartsAt, offer.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fitsWindows(windows, offer.StartsAt, offer.EndsAt) {
+		s.waitlistRepo.MarkOfferTaken(ctx, offer.ID)
+		return nil, errors.New("selected slot outside availability")
+	}
+
+	hold, err := placeHold(ctx, s.bookingRepo, s.mentorRepo, s.holds, mentor, offer.ServiceID, userID, offer.StartsAt, offer.EndsAt, s.holdFor)
+	if errors.Is(err, ErrSlotTaken) || errors.Is(err, repositories.ErrSlotHeld) {
+		s.waitlistRepo.MarkOfferTaken(ctx, offer.ID)
+		return nil, ErrSlotTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.waitlistRepo.AcceptOffer(ctx, offer, mentor.ID); err != nil {
+		s.holds.Release(ctx, mentor.ID, hold.Token)
+		return nil, err
+	}
+
+	return toSlotHoldResponse(hold), nil
+}
+
+// SlotOpened tells the waitlist that one of the mentor's slots may have
+// become free. It never blocks; the periodic sweep catches anything
+// dropped.
+func (s *WaitlistService) SlotOpened(mentorID uuid.UUID) {
+	select {
+	case s.wake <- mentorID:
+	default:
+	}
+}
+
+// Run offers opened slots as they are reported, and sweeps every mentor
+// with a line on every tick, until ctx is cancelled
+func (s *WaitlistService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case mentorID := <-s.wake:
+			notifyCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			_, err := s.notifyMentor(notifyCtx, mentorID)
+			cancel()
+
+			if err != nil {
+				log.Error().Err(err).Str("mentor_id", mentorID.String()).Msg("waitlist notification failed")
+			}
+		case <-ticker.C:
+			if _, err := s.NotifyOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("waitlist sweep failed")
+			}
+		}
+	}
+}
+
+// NotifyOnce expires stale offers and offers free slots to every
+// mentor's line. It returns how many offers it made.
+func (s *WaitlistService) NotifyOnce(ctx context.Context) (int, error) {
+	if _, err := s.waitlistRepo.ExpireOffers(ctx); err != nil {
+		return 0, err
+	}
+
+	mentorIDs, err := s.waitlistRepo.MentorsWaiting(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+
+	for _, mentorID := range mentorIDs {
+		notifyCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		n, err := s.notifyMentor(notifyCtx, mentorID)
+		cancel()
+
+		if err != nil {
+			log.Warn().Err(err).Str("mentor_id", mentorID.String()).Msg("waitlist not notified")
+			continue
+		}
+
+		total += n
+	}
+
+	return total, nil
+}
+
+// notifyMentor walks the mentor's line in order and offers each entry
+// the free slots of the next waitlistHorizon that match its preferences,
+// up to maxOpenOffers open offers per entry. A slot is offered to an
+// entry again only once it has come back after going to someone else, or
+// a while after the entry let the offer expire.
+func (s *WaitlistService) notifyMentor(ctx context.Context, mentorID uuid.UUID) (int, error) {
+	entries, err := s.waitlistRepo.ListWaiting(ctx, mentorID)
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+
+	mentor, err := s.mentorRepo.FindByID(mentorID)
+	if err != nil {
+		return 0, err
+	}
+	if !mentor.IsActive {
+		return 0, nil
+	}
+
+	services, err := s.serviceRepo.FindActiveByMentorID(ctx, mentorID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	free := map[uuid.UUID][]availabilityWindow{}
+
+	slotsOf := func(service *models.MentorService) ([]availabilityWindow, error) {
+		if slots, ok := free[service.ID]; ok {
+			return slots, nil
+		}
+
+		slots, err := s.availability.freeSlots(mentor, service, now, now.Add(waitlistHorizon))
+		if err != nil {
+			return nil, err
+		}
+
+		free[service.ID] = slots
+		return slots, nil
+	}
+
+	offered := 0
+
+	for _, e := range entries {
+		open, err := s.waitlistRepo.CountOpenOffers(ctx, e.ID)
+		if err != nil {
+			return offered, err
+		}
+
+		loc, err := loadLocation(e.Timezone, mentorLocation(mentor))
+		if err != nil {
+			loc = mentorLocation(mentor)
+		}
+
+		for _, service := range services {
+			if open >= maxOpenOffers {
+				break
+			}
+
+			if service.IsGroup() || (e.ServiceID != nil && *e.ServiceID != service.ID) {
+				continue
+			}
+
+			slots, err := slotsOf(service)
+			if err != nil {
+				return offered, err
+			}
+
+			for _, w := range slots {
+				if open >= maxOpenOffers {
+					break
+				}
+
+				if !matchesPreferences(e, w, loc) {
+					continue
+				}
+
+				created, err := s.waitlistRepo.CreateOffer(ctx, &models.WaitlistOffer{
+					ID:        uuid.New(),
+					EntryID:   e.ID,
+					ServiceID: service.ID,
+					StartsAt:  w.Start.UTC(),
+					EndsAt:    w.End.UTC(),
+					Status:    models.WaitlistOfferOpen,
+					ExpiresAt: now.Add(s.offerFor).UTC(),
+				}, waitlistReofferAfter)
+				if err != nil {
+					return offered, err
+				}
+
+				if created {
+					open++
+					offered++
+				}
+			}
+		}
+	}
+
+	if offered > 0 {
+		log.Info().
+			Str("mentor_id", mentorID.String()).
+			Int("offers", offered).
+			Msg("waitlist offers sent")
+	}
+
+	return offered, nil
+}
+
+// matchesPreferences tells whether the slot falls on one of the entry's
+// days and inside its preferred hours, read in loc
+func matchesPreferences(e *models.WaitlistEntry, w availabilityWindow, loc *time.Location) bool {
+	start := w.Start.In(loc)
+	end := w.End.In(loc)
+
+	if len(e.PreferredDays) > 0 {
+		found := false
+		for _, d := range e.PreferredDays {
+			if d == int64(start.Weekday()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if e.PreferredFrom != "" && start.Format("15:04") < e.PreferredFrom {
+		return false
+	}
+
+	if e.PreferredTo != "" {
+		endClock := end.Format("15:04")
+		if !dateOf(end).Equal(dateOf(start)) {
+			endClock = "24:00"
+		}
+		if endClock > e.PreferredTo {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validatePreferredTimes checks that the preferred hours are HH:MM and
+// from comes before to
+func validatePreferredTimes(from string, to string) error {
+	for _, v := range []string{from, to} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", v); err != nil || len(v) != 5 {
+			return errors.New("preferred times must be HH:MM")
+		}
+	}
+
+	if from != "" && to != "" && from >= to {
+		return errors.New("preferred_from must be before preferred_to")
+	}
+
+	return nil
+}
+
+func toWaitlistEntryResponse(
+	e *models.WaitlistEntry,
+	offers []*models.WaitlistOffer,
+	loc *time.Location,
+) *dtos.WaitlistEntryResponse {
+
+	resp := &dtos.WaitlistEntryResponse{
+		ID:            e.ID,
+		MentorID:      e.MentorID,
+		ServiceID:     e.ServiceID,
+		PreferredDays: e.PreferredDays,
+		PreferredFrom: e.PreferredFrom,
+		PreferredTo:   e.PreferredTo,
+		Timezone:      e.Timezone,
+		Status:        e.Status,
+		Position:      e.Position,
+		Offers:        make([]dtos.WaitlistOfferResponse, 0, len(offers)),
+		CreatedAt:     e.CreatedAt,
+	}
+
+	for _, o := range offers {
+		resp.Offers = append(resp.Offers, dtos.WaitlistOfferResponse{
+			ID:        o.ID,
+			ServiceID: o.ServiceID,
+			StartsAt:  o.StartsAt,
+			EndsAt:    o.EndsAt,
+			Date:      o.StartsAt.In(loc).Format("2006-01-02"),
+			StartTime: o.StartsAt.In(loc).Format("15:04"),
+			EndTime:   o.EndsAt.In(loc).Format("15:04"),
+			ExpiresAt: o.ExpiresAt,
+		})
+	}
+
+	return resp
+}
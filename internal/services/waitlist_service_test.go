@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+)
+
+func TestMatchesPreferences(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// Monday 2026-03-02, 09:00-10:00 UTC is 14:30-15:30 in Kolkata
+	slot := availabilityWindow{
+		Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+
+	// Monday 2026-03-02, 23:00 to Tuesday 00:00 UTC
+	lateSlot := availabilityWindow{
+		Start: time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name  string
+		entry models.WaitlistEntry
+		slot  availabilityWindow
+		loc   *time.Location
+		want  bool
+	}{
+		{
+			name: "no preferences",
+			slot: slot,
+			loc:  time.UTC,
+			want: true,
+		},
+		{
+			name:  "preferred day",
+			entry: models.WaitlistEntry{PreferredDays: []int64{1, 3}},
+			slot:  slot,
+			loc:   time.UTC,
+			want:  true,
+		},
+		{
+			name:  "other days only",
+			entry: models.WaitlistEntry{PreferredDays: []int64{0, 6}},
+			slot:  slot,
+			loc:   time.UTC,
+			want:  false,
+		},
+		{
+			name:  "inside preferred hours",
+			entry: models.WaitlistEntry{PreferredFrom: "09:00", PreferredTo: "10:00"},
+			slot:  slot,
+			loc:   time.UTC,
+			want:  true,
+		},
+		{
+			name:  "starts before preferred hours",
+			entry: models.WaitlistEntry{PreferredFrom: "09:30"},
+			slot:  slot,
+			loc:   time.UTC,
+			want:  false,
+		},
+		{
+			name:  "ends after preferred hours",
+			entry: models.WaitlistEntry{PreferredTo: "09:30"},
+			slot:  slot,
+			loc:   time.UTC,
+			want:  false,
+		},
+		{
+			name:  "hours are read in the entry's zone",
+			entry: models.WaitlistEntry{PreferredFrom: "14:00", PreferredTo: "16:00"},
+			slot:  slot,
+			loc:   kolkata,
+			want:  true,
+		},
+		{
+			name:  "utc hours miss in another zone",
+			entry: models.WaitlistEntry{PreferredFrom: "09:00", PreferredTo: "10:00"},
+			slot:  slot,
+			loc:   kolkata,
+			want:  false,
+		},
+		{
+			name:  "days are read in the entry's zone",
+			entry: models.WaitlistEntry{PreferredDays: []int64{2}},
+			slot:  lateSlot,
+			loc:   kolkata,
+			want:  true,
+		},
+		{
+			name:  "slot ending at midnight fits an open evening",
+			entry: models.WaitlistEntry{PreferredFrom: "18:00"},
+			slot:  lateSlot,
+			loc:   time.UTC,
+			want:  true,
+		},
+		{
+			name:  "slot ending at midnight is past a 23:30 end",
+			entry: models.WaitlistEntry{PreferredTo: "23:30"},
+			slot:  lateSlot,
+			loc:   time.UTC,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesPreferences(&tt.entry, tt.slot, tt.loc); got != tt.want {
+				t.Errorf("matchesPreferences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/preetsinghmakkar/OpenCall/internal/dtos"
+	"github.com/preetsinghmakkar/OpenCall/internal/models"
+	"github.com/preetsinghmakkar/OpenCall/internal/repositories"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// webhookBatchSize caps how many events one claim hands out
+	webhookBatchSize = 20
+
+	// An event still processing after this long is assumed abandoned
+	webhookStaleAfter = 5 * time.Minute
+
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = time.Hour
+)
+
+// ErrInvalidWebhook means the webhook failed signature verification or
+// could not be parsed
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// WebhookInbox stores every verified gateway webhook before acting on it
+// and applies stored events in the background, retrying failures with
+// exponential backoff. The webhook endpoint only has to verify and store,
+// so the gateway gets its 200 even while the database is struggling with
+// the actual work, and nothing that arrived is ever lost.
+type WebhookInbox struct {
+	webhookRepo    *repositories.WebhookEventRepository
+	paymentService *PaymentService
+
+	maxAttempts int
+	interval    time.Duration
+
+	// wake lets Receive start processing without waiting for the next tick
+	wake chan struct{}
+}
+
+func NewWebhookInbox(
+	webhookRepo *repositories.WebhookEventRepository,
+	paymentService *PaymentService,
+	maxAttempts int,
+	interval time.Duration,
+) *WebhookInbox {
+	return &WebhookInbox{
+		webhookRepo:    webhookRepo,
+		paymentService: paymentService,
+		maxAttempts:    maxAttempts,
+		interval:       interval,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+func (s *WebhookInbox) GatewayName() string {
+	return s.paymentService.GatewayName()
+}
+
+// Receive verifies a webhook and stores it for processing. Redeliveries of
+// an event already in the inbox are accepted and dropped.
+func (s *WebhookInbox) Receive(
+	ctx context.Context,
+	payload []byte,
+	header http.Header,
+) error {
+
+	event, err := s.paymentService.ParseWebhook(payload, header)
+	if err != nil {
+		return ErrInvalidWebhook
+	}
+
+	eventID := event.ID
+	if eventID == "" {
+		// Without a gateway ID, identical bodies are the same delivery
+		sum := sha256.Sum256(payload)
+		eventID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := s.webhookRepo.Insert(ctx, &models.WebhookEvent{
+		ID:        uuid.New(),
+		Gateway:   s.paymentService.GatewayName(),
+		EventID:   eventID,
+		EventType: event.Type,
+		Payload:   payload,
+		Event:     encoded,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !inserted {
+		log.Info().Str("event_id", eventID).Msg("duplicate webhook dropped")
+		return nil
+	}
+
+	s.notify()
+	return nil
+}
+
+func (s *WebhookInbox) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run processes due events on every tick, and as soon as a new event
+// arrives, until ctx is cancelled
+func (s *WebhookInbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		if _, err := s.ProcessOnce(ctx); err != nil {
+			log.Error().Err(err).Msg("webhook processing failed")
+		}
+	}
+}
+
+// ProcessOnce applies due events until none are left and returns how
+// many it handled
+func (s *WebhookInbox) ProcessOnce(ctx context.Context) (int, error) {
+	total := 0
+
+	for {
+		events, err := s.webhookRepo.ClaimDue(ctx, webhookBatchSize, webhookStaleAfter)
+		if err != nil {
+			return total, err
+		}
+
+		for _, e := range events {
+			if err := s.process(ctx, e); err != nil {
+				return total, err
+			}
+		}
+
+		total += len(events)
+
+		if len(events) < webhookBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// process applies one claimed event and records the outcome. Only a
+// failure to record the outcome is returned.
+func (s *WebhookInbox) process(ctx context.Context, e *models.WebhookEvent) error {
+	var event GatewayEvent
+
+	err := json.Unmarshal(e.Event, &event)
+	if err == nil {
+		err = s.paymentService.HandleEvent(&event)
+	}
+
+	if err == nil {
+		return s.webhookRepo.MarkProcessed(ctx, e.ID)
+	}
+
+	logger := log.Error().
+		Err(err).
+		Str("webhook_event_id", e.ID.String()).
+		Str("event_type", e.EventType).
+		Int("attempt", e.Attempts)
+
+	if e.Attempts >= s.maxAttempts {
+		logger.Msg("webhook event failed permanently")
+		return s.webhookRepo.MarkFailed(ctx, e.ID, err.Error())
+	}
+
+	logger.Msg("webhook event failed, will retry")
+	return s.webhookRepo.MarkRetry(ctx, e.ID, time.Now().Add(webhookBackoff(e.Attempts)), err.Error())
+}
+
+// webhookBackoff doubles the wait after every attempt, up to an hour
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, webhookMaxBackoff)
+}
+
+// List returns recent inbox events, optionally only those with `status`
+func (s *WebhookInbox) List(
+	ctx context.Context,
+	status string,
+	limit int,
+) ([]dtos.WebhookEventResponse, error) {
+
+	switch models.WebhookEventStatus(status) {
+	case "",
+		models.WebhookEventStatusPending,
+		models.WebhookEventStatusProcessing,
+		models.WebhookEventStatusProcessed,
+		models.WebhookEventStatusFailed:
+	default:
+		return nil, errors.New("invalid status")
+	}
+
+	events, err := s.webhookRepo.List(ctx, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dtos.WebhookEventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, toWebhookEventResponse(e))
+	}
+
+	return resp, nil
+}
+
+// Replay queues a failed event for processing again
+func (s *WebhookInbox) Replay(
+	ctx context.Context,
+	id uuid.UUID,
+) (*dtos.WebhookEventResponse, error) {
+
+	e, err := s.webhookRepo.Replay(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+
+	resp := toWebhookEventResponse(e)
+	return &resp, nil
+}
+
+func toWebhookEventResponse(e *models.WebhookEvent) dtos.WebhookEventResponse {
+	return dtos.WebhookEventResponse{
+		ID:            e.ID,
+		Gateway:       e.Gateway,
+		EventID:       e.EventID,
+		EventType:     e.EventType,
+		Status:        string(e.Status),
+		Attempts:      e.Attempts,
+		LastError:     e.LastError,
+		NextAttemptAt: e.NextAttemptAt,
+		ReceivedAt:    e.ReceivedAt,
+		ProcessedAt:   e.ProcessedAt,
+		Payload:       string(e.Payload),
+	}
+}
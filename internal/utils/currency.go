@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// currencyExponents maps active ISO-4217 codes to their number of minor
+// units (decimal places). Amounts are always stored in minor units, so
+// 1000 means ₹10.00 in INR, ¥1000 in JPY and 1.000 KD in KWD.
+var currencyExponents = map[string]int{
+	// zero-decimal
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+
+	// three-decimal
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+
+	// two-decimal
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2,
+	"AUD": 2, "AWG": 2, "AZN": 2, "BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2,
+	"BMD": 2, "BND": 2, "BOB": 2, "BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2,
+	"BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHF": 2, "CNY": 2, "COP": 2,
+	"CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2, "DKK": 2, "DOP": 2, "DZD": 2,
+	"EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2, "GBP": 2,
+	"GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GTQ": 2, "GYD": 2, "HKD": 2,
+	"HNL": 2, "HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IRR": 2,
+	"JMD": 2, "KES": 2, "KGS": 2, "KHR": 2, "KPW": 2, "KYD": 2, "KZT": 2,
+	"LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "MAD": 2, "MDL": 2,
+	"MGA": 2, "MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2,
+	"MVR": 2, "MWK": 2, "MXN": 2, "MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2,
+	"NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "PAB": 2, "PEN": 2, "PGK": 2,
+	"PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2,
+	"SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2,
+	"SLE": 2, "SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2,
+	"SZL": 2, "THB": 2, "TJS": 2, "TMT": 2, "TOP": 2, "TRY": 2, "TTD": 2,
+	"TWD": 2, "TZS": 2, "UAH": 2, "USD": 2, "UYU": 2, "UZS": 2, "VES": 2,
+	"WST": 2, "XCD": 2, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWL": 2,
+}
+
+var currencySymbols = map[string]string{
+	"AUD": "A$",
+	"CAD": "CA$",
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+	"JPY": "¥",
+	"SGD": "S$",
+	"USD": "$",
+}
+
+// CurrencyExponent returns the number of minor units of an ISO-4217 code
+func CurrencyExponent(currency string) (int, bool) {
+	exp, ok := currencyExponents[strings.ToUpper(currency)]
+	return exp, ok
+}
+
+// ValidateCurrency checks that the code is a known ISO-4217 currency
+func ValidateCurrency(currency string) error {
+	if _, ok := CurrencyExponent(currency); !ok {
+		return errors.New("unsupported currency " + currency)
+	}
+	return nil
+}
+
+// FormatMoney renders an amount in minor units, e.g. 149900 INR -> "₹1,499.00",
+// 1500 JPY -> "¥1,500", 12500 KWD -> "KWD 12.500"
+func FormatMoney(amount int64, currency string) string {
+	currency = strings.ToUpper(currency)
+
+	if _, ok := currencyExponents[currency]; !ok {
+		return fmt.Sprintf("%d %s", amount, currency)
+	}
+
+	sign, number := formatMinorUnits(amount, currency)
+
+	if symbol, ok := currencySymbols[currency]; ok {
+		return sign + symbol + number
+	}
+
+	return sign + currency + " " + number
+}
+
+// FormatMoneyCode is FormatMoney with the ISO code instead of a symbol,
+// e.g. 149900 INR -> "INR 1,499.00", for output that can't show every
+// currency symbol (such as PDF receipts)
+func FormatMoneyCode(amount int64, currency string) string {
+	currency = strings.ToUpper(currency)
+
+	if _, ok := currencyExponents[currency]; !ok {
+		return fmt.Sprintf("%d %s", amount, currency)
+	}
+
+	sign, number := formatMinorUnits(amount, currency)
+
+	return sign + currency + " " + number
+}
+
+// formatMinorUnits splits an amount into its sign and its grouped decimal
+// digits, e.g. -149900 INR -> ("-", "1,499.00")
+func formatMinorUnits(amount int64, currency string) (string, string) {
+	exp := currencyExponents[currency]
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	divisor := int64(1)
+	for i := 0; i < exp; i++ {
+		divisor *= 10
+	}
+
+	number := groupThousands(amount / divisor)
+	if exp > 0 {
+		number += fmt.Sprintf(".%0*d", exp, amount%divisor)
+	}
+
+	return sign, number
+}
+
+func groupThousands(n int64) string {
+	s := fmt.Sprintf("%d", n)
+
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ICalendar is a minimal RFC 5545 writer for VCALENDAR objects holding
+// VEVENTs, enough for calendar feeds and invites
+type ICalendar struct {
+	Name   string // X-WR-CALNAME, shown by subscribing clients
+	Method string // PUBLISH, REQUEST or CANCEL; empty for feeds
+	Events []ICalEvent
+}
+
+type ICalEvent struct {
+	UID         string
+	Sequence    int
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Status      string // CONFIRMED or CANCELLED
+
+	// Optional, required by REQUEST and CANCEL invites
+	OrganizerName  string
+	OrganizerEmail string
+	AttendeeName   string
+	AttendeeEmail  string
+}
+
+const (
+	ICalMethodPublish = "PUBLISH"
+	ICalMethodRequest = "REQUEST"
+	ICalMethodCancel  = "CANCEL"
+
+	ICalStatusConfirmed = "CONFIRMED"
+	ICalStatusCancelled = "CANCELLED"
+)
+
+// Bytes renders the calendar with CRLF line endings and folded lines
+func (c *ICalendar) Bytes() []byte {
+	var b bytes.Buffer
+
+	line := func(name, value string) {
+		writeICalLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//OpenCall//Bookings//EN")
+	line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		line("METHOD", c.Method)
+	}
+	if c.Name != "" {
+		line("X-WR-CALNAME", icalText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		line("DTSTAMP", icalTime(e.Stamp))
+		line("DTSTART", icalTime(e.Start))
+		line("DTEND", icalTime(e.End))
+		line("SUMMARY", icalText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", icalText(e.Description))
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		if e.OrganizerEmail != "" {
+			writeICalLine(&b, fmt.Sprintf("ORGANIZER;CN=%s:mailto:%s", icalParam(e.OrganizerName), e.OrganizerEmail))
+		}
+		if e.AttendeeEmail != "" {
+			writeICalLine(&b, fmt.Sprintf(
+				"ATTENDEE;CN=%s;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:%s",
+				icalParam(e.AttendeeName), e.AttendeeEmail,
+			))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	return b.Bytes()
+}
+
+func icalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icalText escapes a TEXT value
+func icalText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// icalParam quotes a parameter value; quotes can't be escaped, so they
+// are dropped
+func icalParam(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "") + `"`
+}
+
+// writeICalLine folds content lines longer than 75 octets without
+// splitting UTF-8 characters
+func writeICalLine(b *bytes.Buffer, s string) {
+	limit := 75
+
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+
+		// continuation lines start with the folding space
+		limit = 74
+	}
+
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,612 @@
+package utils
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICalBusy is a span of time an imported calendar marks as busy
+type ICalBusy struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Recurrences are expanded lazily, but a rule without an end could still
+// loop for a very long time; past these the rest of a series is dropped
+const (
+	icalMaxPeriods   = 20000
+	icalMaxInstances = 5000
+)
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// icalProp is one content line: NAME;PARAM=x:VALUE
+type icalProp struct {
+	Params map[string]string
+	Value  string
+}
+
+type icalEvent struct {
+	props map[string][]icalProp
+}
+
+func (e *icalEvent) first(name string) *icalProp {
+	if p := e.props[name]; len(p) > 0 {
+		return &p[0]
+	}
+	return nil
+}
+
+// ParseICalBusy reads the VEVENTs of an iCalendar file and returns the
+// busy time they cover within [from, to), recurrences included. Floating
+// and date-only times are read in defaultLoc. Cancelled events and
+// events marked TRANSP:TRANSPARENT (shown as free) are skipped.
+func ParseICalBusy(data []byte, defaultLoc *time.Location, from, to time.Time) ([]ICalBusy, error) {
+	events, calLoc, err := parseICalEvents(string(data), defaultLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Modified instances of a series replace the instance they were
+	// moved from
+	moved := map[string][]time.Time{}
+	for _, e := range events {
+		rid := e.first("RECURRENCE-ID")
+		uid := e.first("UID")
+		if rid == nil || uid == nil {
+			continue
+		}
+		if t, _, err := parseICalTime(*rid, calLoc); err == nil {
+			moved[uid.Value] = append(moved[uid.Value], t)
+		}
+	}
+
+	var busy []ICalBusy
+
+	for _, e := range events {
+		if p := e.first("STATUS"); p != nil && strings.EqualFold(p.Value, "CANCELLED") {
+			continue
+		}
+		if p := e.first("TRANSP"); p != nil && strings.EqualFold(p.Value, "TRANSPARENT") {
+			continue
+		}
+
+		dtstart := e.first("DTSTART")
+		if dtstart == nil {
+			continue
+		}
+
+		start, allDay, err := parseICalTime(*dtstart, calLoc)
+		if err != nil {
+			continue
+		}
+
+		length, err := eventLength(e, start, allDay, calLoc)
+		if err != nil || length <= 0 {
+			continue
+		}
+
+		var skip []time.Time
+		for _, p := range e.props["EXDATE"] {
+			skip = append(skip, parseICalTimeList(p, calLoc)...)
+		}
+		if uid := e.first("UID"); uid != nil && e.first("RECURRENCE-ID") == nil {
+			skip = append(skip, moved[uid.Value]...)
+		}
+
+		starts := []time.Time{start}
+		if rrule := e.first("RRULE"); rrule != nil && e.first("RECURRENCE-ID") == nil {
+			// Instances can start before `from` and still run into it
+			starts = expandRRule(rrule.Value, start, calLoc, from.Add(-length), to)
+		}
+		for _, p := range e.props["RDATE"] {
+			starts = append(starts, parseICalTimeList(p, calLoc)...)
+		}
+
+		for _, s := range starts {
+			if containsTime(skip, s) {
+				continue
+			}
+
+			end := s.Add(length)
+			if allDay {
+				// Whole days stay whole days across DST changes
+				days := int((length + 12*time.Hour) / (24 * time.Hour))
+				end = time.Date(s.Year(), s.Month(), s.Day()+days, 0, 0, 0, 0, s.Location())
+			}
+
+			if s.Before(to) && end.After(from) {
+				busy = append(busy, ICalBusy{Start: s, End: end})
+			}
+		}
+	}
+
+	sort.Slice(busy, func(i, j int) bool {
+		return busy[i].Start.Before(busy[j].Start)
+	})
+
+	return busy, nil
+}
+
+// parseICalEvents splits the file into VEVENTs and returns the zone
+// floating times are read in (X-WR-TIMEZONE when the calendar sets one)
+func parseICalEvents(data string, defaultLoc *time.Location) ([]*icalEvent, *time.Location, error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\n ", "")
+	data = strings.ReplaceAll(data, "\n\t", "")
+
+	if !strings.Contains(strings.ToUpper(data), "BEGIN:VCALENDAR") {
+		return nil, nil, errors.New("not an iCalendar file")
+	}
+
+	loc := defaultLoc
+
+	var (
+		events  []*icalEvent
+		current *icalEvent
+		depth   int // nesting inside the current VEVENT (e.g. VALARM)
+	)
+
+	for _, line := range strings.Split(data, "\n") {
+		name, prop, ok := parseICalLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT") && current == nil:
+			current = &icalEvent{props: map[string][]icalProp{}}
+			depth = 0
+		case name == "BEGIN" && current != nil:
+			depth++
+		case name == "END" && current != nil && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(prop.Value, "VEVENT") && current != nil:
+			events = append(events, current)
+			current = nil
+		case current != nil && depth == 0:
+			current.props[name] = append(current.props[name], prop)
+		case current == nil && name == "X-WR-TIMEZONE":
+			if l, err := time.LoadLocation(prop.Value); err == nil {
+				loc = l
+			}
+		}
+	}
+
+	return events, loc, nil
+}
+
+func parseICalLine(line string) (string, icalProp, bool) {
+	line = strings.TrimRight(line, "\r")
+
+	// The value starts at the first colon outside a quoted parameter
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", icalProp{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := icalProp{Params: map[string]string{}, Value: line[colon+1:]}
+
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			prop.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), prop, true
+}
+
+// parseICalTime reads a DATE or DATE-TIME property. Date-only values are
+// midnight in loc and reported as all-day.
+func parseICalTime(p icalProp, loc *time.Location) (time.Time, bool, error) {
+	return parseICalValue(p.Value, p.Params, loc)
+}
+
+func parseICalValue(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	switch {
+	case len(value) == 8 || strings.EqualFold(params["VALUE"], "DATE"):
+		t, err := time.ParseInLocation("20060102", value[:min(8, len(value))], loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+func parseICalTimeList(p icalProp, loc *time.Location) []time.Time {
+	var times []time.Time
+	for _, v := range strings.Split(p.Value, ",") {
+		if t, _, err := parseICalValue(v, p.Params, loc); err == nil {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, x := range times {
+		if x.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventLength comes from DTEND or DURATION; without either an all-day
+// event lasts a day and a timed one is a point in time
+func eventLength(e *icalEvent, start time.Time, allDay bool, loc *time.Location) (time.Duration, error) {
+	if p := e.first("DTEND"); p != nil {
+		end, _, err := parseICalTime(*p, loc)
+		if err != nil {
+			return 0, err
+		}
+		return end.Sub(start), nil
+	}
+
+	if p := e.first("DURATION"); p != nil {
+		return parseICalDuration(p.Value)
+	}
+
+	if allDay {
+		return 24 * time.Hour, nil
+	}
+
+	return 0, nil
+}
+
+// parseICalDuration reads durations like PT1H30M, P1D or P2W
+func parseICalDuration(s string) (time.Duration, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "+")
+	if !strings.HasPrefix(s, "P") {
+		return 0, errors.New("invalid duration")
+	}
+
+	var (
+		total time.Duration
+		num   string
+	)
+
+	for _, r := range s[1:] {
+		if r >= '0' && r <= '9' {
+			num += string(r)
+			continue
+		}
+
+		if r == 'T' {
+			continue
+		}
+
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, errors.New("invalid duration")
+		}
+		num = ""
+
+		switch r {
+		case 'W':
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case 'D':
+			total += time.Duration(n) * 24 * time.Hour
+		case 'H':
+			total += time.Duration(n) * time.Hour
+		case 'M':
+			total += time.Duration(n) * time.Minute
+		case 'S':
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, errors.New("invalid duration")
+		}
+	}
+
+	return total, nil
+}
+
+// icalRule is the subset of RRULE we expand: FREQ, INTERVAL, COUNT,
+// UNTIL, BYDAY (with ordinals for monthly and yearly rules), BYMONTHDAY
+// and BYMONTH
+type icalRule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []icalByDay
+	byMonthDay []int
+	byMonth    []time.Month
+}
+
+type icalByDay struct {
+	ordinal int // 0 = every such weekday in the period
+	weekday time.Weekday
+}
+
+func parseRRule(value string, loc *time.Location) (*icalRule, error) {
+	r := &icalRule{interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				r.interval = n
+			}
+		case "COUNT":
+			if n, err := strconv.Atoi(v); err == nil {
+				r.count = n
+			}
+		case "UNTIL":
+			if t, allDay, err := parseICalValue(v, nil, loc); err == nil {
+				if allDay {
+					// A date-only UNTIL includes that whole day
+					t = t.AddDate(0, 0, 1).Add(-time.Second)
+				}
+				r.until = t
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(strings.ToUpper(v), ",") {
+				if len(d) < 2 {
+					continue
+				}
+				wd, ok := icalWeekdays[d[len(d)-2:]]
+				if !ok {
+					continue
+				}
+				ordinal, _ := strconv.Atoi(strings.TrimPrefix(d[:len(d)-2], "+"))
+				r.byDay = append(r.byDay, icalByDay{ordinal: ordinal, weekday: wd})
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(v, ",") {
+				if n, err := strconv.Atoi(d); err == nil && n != 0 {
+					r.byMonthDay = append(r.byMonthDay, n)
+				}
+			}
+		case "BYMONTH":
+			for _, m := range strings.Split(v, ",") {
+				if n, err := strconv.Atoi(m); err == nil && n >= 1 && n <= 12 {
+					r.byMonth = append(r.byMonth, time.Month(n))
+				}
+			}
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+		return r, nil
+	}
+
+	return nil, errors.New("unsupported recurrence")
+}
+
+// expandRRule lists the starts of a series, from dtstart on, that begin
+// before `to` and not before `from`. Instances keep dtstart's wall-clock
+// time in its zone, so a 09:00 meeting stays at 09:00 across DST. An
+// unsupported rule yields just dtstart.
+func expandRRule(value string, dtstart time.Time, loc *time.Location, from, to time.Time) []time.Time {
+	rule, err := parseRRule(value, loc)
+	if err != nil {
+		return []time.Time{dtstart}
+	}
+
+	var (
+		starts []time.Time
+		seen   int
+	)
+
+	for period := 0; period < icalMaxPeriods; period++ {
+		days := rule.periodDays(dtstart, period)
+		if len(days) == 0 && rule.periodStart(dtstart, period).After(to) {
+			break
+		}
+
+		for _, day := range days {
+			s := time.Date(
+				day.Year(), day.Month(), day.Day(),
+				dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0,
+				dtstart.Location(),
+			)
+
+			if s.Before(dtstart) {
+				continue
+			}
+
+			if (!rule.until.IsZero() && s.After(rule.until)) || !s.Before(to) {
+				return starts
+			}
+
+			seen++
+			if rule.count > 0 && seen > rule.count {
+				return starts
+			}
+
+			if !s.Before(from) {
+				starts = append(starts, s)
+				if len(starts) >= icalMaxInstances {
+					return starts
+				}
+			}
+		}
+	}
+
+	return starts
+}
+
+// periodStart is the first day of the n-th period of the rule
+func (r *icalRule) periodStart(dtstart time.Time, n int) time.Time {
+	y, m, d := dtstart.Date()
+	step := n * r.interval
+
+	switch r.freq {
+	case "DAILY":
+		return time.Date(y, m, d+step, 0, 0, 0, 0, time.UTC)
+	case "WEEKLY":
+		// Weeks start on Monday
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset+7*step, 0, 0, 0, 0, time.UTC)
+	case "MONTHLY":
+		return time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y+step, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// periodDays lists, in order, the days of the n-th period the rule
+// selects
+func (r *icalRule) periodDays(dtstart time.Time, n int) []time.Time {
+	start := r.periodStart(dtstart, n)
+
+	switch r.freq {
+	case "DAILY":
+		if r.matchesDay(start) {
+			return []time.Time{start}
+		}
+		return nil
+
+	case "WEEKLY":
+		var days []time.Time
+		for i := 0; i < 7; i++ {
+			day := start.AddDate(0, 0, i)
+			if len(r.byDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if r.matchesDay(day) {
+				days = append(days, day)
+			}
+		}
+		return days
+
+	case "MONTHLY":
+		return r.monthDays(start, dtstart)
+
+	default:
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []time.Month{dtstart.Month()}
+		}
+
+		var days []time.Time
+		for _, m := range months {
+			days = append(days, r.monthDays(time.Date(start.Year(), m, 1, 0, 0, 0, 0, time.UTC), dtstart)...)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+		return days
+	}
+}
+
+// monthDays selects days of the month starting at `first` by BYMONTHDAY
+// or BYDAY, defaulting to dtstart's day of the month
+func (r *icalRule) monthDays(first time.Time, dtstart time.Time) []time.Time {
+	if len(r.byMonth) > 0 && !containsMonth(r.byMonth, first.Month()) {
+		return nil
+	}
+
+	last := first.AddDate(0, 1, -1).Day()
+	picked := map[int]bool{}
+
+	for _, d := range r.byMonthDay {
+		if d < 0 {
+			d = last + 1 + d
+		}
+		if d >= 1 && d <= last {
+			picked[d] = true
+		}
+	}
+
+	for _, bd := range r.byDay {
+		var matches []int
+		for d := 1; d <= last; d++ {
+			if time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, time.UTC).Weekday() == bd.weekday {
+				matches = append(matches, d)
+			}
+		}
+
+		switch {
+		case bd.ordinal == 0:
+			for _, d := range matches {
+				picked[d] = true
+			}
+		case bd.ordinal > 0 && bd.ordinal <= len(matches):
+			picked[matches[bd.ordinal-1]] = true
+		case bd.ordinal < 0 && -bd.ordinal <= len(matches):
+			picked[matches[len(matches)+bd.ordinal]] = true
+		}
+	}
+
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 && dtstart.Day() <= last {
+		picked[dtstart.Day()] = true
+	}
+
+	var days []time.Time
+	for d := 1; d <= last; d++ {
+		if picked[d] {
+			days = append(days, time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, time.UTC))
+		}
+	}
+
+	return days
+}
+
+// matchesDay applies BYDAY and BYMONTH as filters for daily and weekly rules
+func (r *icalRule) matchesDay(day time.Time) bool {
+	if len(r.byMonth) > 0 && !containsMonth(r.byMonth, day.Month()) {
+		return false
+	}
+
+	if len(r.byDay) == 0 {
+		return true
+	}
+
+	for _, bd := range r.byDay {
+		if bd.weekday == day.Weekday() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsMonth(months []time.Month, m time.Month) bool {
+	for _, x := range months {
+		if x == m {
+			return true
+		}
+	}
+	return false
+}
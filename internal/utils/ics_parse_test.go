@@ -0,0 +1,276 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func icalFile(lines ...string) []byte {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...)
+	all = append(all, "END:VCALENDAR")
+	return []byte(strings.Join(all, "\r\n") + "\r\n")
+}
+
+func icalEventLines(props ...string) []string {
+	lines := append([]string{"BEGIN:VEVENT"}, props...)
+	return append(lines, "END:VEVENT")
+}
+
+func TestParseICalBusy(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	utc := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatalf("bad test time %q: %v", s, err)
+		}
+		return v
+	}
+
+	// Week of Monday 2026-03-02 unless a case says otherwise
+	from := utc("2026-03-02 00:00")
+	to := utc("2026-03-09 00:00")
+
+	tests := []struct {
+		name     string
+		data     []byte
+		loc      *time.Location
+		from, to time.Time
+		want     [][2]string // UTC start and end
+	}{
+		{
+			name: "single utc event",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260303T100000Z",
+				"DTEND:20260303T110000Z",
+			)...),
+			want: [][2]string{{"2026-03-03 10:00", "2026-03-03 11:00"}},
+		},
+		{
+			name: "outside the window",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260310T100000Z",
+				"DTEND:20260310T110000Z",
+			)...),
+			want: nil,
+		},
+		{
+			name: "event running into the window",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260301T230000Z",
+				"DTEND:20260302T010000Z",
+			)...),
+			want: [][2]string{{"2026-03-01 23:00", "2026-03-02 01:00"}},
+		},
+		{
+			name: "cancelled and transparent events are skipped",
+			data: icalFile(append(
+				icalEventLines(
+					"UID:a",
+					"STATUS:CANCELLED",
+					"DTSTART:20260303T100000Z",
+					"DTEND:20260303T110000Z",
+				),
+				icalEventLines(
+					"UID:b",
+					"TRANSP:TRANSPARENT",
+					"DTSTART:20260304T100000Z",
+					"DTEND:20260304T110000Z",
+				)...,
+			)...),
+			want: nil,
+		},
+		{
+			name: "tzid",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART;TZID=America/New_York:20260303T090000",
+				"DTEND;TZID=America/New_York:20260303T093000",
+			)...),
+			want: [][2]string{{"2026-03-03 14:00", "2026-03-03 14:30"}},
+		},
+		{
+			name: "floating time uses the default zone",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260303T090000",
+				"DURATION:PT45M",
+			)...),
+			loc:  newYork,
+			want: [][2]string{{"2026-03-03 14:00", "2026-03-03 14:45"}},
+		},
+		{
+			name: "calendar zone overrides the default",
+			data: icalFile(append(
+				[]string{"X-WR-TIMEZONE:America/New_York"},
+				icalEventLines(
+					"UID:a",
+					"DTSTART:20260303T090000",
+					"DTEND:20260303T100000",
+				)...,
+			)...),
+			want: [][2]string{{"2026-03-03 14:00", "2026-03-03 15:00"}},
+		},
+		{
+			name: "all-day event without an end lasts a day",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART;VALUE=DATE:20260304",
+			)...),
+			want: [][2]string{{"2026-03-04 00:00", "2026-03-05 00:00"}},
+		},
+		{
+			name: "folded lines",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260303T1000",
+				" 00Z",
+				"DTEND:20260303T110000Z",
+			)...),
+			want: [][2]string{{"2026-03-03 10:00", "2026-03-03 11:00"}},
+		},
+		{
+			name: "weekly rule with count and byday",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260302T100000Z",
+				"DTEND:20260302T110000Z",
+				"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3",
+			)...),
+			from: from,
+			to:   utc("2026-03-31 00:00"),
+			want: [][2]string{
+				{"2026-03-02 10:00", "2026-03-02 11:00"},
+				{"2026-03-04 10:00", "2026-03-04 11:00"},
+				{"2026-03-09 10:00", "2026-03-09 11:00"},
+			},
+		},
+		{
+			name: "daily rule with an exdate and a moved instance",
+			data: icalFile(append(
+				icalEventLines(
+					"UID:a",
+					"DTSTART:20260302T100000Z",
+					"DTEND:20260302T110000Z",
+					"RRULE:FREQ=DAILY;UNTIL=20260305T235959Z",
+					"EXDATE:20260303T100000Z",
+				),
+				icalEventLines(
+					"UID:a",
+					"RECURRENCE-ID:20260304T100000Z",
+					"DTSTART:20260304T150000Z",
+					"DTEND:20260304T160000Z",
+				)...,
+			)...),
+			want: [][2]string{
+				{"2026-03-02 10:00", "2026-03-02 11:00"},
+				{"2026-03-04 15:00", "2026-03-04 16:00"},
+				{"2026-03-05 10:00", "2026-03-05 11:00"},
+			},
+		},
+		{
+			name: "rule started before the window",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260108T100000Z",
+				"DTEND:20260108T110000Z",
+				"RRULE:FREQ=WEEKLY;INTERVAL=2",
+			)...),
+			want: [][2]string{{"2026-03-05 10:00", "2026-03-05 11:00"}},
+		},
+		{
+			name: "recurrences keep their wall-clock time across dst",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART;TZID=America/New_York:20260306T090000",
+				"DTEND;TZID=America/New_York:20260306T100000",
+				"RRULE:FREQ=DAILY;COUNT=4",
+			)...),
+			from: utc("2026-03-06 00:00"),
+			to:   utc("2026-03-12 00:00"),
+			want: [][2]string{
+				{"2026-03-06 14:00", "2026-03-06 15:00"},
+				{"2026-03-07 14:00", "2026-03-07 15:00"},
+				{"2026-03-08 13:00", "2026-03-08 14:00"},
+				{"2026-03-09 13:00", "2026-03-09 14:00"},
+			},
+		},
+		{
+			name: "monthly rule on the last friday",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260130T100000Z",
+				"DURATION:PT1H",
+				"RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+			)...),
+			from: utc("2026-02-01 00:00"),
+			to:   utc("2026-05-01 00:00"),
+			want: [][2]string{
+				{"2026-02-27 10:00", "2026-02-27 11:00"},
+				{"2026-03-27 10:00", "2026-03-27 11:00"},
+				{"2026-04-24 10:00", "2026-04-24 11:00"},
+			},
+		},
+		{
+			name: "alarms inside an event are ignored",
+			data: icalFile(icalEventLines(
+				"UID:a",
+				"DTSTART:20260303T100000Z",
+				"DTEND:20260303T110000Z",
+				"BEGIN:VALARM",
+				"TRIGGER:-PT15M",
+				"DTSTART:20260303T000000Z",
+				"END:VALARM",
+			)...),
+			want: [][2]string{{"2026-03-03 10:00", "2026-03-03 11:00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+			winFrom, winTo := tt.from, tt.to
+			if winFrom.IsZero() {
+				winFrom, winTo = from, to
+			}
+
+			busy, err := ParseICalBusy(tt.data, loc, winFrom, winTo)
+			if err != nil {
+				t.Fatalf("ParseICalBusy: %v", err)
+			}
+
+			if len(busy) != len(tt.want) {
+				t.Fatalf("got %d spans %v, want %d", len(busy), busy, len(tt.want))
+			}
+
+			for i, w := range tt.want {
+				if !busy[i].Start.Equal(utc(w[0])) || !busy[i].End.Equal(utc(w[1])) {
+					t.Errorf(
+						"span %d = %s - %s, want %s - %s",
+						i,
+						busy[i].Start.UTC().Format("2006-01-02 15:04"),
+						busy[i].End.UTC().Format("2006-01-02 15:04"),
+						w[0], w[1],
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestParseICalBusyRejectsNonCalendars(t *testing.T) {
+	_, err := ParseICalBusy([]byte("<html></html>"), time.UTC, time.Now(), time.Now().Add(time.Hour))
+	if err == nil {
+		t.Fatal("expected an error for a file that isn't iCalendar")
+	}
+}
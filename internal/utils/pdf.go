@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDFPage is a minimal single-page PDF writer. It only knows the
+// standard Helvetica fonts (which every viewer ships, so nothing is
+// embedded), text and straight lines - enough for receipts, with no
+// external dependencies. Coordinates are in points from the bottom-left
+// corner of an A4 page.
+type PDFPage struct {
+	content bytes.Buffer
+}
+
+const (
+	PDFPageWidth  = 595.28
+	PDFPageHeight = 841.89
+)
+
+func NewPDFPage() *PDFPage {
+	return &PDFPage{}
+}
+
+// Text draws s at (x, y). Characters outside Latin-1 are replaced with '?'.
+func (p *PDFPage) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// TextRight draws s so that it ends at x. Widths are approximated, which
+// is good enough to right-align columns of digits.
+func (p *PDFPage) TextRight(x, y, size float64, bold bool, s string) {
+	p.Text(x-approxTextWidth(s, size), y, size, bold, s)
+}
+
+// Line draws a 0.5pt line from (x1, y1) to (x2, y2)
+func (p *PDFPage) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// Bytes renders the complete PDF file
+func (p *PDFPage) Bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+				"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>",
+			PDFPageWidth, PDFPageHeight,
+		),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsi (~Latin-1) encoding
+func pdfString(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '€':
+			b.WriteString(`\200`)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	return b.String()
+}
+
+func approxTextWidth(s string, size float64) float64 {
+	// Helvetica averages a little over half an em per character
+	return float64(len([]rune(s))) * size * 0.55
+}
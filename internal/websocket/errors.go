@@ -6,4 +6,6 @@ var (
 	ErrMessageBufferFull = errors.New("message buffer is full")
 	ErrSessionNotFound   = errors.New("session not found")
 	ErrClientNotFound    = errors.New("client not found")
+	ErrRoomFull          = errors.New("every seat in this session is taken")
+	ErrRemovedFromRoom   = errors.New("removed from this session by the mentor")
 )
@@ -13,7 +13,8 @@ import (
 type Client struct {
 	ID              uuid.UUID
 	BookingID       uuid.UUID
-	Role            string // "mentor" or "user" - derived from booking ownership
+	RoomID          uuid.UUID // the booking ID, or the room shared by a group session
+	Role            string    // "mentor" or "user" - derived from booking ownership
 	UserID          uuid.UUID
 	MentorID        uuid.UUID // For reference
 	Username        string
@@ -22,23 +23,32 @@ type Client struct {
 	Send            chan interface{}
 	Done            chan struct{}
 	ConnectionState *ConnectionState // Tracks signaling state
+
+	closeOnce sync.Once
 }
 
 // Hub manages all active WebSocket connections for a booking
 type Hub struct {
 	mu       sync.RWMutex
-	sessions map[uuid.UUID]*Session // key: booking_id
+	sessions map[uuid.UUID]*Session // key: room ID
 }
 
-// Session represents an active video session with both parties
+// Session represents an active video session with both parties.
+// Group sessions have the mentor and any number of attendees instead.
 type Session struct {
-	BookingID   uuid.UUID
+	BookingID   uuid.UUID // the room ID for group sessions
 	Mentor      *Client
 	User        *Client
 	StartTime   time.Time
 	MaxDuration int // seconds
 	Done        chan struct{}
 	mu          sync.RWMutex
+
+	Group     bool
+	Capacity  int
+	Topology  string                // TopologyFanOut or TopologyMesh
+	Attendees map[uuid.UUID]*Client // key: user ID
+	removed   map[uuid.UUID]bool    // attendees the mentor removed
 }
 
 // NewHub creates a new WebSocket hub
@@ -88,6 +98,53 @@ func (h *Hub) AddClient(bookingID uuid.UUID, client *Client) *Session {
 	return session
 }
 
+// AddGroupClient adds a client to the room of a group session, creating
+// it in fan-out topology if needed. An attendee reconnecting replaces
+// their old connection; attendees the mentor removed stay out.
+func (h *Hub) AddGroupClient(roomID uuid.UUID, capacity int, client *Client) (*Session, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	session, exists := h.sessions[roomID]
+	if !exists {
+		session = NewSession(roomID, 30*60)
+		session.Group = true
+		session.Capacity = capacity
+		session.Topology = TopologyFanOut
+		session.Attendees = make(map[uuid.UUID]*Client)
+		session.removed = make(map[uuid.UUID]bool)
+		h.sessions[roomID] = session
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if client.Role == "mentor" {
+		if session.Mentor != nil && session.Mentor.ID != client.ID {
+			fmt.Println("[Hub] Closing duplicate mentor connection for room", roomID)
+			session.Mentor.Close()
+		}
+		session.Mentor = client
+		return session, nil
+	}
+
+	if session.removed[client.UserID] {
+		return nil, ErrRemovedFromRoom
+	}
+
+	if old := session.Attendees[client.UserID]; old != nil {
+		if old.ID != client.ID {
+			fmt.Println("[Hub] Closing duplicate attendee connection for room", roomID)
+			old.Close()
+		}
+	} else if len(session.Attendees) >= session.Capacity {
+		return nil, ErrRoomFull
+	}
+
+	session.Attendees[client.UserID] = client
+	return session, nil
+}
+
 // GetSession gets a session by booking ID
 func (h *Hub) GetSession(bookingID uuid.UUID) *Session {
 	h.mu.RLock()
@@ -96,26 +153,39 @@ func (h *Hub) GetSession(bookingID uuid.UUID) *Session {
 	return h.sessions[bookingID]
 }
 
-// RemoveClient removes a client from a session
-func (h *Hub) RemoveClient(bookingID uuid.UUID, role string) {
+// RemoveClient removes a client from its session. It reports false when
+// the client had already been replaced or removed.
+func (h *Hub) RemoveClient(client *Client) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	session, exists := h.sessions[bookingID]
+	session, exists := h.sessions[client.RoomID]
 	if !exists {
-		return
+		return false
 	}
 
-	if role == "mentor" {
+	session.mu.Lock()
+	removed := false
+	switch {
+	case session.Mentor == client:
 		session.Mentor = nil
-	} else {
+		removed = true
+	case session.User == client:
 		session.User = nil
+		removed = true
+	case session.Group && session.Attendees[client.UserID] == client:
+		delete(session.Attendees, client.UserID)
+		removed = true
 	}
+	empty := session.Mentor == nil && session.User == nil && len(session.Attendees) == 0
+	session.mu.Unlock()
 
-	// If both are gone, remove session
-	if session.Mentor == nil && session.User == nil {
-		delete(h.sessions, bookingID)
+	// If everyone is gone, remove session
+	if empty {
+		delete(h.sessions, client.RoomID)
 	}
+
+	return removed
 }
 
 // BothJoined checks if both parties have joined
@@ -126,7 +196,8 @@ func (s *Session) BothJoined() bool {
 	return s.Mentor != nil && s.User != nil
 }
 
-// GetOtherClient gets the other party
+// GetOtherClient gets the other party. In a group session an attendee's
+// other party is the mentor; the mentor has none.
 func (s *Session) GetOtherClient(role string) *Client {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -137,23 +208,94 @@ func (s *Session) GetOtherClient(role string) *Client {
 	return s.Mentor
 }
 
-// Broadcast sends a message to both parties
-func (s *Session) Broadcast(message interface{}) {
+// Participants lists everyone in the session, mentor first
+func (s *Session) Participants() []*Client {
 	s.mu.RLock()
-	mentor := s.Mentor
-	user := s.User
-	s.mu.RUnlock()
+	defer s.mu.RUnlock()
 
-	if mentor != nil {
-		select {
-		case mentor.Send <- message:
-		default:
+	var clients []*Client
+	if s.Mentor != nil {
+		clients = append(clients, s.Mentor)
+	}
+	if s.User != nil {
+		clients = append(clients, s.User)
+	}
+	for _, c := range s.Attendees {
+		clients = append(clients, c)
+	}
+
+	return clients
+}
+
+// Participant finds the client of userID in the session
+func (s *Session) Participant(userID uuid.UUID) *Client {
+	for _, c := range s.Participants() {
+		if c.UserID == userID {
+			return c
 		}
 	}
+	return nil
+}
+
+// GetTopology returns the signaling topology of a group session
+func (s *Session) GetTopology() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Topology
+}
+
+// SetTopology switches a group session between fan-out and mesh
+func (s *Session) SetTopology(topology string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Topology = topology
+}
+
+// CanSignal tells whether `from` may negotiate a peer connection with
+// `to`. In fan-out every attendee only connects to the mentor; in mesh
+// attendees connect to each other too.
+func (s *Session) CanSignal(from *Client, to *Client) bool {
+	if from == nil || to == nil || from == to {
+		return false
+	}
+
+	if from.Role == "mentor" || to.Role == "mentor" {
+		return true
+	}
+
+	return s.GetTopology() == TopologyMesh
+}
+
+// RemoveAttendee takes an attendee out of a group session for good and
+// returns their client, if connected
+func (s *Session) RemoveAttendee(userID uuid.UUID) *Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removed[userID] = true
+
+	client := s.Attendees[userID]
+	delete(s.Attendees, userID)
+
+	return client
+}
+
+// Broadcast sends a message to both parties
+func (s *Session) Broadcast(message interface{}) {
+	s.BroadcastExcept(nil, message)
+}
+
+// BroadcastExcept sends a message to everyone in the session but `except`
+func (s *Session) BroadcastExcept(except *Client, message interface{}) {
+	for _, c := range s.Participants() {
+		if c == except {
+			continue
+		}
 
-	if user != nil {
 		select {
-		case user.Send <- message:
+		case c.Send <- message:
 		default:
 		}
 	}
@@ -182,24 +324,19 @@ func (s *Session) SendToRole(role string, message interface{}) {
 func (s *Session) Close() {
 	close(s.Done)
 
-	s.mu.RLock()
-	mentor := s.Mentor
-	user := s.User
-	s.mu.RUnlock()
-
-	if mentor != nil {
-		mentor.Close()
-	}
-	if user != nil {
-		user.Close()
+	for _, c := range s.Participants() {
+		c.Close()
 	}
 }
 
-// Close closes the client connection
+// Close asks the client's connection to shut down. Send stays open:
+// other goroutines may still hold the client and send to it. The write
+// pump flushes what was queued and closes the connection once Done is
+// closed.
 func (c *Client) Close() {
-	close(c.Send)
-	c.Conn.Close()
-	close(c.Done)
+	c.closeOnce.Do(func() {
+		close(c.Done)
+	})
 }
 
 // IsConnected checks if client is still connected
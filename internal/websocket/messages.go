@@ -3,6 +3,8 @@ package websocket
 import (
 	"encoding/json"
 	"sync"
+
+	"github.com/google/uuid"
 )
 
 // MessageBuffer buffers WebRTC signaling messages until both clients are ready
@@ -44,6 +46,43 @@ type ICECandidatePayload struct {
 // LeaveCallPayload is sent when user leaves the call
 type LeaveCallPayload struct{}
 
+// Signaling topologies of a group session
+const (
+	TopologyFanOut = "fanout" // attendees only connect to the mentor
+	TopologyMesh   = "mesh"   // everyone connects to everyone
+)
+
+// SignalTarget picks the recipient of an offer, answer or ICE candidate
+// in a group session. The relayed message carries "from" instead.
+type SignalTarget struct {
+	To uuid.UUID `json:"to"`
+}
+
+// ParticipantPayload describes someone in a group session
+type ParticipantPayload struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"` // "mentor" or "user"
+}
+
+// RoomStatePayload is sent to whoever joins a group session
+type RoomStatePayload struct {
+	Role         string               `json:"role"`
+	Topology     string               `json:"topology"`
+	Capacity     int                  `json:"capacity"`
+	Participants []ParticipantPayload `json:"participants"`
+}
+
+// TopologyPayload switches a group session's topology (mentor only)
+type TopologyPayload struct {
+	Topology string `json:"topology"`
+}
+
+// ModerationPayload names the attendee a mentor mutes or removes
+type ModerationPayload struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
 // NewMessageBuffer creates a new message buffer
 func NewMessageBuffer(maxSize int) *MessageBuffer {
 	return &MessageBuffer{
@@ -0,0 +1,111 @@
+-- Tables as they were before the migrations below. Existing databases
+-- already have them; this creates them on an empty database.
+
+CREATE TABLE IF NOT EXISTS users (
+	id              UUID PRIMARY KEY,
+	first_name      TEXT NOT NULL,
+	last_name       TEXT NOT NULL,
+	username        TEXT NOT NULL UNIQUE,
+	email           TEXT NOT NULL UNIQUE,
+	password_hash   TEXT NOT NULL,
+	role            TEXT NOT NULL,
+	profile_picture TEXT NOT NULL DEFAULT '',
+	bio             TEXT NOT NULL DEFAULT '',
+	is_active       BOOLEAN NOT NULL DEFAULT true,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	deleted_at      TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id    UUID NOT NULL REFERENCES users (id),
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	revoked_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS mentor_profiles (
+	id         UUID PRIMARY KEY,
+	user_id    UUID NOT NULL UNIQUE REFERENCES users (id),
+	title      TEXT NOT NULL,
+	bio        TEXT NOT NULL DEFAULT '',
+	timezone   TEXT NOT NULL DEFAULT 'UTC',
+	is_active  BOOLEAN NOT NULL DEFAULT true,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS mentor_services (
+	id               UUID PRIMARY KEY,
+	mentor_id        UUID NOT NULL REFERENCES mentor_profiles (id),
+	title            TEXT NOT NULL,
+	description      TEXT NOT NULL DEFAULT '',
+	duration_minutes INTEGER NOT NULL,
+	price_cents      INTEGER NOT NULL,
+	currency         TEXT NOT NULL,
+	is_active        BOOLEAN NOT NULL DEFAULT true,
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Weekly hours, in the mentor's timezone
+CREATE TABLE IF NOT EXISTS mentor_availability_rules (
+	id          UUID PRIMARY KEY,
+	mentor_id   UUID NOT NULL REFERENCES mentor_profiles (id),
+	day_of_week INTEGER NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+	start_time  TIME NOT NULL,
+	end_time    TIME NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- booking_date, start_time and end_time are replaced by instants in
+-- 0012_booking_instants.sql
+CREATE TABLE IF NOT EXISTS bookings (
+	id           UUID PRIMARY KEY,
+	mentor_id    UUID NOT NULL REFERENCES mentor_profiles (id),
+	user_id      UUID NOT NULL REFERENCES users (id),
+	service_id   UUID NOT NULL REFERENCES mentor_services (id),
+	booking_date DATE NOT NULL,
+	start_time   TIME NOT NULL,
+	end_time     TIME NOT NULL,
+	status       TEXT NOT NULL,
+	price_cents  INTEGER NOT NULL,
+	currency     TEXT NOT NULL,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+	id                 UUID PRIMARY KEY,
+	booking_id         UUID NOT NULL REFERENCES bookings (id),
+	user_id            UUID NOT NULL REFERENCES users (id),
+	gateway            TEXT NOT NULL,
+	gateway_order_id   TEXT NOT NULL UNIQUE,
+	gateway_payment_id TEXT,
+	gateway_signature  TEXT,
+	amount             BIGINT NOT NULL,
+	currency           TEXT NOT NULL,
+	status             TEXT NOT NULL,
+	created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS video_sessions (
+	id                 UUID PRIMARY KEY,
+	booking_id         UUID NOT NULL UNIQUE REFERENCES bookings (id),
+	mentor_id          UUID NOT NULL,
+	user_id            UUID NOT NULL,
+	session_started_at TIMESTAMPTZ,
+	session_ended_at   TIMESTAMPTZ,
+	mentor_joined_at   TIMESTAMPTZ,
+	user_joined_at     TIMESTAMPTZ,
+	mentor_left_at     TIMESTAMPTZ,
+	user_left_at       TIMESTAMPTZ,
+	duration_seconds   INTEGER NOT NULL DEFAULT 0,
+	status             TEXT NOT NULL,
+	created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
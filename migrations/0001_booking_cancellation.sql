@@ -0,0 +1,6 @@
+-- user-001: cancellation and rescheduling
+
+ALTER TABLE bookings
+	ADD COLUMN IF NOT EXISTS cancelled_at        TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS cancelled_by        TEXT, -- user | mentor | system
+	ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;
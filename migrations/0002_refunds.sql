@@ -0,0 +1,19 @@
+-- user-002: refunds through the gateway, tracked by webhook
+
+CREATE TABLE IF NOT EXISTS refunds (
+	id                UUID PRIMARY KEY,
+	payment_id        UUID NOT NULL REFERENCES payments (id),
+	booking_id        UUID REFERENCES bookings (id), -- NULL for package payments
+	gateway_refund_id TEXT UNIQUE,
+	amount            BIGINT NOT NULL CHECK (amount > 0),
+	currency          TEXT NOT NULL,
+	status            TEXT NOT NULL, -- pending | processed | failed
+	reason            TEXT NOT NULL,
+	failure_reason    TEXT,
+	created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS refunds_payment_id_idx ON refunds (payment_id);
+
+CREATE INDEX IF NOT EXISTS payments_gateway_payment_id_idx ON payments (gateway_payment_id);
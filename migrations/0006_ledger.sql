@@ -0,0 +1,27 @@
+-- user-006: double-entry ledger of mentor earnings
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+	id             UUID PRIMARY KEY,
+	kind           TEXT NOT NULL, -- booking_revenue | package_revenue | refund
+	reference_type TEXT NOT NULL,
+	reference_id   UUID NOT NULL,
+	mentor_id      UUID NOT NULL REFERENCES mentor_profiles (id),
+	currency       TEXT NOT NULL,
+	created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+	-- Posting the same thing twice is a no-op
+	UNIQUE (kind, reference_id)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_transactions_mentor_created_idx
+	ON ledger_transactions (mentor_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+	id             UUID PRIMARY KEY,
+	transaction_id UUID NOT NULL REFERENCES ledger_transactions (id),
+	account        TEXT NOT NULL, -- gateway_clearing | platform_commission | mentor_payable
+	direction      TEXT NOT NULL CHECK (direction IN ('debit', 'credit')),
+	amount         BIGINT NOT NULL CHECK (amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_transaction_id_idx ON ledger_entries (transaction_id);
@@ -0,0 +1,40 @@
+-- user-007: promo codes redeemed at booking time
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+	id               UUID PRIMARY KEY,
+	code             TEXT NOT NULL UNIQUE, -- upper-case
+	mentor_id        UUID REFERENCES mentor_profiles (id), -- NULL for platform-wide codes
+	discount_type    TEXT NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+	percent_off      INTEGER NOT NULL DEFAULT 0,
+	amount_off_cents BIGINT NOT NULL DEFAULT 0,
+	currency         TEXT,
+	max_redemptions  INTEGER,
+	max_per_user     INTEGER,
+	redemption_count INTEGER NOT NULL DEFAULT 0,
+	starts_at        TIMESTAMPTZ,
+	expires_at       TIMESTAMPTZ,
+	is_active        BOOLEAN NOT NULL DEFAULT true,
+	created_by       UUID NOT NULL REFERENCES users (id),
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+	id             UUID PRIMARY KEY,
+	promo_code_id  UUID NOT NULL REFERENCES promo_codes (id),
+	booking_id     UUID NOT NULL REFERENCES bookings (id),
+	user_id        UUID NOT NULL REFERENCES users (id),
+	discount_cents BIGINT NOT NULL,
+	released_at    TIMESTAMPTZ,
+	created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS promo_redemptions_code_user_idx ON promo_redemptions (promo_code_id, user_id);
+CREATE INDEX IF NOT EXISTS promo_redemptions_booking_id_idx ON promo_redemptions (booking_id);
+
+ALTER TABLE bookings
+	ADD COLUMN IF NOT EXISTS promo_code_id  UUID REFERENCES promo_codes (id),
+	ADD COLUMN IF NOT EXISTS discount_cents INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE payments
+	ADD COLUMN IF NOT EXISTS discount_cents BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,42 @@
+-- user-008: prepaid session packages
+
+CREATE TABLE IF NOT EXISTS service_packages (
+	id            UUID PRIMARY KEY,
+	mentor_id     UUID NOT NULL REFERENCES mentor_profiles (id),
+	service_id    UUID NOT NULL REFERENCES mentor_services (id),
+	title         TEXT NOT NULL,
+	session_count INTEGER NOT NULL CHECK (session_count > 0),
+	price_cents   INTEGER NOT NULL,
+	currency      TEXT NOT NULL,
+	validity_days INTEGER, -- NULL = credits never expire
+	is_active     BOOLEAN NOT NULL DEFAULT true,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS package_purchases (
+	id                UUID PRIMARY KEY,
+	package_id        UUID NOT NULL REFERENCES service_packages (id),
+	user_id           UUID NOT NULL REFERENCES users (id),
+	mentor_id         UUID NOT NULL REFERENCES mentor_profiles (id),
+	service_id        UUID NOT NULL REFERENCES mentor_services (id),
+	credits_total     INTEGER NOT NULL,
+	credits_remaining INTEGER NOT NULL CHECK (credits_remaining >= 0),
+	price_cents       INTEGER NOT NULL,
+	currency          TEXT NOT NULL,
+	status            TEXT NOT NULL, -- pending | active | failed
+	activated_at      TIMESTAMPTZ,
+	expires_at        TIMESTAMPTZ,
+	created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS package_purchases_user_id_idx ON package_purchases (user_id);
+
+ALTER TABLE bookings
+	ADD COLUMN IF NOT EXISTS package_purchase_id UUID REFERENCES package_purchases (id);
+
+-- A payment is now for a booking or a package purchase
+ALTER TABLE payments
+	ADD COLUMN IF NOT EXISTS package_purchase_id UUID REFERENCES package_purchases (id),
+	ALTER COLUMN booking_id DROP NOT NULL;
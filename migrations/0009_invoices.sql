@@ -0,0 +1,34 @@
+-- user-009: numbered receipts for captured payments
+
+CREATE TABLE IF NOT EXISTS invoices (
+	id                  UUID PRIMARY KEY,
+	number              TEXT NOT NULL UNIQUE,
+	payment_id          UUID NOT NULL UNIQUE REFERENCES payments (id),
+	booking_id          UUID REFERENCES bookings (id),
+	package_purchase_id UUID REFERENCES package_purchases (id),
+	user_id             UUID NOT NULL REFERENCES users (id),
+	learner_name        TEXT NOT NULL,
+	learner_email       TEXT NOT NULL,
+	mentor_id           UUID NOT NULL REFERENCES mentor_profiles (id),
+	mentor_name         TEXT NOT NULL,
+	mentor_username     TEXT NOT NULL,
+	description         TEXT NOT NULL,
+	currency            TEXT NOT NULL,
+	discount_cents      BIGINT NOT NULL DEFAULT 0,
+	subtotal_cents      BIGINT NOT NULL,
+	tax_label           TEXT NOT NULL,
+	tax_rate_bps        INTEGER NOT NULL,
+	tax_cents           BIGINT NOT NULL,
+	total_cents         BIGINT NOT NULL,
+	issued_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Invoice numbers are taken from this row under lock, so they have no gaps
+CREATE TABLE IF NOT EXISTS invoice_counters (
+	name        TEXT PRIMARY KEY,
+	last_number BIGINT NOT NULL
+);
+
+INSERT INTO invoice_counters (name, last_number)
+VALUES ('invoice', 0)
+ON CONFLICT (name) DO NOTHING;
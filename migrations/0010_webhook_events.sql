@@ -0,0 +1,23 @@
+-- user-010: inbox of gateway webhooks, processed in the background
+
+CREATE TABLE IF NOT EXISTS webhook_events (
+	id              UUID PRIMARY KEY,
+	gateway         TEXT NOT NULL,
+	event_id        TEXT NOT NULL,
+	event_type      TEXT NOT NULL,
+	payload         BYTEA NOT NULL, -- raw body as received
+	event           BYTEA NOT NULL, -- parsed, gateway-neutral event (JSON)
+	status          TEXT NOT NULL, -- pending | processing | processed | failed
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	last_error      TEXT,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	received_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	processed_at    TIMESTAMPTZ,
+	updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+	-- Redeliveries of the same event are dropped
+	UNIQUE (gateway, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_events_status_next_attempt_idx
+	ON webhook_events (status, next_attempt_at);
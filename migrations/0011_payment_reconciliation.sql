@@ -0,0 +1,6 @@
+-- user-011: reconciling unpaid payments against the gateway
+
+ALTER TABLE payments
+	ADD COLUMN IF NOT EXISTS reconciled_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS payments_status_created_idx ON payments (status, created_at);
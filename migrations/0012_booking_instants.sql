@@ -0,0 +1,58 @@
+-- user-012: bookings are stored as instants instead of a local date and
+-- wall-clock times.
+--
+-- Existing rows are converted using the mentor's timezone, which is what
+-- the old columns were expressed in. A mentor without a valid zone falls
+-- back to UTC. A booking whose end time is not after its start time ran
+-- past midnight and ends on the next day.
+
+ALTER TABLE bookings
+	ADD COLUMN IF NOT EXISTS starts_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS ends_at   TIMESTAMPTZ;
+
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+		  AND table_name = 'bookings'
+		  AND column_name = 'booking_date'
+	) THEN
+		UPDATE bookings b
+		SET
+			starts_at = (b.booking_date + b.start_time) AT TIME ZONE z.tz,
+			ends_at = (
+				b.booking_date
+				+ b.end_time
+				+ CASE
+					WHEN b.end_time <= b.start_time THEN INTERVAL '1 day'
+					ELSE INTERVAL '0'
+				  END
+			) AT TIME ZONE z.tz
+		FROM (
+			SELECT
+				bk.id,
+				COALESCE(tzn.name, 'UTC') AS tz
+			FROM bookings bk
+			LEFT JOIN mentor_profiles mp ON mp.id = bk.mentor_id
+			LEFT JOIN pg_timezone_names tzn ON tzn.name = mp.timezone
+		) z
+		WHERE z.id = b.id
+		  AND b.starts_at IS NULL;
+
+		ALTER TABLE bookings
+			DROP COLUMN booking_date,
+			DROP COLUMN start_time,
+			DROP COLUMN end_time;
+	END IF;
+END
+$$;
+
+ALTER TABLE bookings
+	ALTER COLUMN starts_at SET NOT NULL,
+	ALTER COLUMN ends_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS bookings_mentor_starts_at_idx ON bookings (mentor_id, starts_at);
+CREATE INDEX IF NOT EXISTS bookings_user_starts_at_idx ON bookings (user_id, starts_at);
+CREATE INDEX IF NOT EXISTS bookings_status_ends_at_idx ON bookings (status, ends_at);
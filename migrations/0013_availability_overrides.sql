@@ -0,0 +1,19 @@
+-- user-013: one-off time off and extra hours on specific dates
+
+CREATE TABLE IF NOT EXISTS mentor_availability_overrides (
+	id         UUID PRIMARY KEY,
+	mentor_id  UUID NOT NULL REFERENCES mentor_profiles (id),
+	kind       TEXT NOT NULL CHECK (kind IN ('block', 'extra')),
+	start_date DATE NOT NULL,
+	end_date   DATE NOT NULL,
+	start_time TIME, -- NULL = the whole day
+	end_time   TIME,
+	reason     TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+	CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS mentor_availability_overrides_mentor_dates_idx
+	ON mentor_availability_overrides (mentor_id, start_date, end_date);
@@ -0,0 +1,8 @@
+-- user-015: per-mentor buffers, notice, horizon and daily cap.
+-- 0 means the rule is off.
+
+ALTER TABLE mentor_profiles
+	ADD COLUMN IF NOT EXISTS buffer_minutes     INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS min_notice_minutes INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS max_advance_days   INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS daily_session_cap  INTEGER NOT NULL DEFAULT 0;
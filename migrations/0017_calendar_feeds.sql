@@ -0,0 +1,7 @@
+-- user-017: private iCalendar feed per user
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+	user_id    UUID PRIMARY KEY REFERENCES users (id),
+	token_hash TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
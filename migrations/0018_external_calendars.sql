@@ -0,0 +1,27 @@
+-- user-018: mentors' external calendars blocking availability
+
+CREATE TABLE IF NOT EXISTS external_calendars (
+	id             UUID PRIMARY KEY,
+	mentor_id      UUID NOT NULL REFERENCES mentor_profiles (id),
+	name           TEXT NOT NULL,
+	source_url     TEXT, -- either a URL to poll or an uploaded file
+	ics_data       TEXT,
+	last_synced_at TIMESTAMPTZ,
+	last_error     TEXT,
+	next_sync_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS external_calendars_next_sync_at_idx ON external_calendars (next_sync_at);
+
+CREATE TABLE IF NOT EXISTS external_busy_blocks (
+	calendar_id UUID NOT NULL REFERENCES external_calendars (id) ON DELETE CASCADE,
+	mentor_id   UUID NOT NULL REFERENCES mentor_profiles (id),
+	starts_at   TIMESTAMPTZ NOT NULL,
+	ends_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS external_busy_blocks_calendar_id_idx ON external_busy_blocks (calendar_id);
+CREATE INDEX IF NOT EXISTS external_busy_blocks_mentor_starts_at_idx
+	ON external_busy_blocks (mentor_id, starts_at);
@@ -0,0 +1,23 @@
+-- user-019: recurring bookings paid up front
+
+CREATE TABLE IF NOT EXISTS booking_series (
+	id             UUID PRIMARY KEY,
+	mentor_id      UUID NOT NULL REFERENCES mentor_profiles (id),
+	user_id        UUID NOT NULL REFERENCES users (id),
+	service_id     UUID NOT NULL REFERENCES mentor_services (id),
+	frequency      TEXT NOT NULL,
+	interval_weeks INTEGER NOT NULL,
+	occurrences    INTEGER NOT NULL,
+	price_cents    INTEGER NOT NULL,
+	currency       TEXT NOT NULL,
+	created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE bookings
+	ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES booking_series (id);
+
+ALTER TABLE payments
+	ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES booking_series (id);
+
+CREATE INDEX IF NOT EXISTS bookings_series_id_idx ON bookings (series_id);
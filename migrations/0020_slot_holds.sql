@@ -0,0 +1,16 @@
+-- user-020: short holds on a slot while the learner checks out. Only
+-- used when Redis is not configured.
+
+CREATE TABLE IF NOT EXISTS slot_holds (
+	token      TEXT PRIMARY KEY,
+	mentor_id  UUID NOT NULL REFERENCES mentor_profiles (id),
+	service_id UUID NOT NULL REFERENCES mentor_services (id),
+	user_id    UUID NOT NULL REFERENCES users (id),
+	starts_at  TIMESTAMPTZ NOT NULL,
+	ends_at    TIMESTAMPTZ NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS slot_holds_mentor_starts_at_idx ON slot_holds (mentor_id, starts_at);
+CREATE INDEX IF NOT EXISTS slot_holds_user_id_idx ON slot_holds (user_id);
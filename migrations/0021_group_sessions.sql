@@ -0,0 +1,5 @@
+-- user-021: group sessions with several seats per slot
+
+ALTER TABLE mentor_services
+	ADD COLUMN IF NOT EXISTS service_type TEXT NOT NULL DEFAULT 'one_on_one',
+	ADD COLUMN IF NOT EXISTS capacity     INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,44 @@
+-- user-022: waitlists for fully booked mentors
+
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+	id             UUID PRIMARY KEY,
+	mentor_id      UUID NOT NULL REFERENCES mentor_profiles (id),
+	user_id        UUID NOT NULL REFERENCES users (id),
+	service_id     UUID REFERENCES mentor_services (id), -- NULL = any service
+	preferred_days BIGINT[] NOT NULL DEFAULT '{}', -- 0 = Sunday
+	preferred_from TEXT NOT NULL DEFAULT '', -- HH:MM, '' = no bound
+	preferred_to   TEXT NOT NULL DEFAULT '',
+	timezone       TEXT NOT NULL,
+	status         TEXT NOT NULL, -- waiting | fulfilled | cancelled
+	created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One waiting entry per learner, mentor and service
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_waiting_idx
+	ON waitlist_entries (
+		mentor_id,
+		user_id,
+		COALESCE(service_id, '00000000-0000-0000-0000-000000000000')
+	)
+	WHERE status = 'waiting';
+
+CREATE INDEX IF NOT EXISTS waitlist_entries_mentor_created_idx
+	ON waitlist_entries (mentor_id, created_at);
+
+CREATE TABLE IF NOT EXISTS waitlist_offers (
+	id         UUID PRIMARY KEY,
+	entry_id   UUID NOT NULL REFERENCES waitlist_entries (id),
+	service_id UUID NOT NULL REFERENCES mentor_services (id),
+	starts_at  TIMESTAMPTZ NOT NULL,
+	ends_at    TIMESTAMPTZ NOT NULL,
+	status     TEXT NOT NULL, -- open | accepted | expired | taken | withdrawn
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+	UNIQUE (entry_id, starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS waitlist_offers_status_expires_at_idx
+	ON waitlist_offers (status, expires_at);
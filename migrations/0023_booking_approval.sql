@@ -0,0 +1,7 @@
+-- user-023: services that need the mentor to approve each request
+
+ALTER TABLE mentor_services
+	ADD COLUMN IF NOT EXISTS requires_approval BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE bookings
+	ADD COLUMN IF NOT EXISTS approved_at TIMESTAMPTZ;
@@ -0,0 +1,14 @@
+-- user-025: learner reviews of completed sessions
+
+CREATE TABLE IF NOT EXISTS reviews (
+	id         UUID PRIMARY KEY,
+	booking_id UUID NOT NULL UNIQUE REFERENCES bookings (id),
+	mentor_id  UUID NOT NULL REFERENCES mentor_profiles (id),
+	user_id    UUID NOT NULL REFERENCES users (id),
+	rating     INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+	comment    TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS reviews_mentor_created_idx ON reviews (mentor_id, created_at);